@@ -0,0 +1,186 @@
+package aggregation
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Accumulator aggregates the rows of a period and dimension.
+// Rows whose value cannot be parsed are skipped rather than counted as zero.
+type Accumulator interface {
+	Add(row []string)
+	Value() decimal.Decimal
+	// Skipped returns the number of rows ignored because of an invalid value.
+	Skipped() int
+}
+
+type skippedRows struct {
+	skipped int
+}
+
+func (s *skippedRows) Skipped() int {
+	return s.skipped
+}
+
+type sumAccumulator struct {
+	skippedRows
+	columnIndex int
+	sum         decimal.Decimal
+}
+
+func (a *sumAccumulator) Add(row []string) {
+	value, ok := parseValue(valueAt(row, a.columnIndex))
+	if !ok {
+		a.skipped++
+		return
+	}
+	a.sum = a.sum.Add(value)
+}
+
+func (a *sumAccumulator) Value() decimal.Decimal {
+	return a.sum
+}
+
+// countAccumulator counts the rows, or the rows with a value when it has a column.
+type countAccumulator struct {
+	skippedRows
+	columnIndex int
+	count       int64
+}
+
+func (a *countAccumulator) Add(row []string) {
+	if a.columnIndex != -1 && strings.TrimSpace(valueAt(row, a.columnIndex)) == "" {
+		a.skipped++
+		return
+	}
+	a.count++
+}
+
+func (a *countAccumulator) Value() decimal.Decimal {
+	return decimal.NewFromInt(a.count)
+}
+
+type countDistinctAccumulator struct {
+	skippedRows
+	columnIndex int
+	values      map[string]bool
+}
+
+func (a *countDistinctAccumulator) Add(row []string) {
+	value := strings.TrimSpace(valueAt(row, a.columnIndex))
+	if value == "" {
+		a.skipped++
+		return
+	}
+	a.values[value] = true
+}
+
+func (a *countDistinctAccumulator) Value() decimal.Decimal {
+	return decimal.NewFromInt(int64(len(a.values)))
+}
+
+type avgAccumulator struct {
+	skippedRows
+	columnIndex int
+	sum         decimal.Decimal
+	count       int64
+}
+
+func (a *avgAccumulator) Add(row []string) {
+	value, ok := parseValue(valueAt(row, a.columnIndex))
+	if !ok {
+		a.skipped++
+		return
+	}
+	a.sum = a.sum.Add(value)
+	a.count++
+}
+
+func (a *avgAccumulator) Value() decimal.Decimal {
+	if a.count == 0 {
+		return decimal.Zero
+	}
+	return a.sum.Div(decimal.NewFromInt(a.count))
+}
+
+type extremumAccumulator struct {
+	skippedRows
+	columnIndex int
+	isMax       bool
+	value       decimal.Decimal
+	hasValue    bool
+}
+
+func (a *extremumAccumulator) Add(row []string) {
+	value, ok := parseValue(valueAt(row, a.columnIndex))
+	if !ok {
+		a.skipped++
+		return
+	}
+	if !a.hasValue || (a.isMax && value.GreaterThan(a.value)) || (!a.isMax && value.LessThan(a.value)) {
+		a.value = value
+		a.hasValue = true
+	}
+}
+
+func (a *extremumAccumulator) Value() decimal.Decimal {
+	return a.value
+}
+
+type medianAccumulator struct {
+	skippedRows
+	columnIndex int
+	values      []decimal.Decimal
+}
+
+func (a *medianAccumulator) Add(row []string) {
+	value, ok := parseValue(valueAt(row, a.columnIndex))
+	if !ok {
+		a.skipped++
+		return
+	}
+	a.values = append(a.values, value)
+}
+
+func (a *medianAccumulator) Value() decimal.Decimal {
+	if len(a.values) == 0 {
+		return decimal.Zero
+	}
+	sort.Slice(a.values, func(i, j int) bool {
+		return a.values[i].LessThan(a.values[j])
+	})
+	middle := len(a.values) / 2
+	if len(a.values)%2 == 1 {
+		return a.values[middle]
+	}
+	return a.values[middle-1].Add(a.values[middle]).Div(decimal.NewFromInt(2))
+}
+
+// ratioAccumulator divides the sum of the numerator column by the sum of the denominator column.
+type ratioAccumulator struct {
+	skippedRows
+	numeratorIndex   int
+	denominatorIndex int
+	numerator        decimal.Decimal
+	denominator      decimal.Decimal
+}
+
+func (a *ratioAccumulator) Add(row []string) {
+	numerator, numeratorOk := parseValue(valueAt(row, a.numeratorIndex))
+	denominator, denominatorOk := parseValue(valueAt(row, a.denominatorIndex))
+	if !numeratorOk || !denominatorOk {
+		a.skipped++
+		return
+	}
+	a.numerator = a.numerator.Add(numerator)
+	a.denominator = a.denominator.Add(denominator)
+}
+
+func (a *ratioAccumulator) Value() decimal.Decimal {
+	if a.denominator.IsZero() {
+		return decimal.Zero
+	}
+	return a.numerator.Div(a.denominator)
+}
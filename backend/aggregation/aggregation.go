@@ -0,0 +1,176 @@
+package aggregation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+type Function string
+
+const (
+	Sum           Function = "sum"
+	Count         Function = "count"
+	CountDistinct Function = "count_distinct"
+	Avg           Function = "avg"
+	Min           Function = "min"
+	Max           Function = "max"
+	Median        Function = "median"
+	Ratio         Function = "ratio"
+)
+
+// Definition tells how the rows of a period and dimension are aggregated into a KPI.
+type Definition struct {
+	Function          Function
+	Column            string
+	NumeratorColumn   string
+	DenominatorColumn string
+}
+
+// FromMetricConfig returns the aggregation of a metric, a sum of its KPI column by default.
+func FromMetricConfig(metric common.MetricConfig) Definition {
+	function := Function(metric.Aggregation)
+	if function == "" {
+		function = Sum
+	}
+	return Definition{
+		Function:          function,
+		Column:            metric.KPIColumnName,
+		NumeratorColumn:   metric.NumeratorColumn,
+		DenominatorColumn: metric.DenominatorColumn,
+	}
+}
+
+func (d Definition) Validate() error {
+	switch d.Function {
+	case Sum, CountDistinct, Avg, Min, Max, Median:
+		if d.Column == "" {
+			return fmt.Errorf("aggregation %s requires a column", d.Function)
+		}
+	case Count:
+	case Ratio:
+		if d.NumeratorColumn == "" || d.DenominatorColumn == "" {
+			return fmt.Errorf("aggregation ratio requires a numerator and a denominator column")
+		}
+	default:
+		return fmt.Errorf("unknown aggregation %q", d.Function)
+	}
+	return nil
+}
+
+// IsAdditive tells whether the KPI of a set of rows is the sum of the values of its rows.
+func (d Definition) IsAdditive() bool {
+	return d.Function == Sum || d.Function == Count
+}
+
+// Aggregator is a definition bound to the headers of a snapshot.
+type Aggregator struct {
+	function         Function
+	columnIndex      int
+	numeratorIndex   int
+	denominatorIndex int
+}
+
+// Bind resolves the columns of the definition in the headers of a snapshot.
+func (d Definition) Bind(headers []string) (Aggregator, error) {
+	if err := d.Validate(); err != nil {
+		return Aggregator{}, err
+	}
+	aggregator := Aggregator{function: d.Function, columnIndex: -1, numeratorIndex: -1, denominatorIndex: -1}
+	var err error
+	switch d.Function {
+	case Count:
+		// Without a column every row is counted, a configured column must exist so that a typo is not a row count
+		if d.Column != "" {
+			if aggregator.columnIndex, err = findColumn(headers, d.Column); err != nil {
+				return Aggregator{}, err
+			}
+		}
+	case Ratio:
+		if aggregator.numeratorIndex, err = findColumn(headers, d.NumeratorColumn); err != nil {
+			return Aggregator{}, err
+		}
+		if aggregator.denominatorIndex, err = findColumn(headers, d.DenominatorColumn); err != nil {
+			return Aggregator{}, err
+		}
+	default:
+		if aggregator.columnIndex, err = findColumn(headers, d.Column); err != nil {
+			return Aggregator{}, err
+		}
+	}
+	return aggregator, nil
+}
+
+// RowValue returns the value a row brings to the KPI, 1 for a counted row and the numerator for a ratio.
+// It returns false when the value of the row cannot be parsed.
+func (a Aggregator) RowValue(row []string) (decimal.Decimal, bool) {
+	switch a.function {
+	case Count, CountDistinct:
+		if a.columnIndex != -1 && strings.TrimSpace(valueAt(row, a.columnIndex)) == "" {
+			return decimal.Zero, false
+		}
+		return decimal.NewFromInt(1), true
+	case Ratio:
+		if _, ok := parseValue(valueAt(row, a.denominatorIndex)); !ok {
+			return decimal.Zero, false
+		}
+		return parseValue(valueAt(row, a.numeratorIndex))
+	default:
+		return parseValue(valueAt(row, a.columnIndex))
+	}
+}
+
+func (a Aggregator) NewAccumulator() Accumulator {
+	switch a.function {
+	case Count:
+		return &countAccumulator{columnIndex: a.columnIndex}
+	case CountDistinct:
+		return &countDistinctAccumulator{columnIndex: a.columnIndex, values: make(map[string]bool)}
+	case Avg:
+		return &avgAccumulator{columnIndex: a.columnIndex}
+	case Min:
+		return &extremumAccumulator{columnIndex: a.columnIndex, isMax: false}
+	case Max:
+		return &extremumAccumulator{columnIndex: a.columnIndex, isMax: true}
+	case Median:
+		return &medianAccumulator{columnIndex: a.columnIndex}
+	case Ratio:
+		return &ratioAccumulator{numeratorIndex: a.numeratorIndex, denominatorIndex: a.denominatorIndex}
+	default:
+		return &sumAccumulator{columnIndex: a.columnIndex}
+	}
+}
+
+func findColumn(headers []string, column string) (int, error) {
+	index := indexOfColumn(headers, column)
+	if index == -1 {
+		return -1, fmt.Errorf("column %s not found", column)
+	}
+	return index, nil
+}
+
+func indexOfColumn(headers []string, column string) int {
+	for i, header := range headers {
+		if header == column {
+			return i
+		}
+	}
+	return -1
+}
+
+func valueAt(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
+func parseValue(value string) (decimal.Decimal, bool) {
+	parsedValue, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return decimal.Zero, false
+	}
+	return parsedValue, true
+}
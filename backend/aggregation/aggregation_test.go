@@ -0,0 +1,95 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var testRecords = [][]string{
+	{"unique_key", "amount", "customer", "converted", "visits"},
+	{"a", "10", "alice", "1", "4"},
+	{"b", "4", "bob", "0", "2"},
+	{"c", "n/a", "alice", "1", "2"},
+	{"d", "7", "", "x", "3"},
+	{"e", "1", "carol", "0", "1"},
+}
+
+func aggregate(t *testing.T, definition Definition) (decimal.Decimal, int) {
+	aggregator, err := definition.Bind(testRecords[0])
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	accumulator := aggregator.NewAccumulator()
+	for _, record := range testRecords[1:] {
+		accumulator.Add(record)
+	}
+	return accumulator.Value(), accumulator.Skipped()
+}
+
+func TestAccumulators(t *testing.T) {
+	testCases := []struct {
+		definition Definition
+		want       string
+		skipped    int
+	}{
+		{Definition{Function: Sum, Column: "amount"}, "22", 1},
+		{Definition{Function: Count}, "5", 0},
+		{Definition{Function: Count, Column: "customer"}, "4", 1},
+		{Definition{Function: CountDistinct, Column: "customer"}, "3", 1},
+		{Definition{Function: Avg, Column: "amount"}, "5.5", 1},
+		{Definition{Function: Min, Column: "amount"}, "1", 1},
+		{Definition{Function: Max, Column: "amount"}, "10", 1},
+		{Definition{Function: Median, Column: "amount"}, "5.5", 1},
+		{Definition{Function: Median, Column: "visits"}, "2", 0},
+		{Definition{Function: Ratio, NumeratorColumn: "converted", DenominatorColumn: "visits"}, "0.2222222222222222", 1},
+	}
+
+	for _, tc := range testCases {
+		got, skipped := aggregate(t, tc.definition)
+		if got.String() != tc.want || skipped != tc.skipped {
+			t.Errorf("%s(%s): expected %s with %d skipped rows, but got %s with %d", tc.definition.Function, tc.definition.Column, tc.want, tc.skipped, got.String(), skipped)
+		}
+	}
+}
+
+func TestAccumulatorsWithoutRows(t *testing.T) {
+	for _, function := range []Function{Sum, Avg, Min, Median} {
+		aggregator, _ := Definition{Function: function, Column: "amount"}.Bind(testRecords[0])
+		if value := aggregator.NewAccumulator().Value(); !value.IsZero() {
+			t.Errorf("%s: expected 0 without rows, but got %v", function, value)
+		}
+	}
+	aggregator, _ := Definition{Function: Ratio, NumeratorColumn: "converted", DenominatorColumn: "visits"}.Bind(testRecords[0])
+	if value := aggregator.NewAccumulator().Value(); !value.IsZero() {
+		t.Errorf("ratio: expected 0 without rows, but got %v", value)
+	}
+}
+
+func TestBind(t *testing.T) {
+	if _, err := (Definition{Function: Sum, Column: "missing"}).Bind(testRecords[0]); err == nil {
+		t.Error("Expected an error for a missing column")
+	}
+	if _, err := (Definition{Function: Count, Column: "missing"}).Bind(testRecords[0]); err == nil {
+		t.Error("Expected an error for a count of a missing column")
+	}
+	if _, err := (Definition{Function: Count}).Bind(testRecords[0]); err != nil {
+		t.Errorf("Expected a count without column to count the rows, but got %v", err)
+	}
+	if _, err := (Definition{Function: Ratio, NumeratorColumn: "converted"}).Bind(testRecords[0]); err == nil {
+		t.Error("Expected an error for a ratio without denominator")
+	}
+	if _, err := (Definition{Function: "mode", Column: "amount"}).Bind(testRecords[0]); err == nil {
+		t.Error("Expected an error for an unknown aggregation")
+	}
+}
+
+func TestRowValue(t *testing.T) {
+	aggregator, _ := Definition{Function: Count, Column: "customer"}.Bind(testRecords[0])
+	if value, ok := aggregator.RowValue(testRecords[1]); !ok || !value.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("Expected a counted row to be worth 1, but got %v", value)
+	}
+	if _, ok := aggregator.RowValue(testRecords[4]); ok {
+		t.Error("Expected a row without customer not to be counted")
+	}
+}
@@ -0,0 +1,126 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/data-drift/data-drift/calendar"
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/period"
+	"github.com/shopspring/decimal"
+)
+
+// Alert is a drift of a KPI matching an alert rule of its metric.
+type Alert struct {
+	MetricName     string                `json:"metricName"`
+	KPIName        string                `json:"kpiName"`
+	PeriodId       common.PeriodKey      `json:"periodId"`
+	DimensionValue common.DimensionValue `json:"dimensionValue,omitempty"`
+	InitialValue   decimal.Decimal       `json:"initialValue"`
+	Event          common.EventObject    `json:"event"`
+	ReportUrl      string                `json:"reportUrl"`
+	Notifiers      []string              `json:"-"`
+}
+
+// Message is the human readable description of the alert.
+func (alert Alert) Message() string {
+	message := fmt.Sprintf("%s drifted by %+g to %s on %s", alert.KPIName, alert.Event.Diff, alert.Event.Current.String(), time.Unix(alert.Event.CommitTimestamp, 0).UTC().Format(time.RFC3339))
+	if alert.Event.Anomaly != nil {
+		message += fmt.Sprintf(", %s compared to %d previous periods (score %g)", alert.Event.Anomaly.Level, alert.Event.Anomaly.ReferenceCohorts, alert.Event.Anomaly.Score)
+	}
+	if alert.Event.CommitUrl != "" {
+		message += "\nCommit: " + alert.Event.CommitUrl
+	}
+	if alert.ReportUrl != "" {
+		message += "\nReport: " + alert.ReportUrl
+	}
+	return message
+}
+
+// Evaluate returns the alerts raised by the drifts of the reports committed after since.
+// No alert is raised when since is zero, i.e. when the history has just been built.
+func Evaluate(metric common.MetricConfig, reports []common.KPIReport, since int64) ([]Alert, error) {
+	if since == 0 || len(metric.Alerts) == 0 {
+		return nil, nil
+	}
+	metricCalendar, err := calendar.ForMetricConfig(metric)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar of %s: %v", metric.MetricName, err.Error())
+	}
+
+	var alerts []Alert
+	for _, report := range reports {
+		if len(report.Events) == 0 {
+			continue
+		}
+		reportPeriod, err := period.ParseInCalendar(report.PeriodId, metricCalendar)
+		if err != nil {
+			return alerts, fmt.Errorf("invalid period %s of %s: %v", report.PeriodId, metric.MetricName, err.Error())
+		}
+		for _, event := range report.Events {
+			if event.EventType != common.EventTypeUpdate || event.CommitTimestamp <= since {
+				continue
+			}
+			// A drift matching several rules is notified once to each of their notifiers
+			matched := false
+			var notifiers []string
+			seenNotifiers := make(map[string]bool)
+			for _, rule := range metric.Alerts {
+				if !matches(rule, event, report.InitialValue, reportPeriod) {
+					continue
+				}
+				matched = true
+				for _, notifier := range rule.Notifiers {
+					if !seenNotifiers[notifier] {
+						seenNotifiers[notifier] = true
+						notifiers = append(notifiers, notifier)
+					}
+				}
+			}
+			if !matched {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				MetricName:     metric.MetricName,
+				KPIName:        report.KPIName,
+				PeriodId:       report.PeriodId,
+				DimensionValue: report.DimensionValue,
+				InitialValue:   report.InitialValue,
+				Event:          event,
+				ReportUrl:      report.WaterfallChartUrl,
+				Notifiers:      notifiers,
+			})
+		}
+	}
+	return alerts, nil
+}
+
+func matches(rule common.AlertRule, event common.EventObject, initialValue decimal.Decimal, reportPeriod period.Period) bool {
+	diff := math.Abs(event.Diff)
+	if diff < rule.AbsoluteDelta {
+		return false
+	}
+	if rule.RelativeDelta > 0 && !initialValue.IsZero() {
+		initial, _ := initialValue.Abs().Float64()
+		if diff/initial*100 < rule.RelativeDelta {
+			return false
+		}
+	}
+	commitTime := time.Unix(event.CommitTimestamp, 0)
+	if rule.OnlyAfterPeriod && commitTime.Before(reportPeriod.End) {
+		return false
+	}
+	if rule.MinDaysAfterPeriod > 0 && commitTime.Before(reportPeriod.End.AddDate(0, 0, rule.MinDaysAfterPeriod)) {
+		return false
+	}
+	if rule.MinAnomalyLevel != "" {
+		if event.Anomaly == nil {
+			return rule.AlertUnscored
+		}
+		if !event.Anomaly.Level.AtLeast(rule.MinAnomalyLevel) {
+			return false
+		}
+	}
+	return true
+}
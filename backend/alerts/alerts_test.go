@@ -0,0 +1,103 @@
+package alerts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+func TestEvaluate(t *testing.T) {
+	since := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+	update := func(day int, diff float64) common.EventObject {
+		return common.EventObject{
+			CommitTimestamp: time.Date(2023, 6, day, 12, 0, 0, 0, time.UTC).Unix(),
+			Diff:            diff,
+			Current:         decimal.NewFromFloat(1000 + diff),
+			EventType:       common.EventTypeUpdate,
+		}
+	}
+	unusualUpdate := update(10, 30)
+	unusualUpdate.Anomaly = &common.AnomalyScore{Score: 2.5, Level: common.AnomalyUnusual, ReferenceCohorts: 6}
+	// May 2023 closes on 1 June, the drifts of June are committed once it is closed
+	closedReport := common.KPIReport{KPIName: "revenue 2023-05", PeriodId: "2023-05", InitialValue: decimal.NewFromInt(1000), Events: []common.EventObject{
+		{CommitTimestamp: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC).Unix(), Current: decimal.NewFromInt(1000), EventType: common.EventTypeCreate},
+		unusualUpdate,
+	}}
+	openReport := common.KPIReport{KPIName: "revenue 2023-06", PeriodId: "2023-06", InitialValue: decimal.NewFromInt(1000), Events: []common.EventObject{update(10, -80)}}
+	reports := []common.KPIReport{closedReport, openReport}
+
+	testCases := []struct {
+		name     string
+		rule     common.AlertRule
+		since    int64
+		expected []common.PeriodKey
+	}{
+		{"any drift", common.AlertRule{}, since, []common.PeriodKey{"2023-05", "2023-06"}},
+		{"absolute delta", common.AlertRule{AbsoluteDelta: 50}, since, []common.PeriodKey{"2023-06"}},
+		{"relative delta", common.AlertRule{RelativeDelta: 3}, since, []common.PeriodKey{"2023-05", "2023-06"}},
+		{"relative delta above the drifts", common.AlertRule{RelativeDelta: 10}, since, nil},
+		{"only after period", common.AlertRule{OnlyAfterPeriod: true}, since, []common.PeriodKey{"2023-05"}},
+		{"min days after period", common.AlertRule{MinDaysAfterPeriod: 9}, since, []common.PeriodKey{"2023-05"}},
+		{"min days after period not reached", common.AlertRule{MinDaysAfterPeriod: 10}, since, nil},
+		// the drift of June has no score and is skipped
+		{"unusual drifts", common.AlertRule{MinAnomalyLevel: common.AnomalyUnusual}, since, []common.PeriodKey{"2023-05"}},
+		{"anomalous drifts", common.AlertRule{MinAnomalyLevel: common.AnomalyAnomalous}, since, nil},
+		{"unscored drifts", common.AlertRule{MinAnomalyLevel: common.AnomalyAnomalous, AlertUnscored: true}, since, []common.PeriodKey{"2023-06"}},
+		{"unusual and unscored drifts", common.AlertRule{MinAnomalyLevel: common.AnomalyUnusual, AlertUnscored: true}, since, []common.PeriodKey{"2023-05", "2023-06"}},
+		{"drifts already notified", common.AlertRule{}, update(10, 0).CommitTimestamp, nil},
+		{"history just built", common.AlertRule{}, 0, nil},
+	}
+
+	for _, tc := range testCases {
+		tc.rule.Notifiers = []string{"team"}
+		metric := common.MetricConfig{MetricName: "revenue", Alerts: []common.AlertRule{tc.rule}}
+		alerts, err := Evaluate(metric, reports, tc.since)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		var periods []common.PeriodKey
+		for _, alert := range alerts {
+			periods = append(periods, alert.PeriodId)
+		}
+		if len(periods) != len(tc.expected) {
+			t.Errorf("%s: expected alerts on %v, got %v", tc.name, tc.expected, periods)
+			continue
+		}
+		for i := range periods {
+			if periods[i] != tc.expected[i] {
+				t.Errorf("%s: expected alerts on %v, got %v", tc.name, tc.expected, periods)
+			}
+		}
+	}
+}
+
+func TestEvaluate_ZeroInitialValue(t *testing.T) {
+	report := common.KPIReport{KPIName: "refunds 2023-05", PeriodId: "2023-05", Events: []common.EventObject{
+		{CommitTimestamp: 2, Diff: 4, Current: decimal.NewFromInt(4), EventType: common.EventTypeUpdate},
+	}}
+	metric := common.MetricConfig{MetricName: "refunds", Alerts: []common.AlertRule{{RelativeDelta: 50, Notifiers: []string{"team"}}}}
+	alerts, _ := Evaluate(metric, []common.KPIReport{report}, 1)
+	if len(alerts) != 1 {
+		t.Errorf("Expected any drift of a KPI starting at zero to be relevant, got %v", alerts)
+	}
+}
+
+func TestEvaluate_SeveralRules(t *testing.T) {
+	report := common.KPIReport{KPIName: "revenue 2023-05", PeriodId: "2023-05", InitialValue: decimal.NewFromInt(1000), Events: []common.EventObject{
+		{CommitTimestamp: 2, Diff: 80, Current: decimal.NewFromInt(1080), EventType: common.EventTypeUpdate},
+	}}
+	metric := common.MetricConfig{MetricName: "revenue", Alerts: []common.AlertRule{
+		{AbsoluteDelta: 10, Notifiers: []string{"team", "oncall"}},
+		{RelativeDelta: 5, Notifiers: []string{"team", "finance"}},
+	}}
+	alerts, _ := Evaluate(metric, []common.KPIReport{report}, 1)
+	if len(alerts) != 1 {
+		t.Fatalf("Expected a single alert for a drift matching several rules, got %v", alerts)
+	}
+	if !reflect.DeepEqual(alerts[0].Notifiers, []string{"team", "oncall", "finance"}) {
+		t.Errorf("Expected each notifier once, got %v", alerts[0].Notifiers)
+	}
+}
@@ -0,0 +1,168 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+// Notifier sends alerts to a destination.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// httpClient only connects to public addresses, so that the URL of a notifier can not reach the internal network.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: rejectNonPublicAddress}).DialContext,
+	},
+}
+
+// rejectNonPublicAddress refuses the connections to private, loopback, link-local and unspecified addresses.
+// It runs on the resolved address of every connection, redirects included, so that DNS can not point elsewhere.
+func rejectNonPublicAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("notifiers can not connect to the non public address %s", host)
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
+
+// WebhookNotifier posts the alerts as JSON.
+type WebhookNotifier struct {
+	Url string
+}
+
+func (notifier WebhookNotifier) Notify(alert Alert) error {
+	return postJSON(notifier.Url, alert)
+}
+
+// SlackNotifier posts the alerts to a Slack compatible incoming webhook.
+type SlackNotifier struct {
+	Url string
+}
+
+func (notifier SlackNotifier) Notify(alert Alert) error {
+	return postJSON(notifier.Url, map[string]string{"text": alert.Message()})
+}
+
+func postJSON(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier sends the alerts by email through the SMTP server of the server config.
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (notifier EmailNotifier) Notify(alert Alert) error {
+	var auth smtp.Auth
+	if notifier.Username != "" {
+		auth = smtp.PlainAuth("", notifier.Username, notifier.Password, notifier.Host)
+	}
+	subject := fmt.Sprintf("[DataDrift] %s drifted", alert.KPIName)
+	message := "From: " + notifier.From + "\r\n" +
+		"To: " + strings.Join(notifier.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(alert.Message(), "\n", "\r\n") + "\r\n"
+	address := notifier.Host + ":" + strconv.Itoa(notifier.Port)
+	return smtp.SendMail(address, auth, notifier.From, notifier.To, []byte(message))
+}
+
+// NewNotifiers builds the notifiers of a config by name.
+// The email notifiers send through the SMTP server of SMTP_HOST, SMTP_PORT, SMTP_USERNAME and SMTP_PASSWORD,
+// which is never taken from the repository config.
+func NewNotifiers(configs []common.NotifierConfig) (map[string]Notifier, error) {
+	notifiers := make(map[string]Notifier)
+	for _, config := range configs {
+		switch config.Type {
+		case common.WebhookNotifier, common.SlackNotifier:
+			notifierUrl, err := url.Parse(config.Url)
+			if err != nil || (notifierUrl.Scheme != "http" && notifierUrl.Scheme != "https") {
+				return nil, fmt.Errorf("notifier %s needs an http or https url", config.Name)
+			}
+			if config.Type == common.WebhookNotifier {
+				notifiers[config.Name] = WebhookNotifier{Url: config.Url}
+			} else {
+				notifiers[config.Name] = SlackNotifier{Url: config.Url}
+			}
+		case common.EmailNotifier:
+			host := os.Getenv("SMTP_HOST")
+			if host == "" {
+				return nil, fmt.Errorf("notifier %s needs SMTP_HOST to be set on the server", config.Name)
+			}
+			port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+			if err != nil || port <= 0 {
+				port = 587
+			}
+			notifiers[config.Name] = EmailNotifier{
+				Host:     host,
+				Port:     port,
+				Username: os.Getenv("SMTP_USERNAME"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     config.From,
+				To:       config.To,
+			}
+		default:
+			return nil, fmt.Errorf("unknown notifier type %s of %s", config.Type, config.Name)
+		}
+	}
+	return notifiers, nil
+}
+
+// Send notifies every alert to the notifiers of its rule, and returns the errors of all the failed notifications.
+func Send(alerts []Alert, notifiers map[string]Notifier) error {
+	var errs []error
+	for _, alert := range alerts {
+		for _, notifierName := range alert.Notifiers {
+			notifier, ok := notifiers[notifierName]
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown notifier %s", notifierName))
+				continue
+			}
+			if err := notifier.Notify(alert); err != nil {
+				errs = append(errs, fmt.Errorf("notifying %s of %s: %v", notifierName, alert.KPIName, err.Error()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
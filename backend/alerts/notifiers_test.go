@@ -0,0 +1,183 @@
+package alerts
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+var testAlert = Alert{
+	MetricName: "revenue",
+	KPIName:    "revenue 2023-05",
+	PeriodId:   "2023-05",
+	Event:      common.EventObject{CommitTimestamp: 1685620800, Diff: 30, Current: decimal.NewFromInt(1030), EventType: common.EventTypeUpdate, CommitUrl: "https://github.com/acme/data/commit/abc"},
+	Notifiers:  []string{"team"},
+}
+
+// allowLocalNotifiers lets the notifiers reach the local stand-in servers of a test.
+func allowLocalNotifiers(t *testing.T) {
+	publicClient := httpClient
+	httpClient = &http.Client{Timeout: publicClient.Timeout}
+	t.Cleanup(func() { httpClient = publicClient })
+}
+
+func TestWebhookNotifiers(t *testing.T) {
+	allowLocalNotifiers(t)
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Unexpected body: %v", err)
+		}
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+
+	notifiers, err := NewNotifiers([]common.NotifierConfig{
+		{Name: "hook", Type: common.WebhookNotifier, Url: server.URL},
+		{Name: "team", Type: common.SlackNotifier, Url: server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alert := testAlert
+	alert.Notifiers = []string{"hook", "team"}
+	if err := Send([]Alert{alert}, notifiers); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(bodies))
+	}
+	if bodies[0]["kpiName"] != "revenue 2023-05" || bodies[0]["periodId"] != "2023-05" {
+		t.Errorf("Unexpected webhook payload %v", bodies[0])
+	}
+	if text, _ := bodies[1]["text"].(string); !strings.Contains(text, "revenue 2023-05 drifted by +30 to 1030") || !strings.Contains(text, alert.Event.CommitUrl) {
+		t.Errorf("Unexpected slack payload %v", bodies[1])
+	}
+}
+
+func TestWebhookNotifier_Error(t *testing.T) {
+	allowLocalNotifiers(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := Send([]Alert{testAlert}, map[string]Notifier{"team": WebhookNotifier{Url: server.URL}})
+	if err == nil {
+		t.Error("Expected an error when the webhook fails")
+	}
+	if err := Send([]Alert{testAlert}, map[string]Notifier{}); err == nil {
+		t.Error("Expected an error for an unknown notifier")
+	}
+}
+
+func TestWebhookNotifier_NonPublicAddresses(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	if err := (WebhookNotifier{Url: server.URL}).Notify(testAlert); err == nil || requested {
+		t.Error("Expected the webhook on a loopback address to be rejected")
+	}
+	for _, address := range []string{"10.0.0.1", "192.168.1.1", "169.254.169.254", "::1", "fe80::1", "0.0.0.0"} {
+		if isPublicIP(net.ParseIP(address)) {
+			t.Errorf("Expected %s not to be public", address)
+		}
+	}
+	if !isPublicIP(net.ParseIP("140.82.112.3")) {
+		t.Error("Expected a public address to be allowed")
+	}
+	if _, err := NewNotifiers([]common.NotifierConfig{{Name: "hook", Type: common.WebhookNotifier, Url: "file:///etc/passwd"}}); err == nil {
+		t.Error("Expected an error for a webhook url which is not http")
+	}
+}
+
+// fakeSMTPServer accepts a single SMTP session and sends the received message on its channel.
+func fakeSMTPServer(t *testing.T) (net.Listener, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		write := func(line string) { io.WriteString(conn, line+"\r\n") }
+		write("220 localhost ESMTP")
+		var message strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				write("250 localhost")
+			case command == "DATA":
+				write("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					message.WriteString(dataLine)
+				}
+				write("250 OK")
+				messages <- message.String()
+			case command == "QUIT":
+				write("221 Bye")
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+	return listener, messages
+}
+
+func TestEmailNotifier(t *testing.T) {
+	listener, messages := fakeSMTPServer(t)
+	defer listener.Close()
+	t.Setenv("SMTP_HOST", "127.0.0.1")
+	t.Setenv("SMTP_PORT", strings.Split(listener.Addr().String(), ":")[1])
+
+	notifiers, err := NewNotifiers([]common.NotifierConfig{
+		{Name: "team", Type: common.EmailNotifier, From: "drift@example.com", To: []string{"finance@example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Send([]Alert{testAlert}, notifiers); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	message := <-messages
+	if !strings.Contains(message, "Subject: [DataDrift] revenue 2023-05 drifted") || !strings.Contains(message, "To: finance@example.com") {
+		t.Errorf("Unexpected message %s", message)
+	}
+}
+
+func TestEmailNotifier_WithoutSMTPHost(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	_, err := NewNotifiers([]common.NotifierConfig{{Name: "team", Type: common.EmailNotifier, From: "drift@example.com", To: []string{"finance@example.com"}}})
+	if err == nil {
+		t.Error("Expected an error for an email notifier without SMTP_HOST")
+	}
+}
@@ -0,0 +1,325 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+// retailPatterns are the weeks of the three months of each quarter of a retail calendar.
+var retailPatterns = map[string][]int{
+	"4-4-5": {4, 4, 5},
+	"4-5-4": {4, 5, 4},
+	"5-4-4": {5, 4, 4},
+}
+
+// Calendar generates the period keys of the rows and parses them back into the bounds of their periods.
+// Hours and days are the ones of the clock of its location. Weeks start on its week start day.
+// Quarters and years follow its fiscal year, named after the calendar year in which it ends.
+// The months of a retail calendar are made of whole weeks, its years starting on the week start day
+// nearest to the first day of the fiscal year start month.
+type Calendar struct {
+	config               *common.CalendarConfig
+	location             *time.Location
+	fiscalYearStartMonth time.Month
+	weekStart            time.Weekday
+	retailWeeks          []int
+}
+
+// Standard is the calendar of ISO weeks, calendar quarters and calendar years in UTC.
+func Standard() Calendar {
+	calendar, _ := New(nil, time.UTC)
+	return calendar
+}
+
+// New returns the calendar of a config in a location, the standard calendar for a nil config.
+func New(config *common.CalendarConfig, location *time.Location) (Calendar, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	calendar := Calendar{config: config, location: location, fiscalYearStartMonth: time.January, weekStart: time.Monday}
+	if config == nil {
+		return calendar, nil
+	}
+	if config.FiscalYearStartMonth != 0 {
+		if config.FiscalYearStartMonth < 1 || config.FiscalYearStartMonth > 12 {
+			return calendar, fmt.Errorf("invalid fiscal year start month %d", config.FiscalYearStartMonth)
+		}
+		calendar.fiscalYearStartMonth = time.Month(config.FiscalYearStartMonth)
+	}
+	switch strings.ToLower(config.WeekStart) {
+	case "", "monday":
+	case "sunday":
+		calendar.weekStart = time.Sunday
+	default:
+		return calendar, fmt.Errorf("invalid week start %s", config.WeekStart)
+	}
+	if config.RetailPattern != "" {
+		weeks, ok := retailPatterns[config.RetailPattern]
+		if !ok {
+			return calendar, fmt.Errorf("invalid retail pattern %s", config.RetailPattern)
+		}
+		calendar.retailWeeks = weeks
+	}
+	return calendar, nil
+}
+
+// ForMetricConfig returns the calendar in which the rows of a metric are bucketed into periods.
+func ForMetricConfig(metric common.MetricConfig) (Calendar, error) {
+	location, err := metric.GetLocation()
+	if err != nil {
+		return Standard(), err
+	}
+	return New(metric.Calendar, location)
+}
+
+// ForMetric returns the calendar of the periods of a stored metric, the standard calendar of its location when its calendar is invalid.
+func ForMetric(metric common.Metric) Calendar {
+	calendar, err := New(metric.Calendar, metric.GetLocation())
+	if err != nil {
+		calendar, _ = New(nil, metric.GetLocation())
+	}
+	return calendar
+}
+
+// Config returns the config of the calendar, nil for the standard calendar.
+func (calendar Calendar) Config() *common.CalendarConfig {
+	return calendar.config
+}
+
+// Location returns the location of the wall clock of the periods.
+func (calendar Calendar) Location() *time.Location {
+	return calendar.location
+}
+
+// TimeGrain returns the time grain of a period key.
+func TimeGrain(periodKey common.PeriodKey) (common.TimeGrain, error) {
+	key, err := parseKey(periodKey)
+	return key.grain, err
+}
+
+// parsedKey is a period key split into its parts, the wall clock of its start for hours and days.
+type parsedKey struct {
+	grain  common.TimeGrain
+	year   int
+	number int
+	clock  time.Time
+}
+
+func parseKey(periodKey common.PeriodKey) (parsedKey, error) {
+	key := string(periodKey)
+	if clock, err := time.Parse("2006-01-02T15", key); err == nil {
+		return parsedKey{grain: common.Hour, clock: clock}, nil
+	}
+	if clock, err := time.Parse("2006-01-02", key); err == nil {
+		return parsedKey{grain: common.Day, clock: clock}, nil
+	}
+	if len(key) == 8 && key[4:6] == "-W" {
+		year, yearErr := strconv.Atoi(key[:4])
+		week, weekErr := strconv.Atoi(key[6:])
+		if yearErr == nil && weekErr == nil && week >= 1 && week <= 53 {
+			return parsedKey{grain: common.Week, year: year, number: week}, nil
+		}
+	}
+	if month, err := time.Parse("2006-01", key); err == nil {
+		return parsedKey{grain: common.Month, year: month.Year(), number: int(month.Month())}, nil
+	}
+	if len(key) == 7 && key[4:6] == "-Q" {
+		year, yearErr := strconv.Atoi(key[:4])
+		quarter, quarterErr := strconv.Atoi(key[6:])
+		if yearErr == nil && quarterErr == nil && quarter >= 1 && quarter <= 4 {
+			return parsedKey{grain: common.Quarter, year: year, number: quarter}, nil
+		}
+	}
+	if year, err := time.Parse("2006", key); err == nil {
+		return parsedKey{grain: common.Year, year: year.Year()}, nil
+	}
+	return parsedKey{}, fmt.Errorf("invalid period key: %s", key)
+}
+
+// PeriodKey returns the key of the period of a time grain containing a time.
+func (calendar Calendar) PeriodKey(t time.Time, grain common.TimeGrain) (common.PeriodKey, error) {
+	t = t.In(calendar.location)
+	switch grain {
+	case common.Hour:
+		return common.PeriodKey(t.Format("2006-01-02T15")), nil
+	case common.Day:
+		return common.PeriodKey(t.Format("2006-01-02")), nil
+	case common.Week:
+		year, week := calendar.week(t)
+		return common.PeriodKey(fmt.Sprintf("%d-W%02d", year, week)), nil
+	case common.Month:
+		if calendar.retailWeeks == nil {
+			return common.PeriodKey(t.Format("2006-01")), nil
+		}
+		year, month := calendar.retailMonth(t)
+		return common.PeriodKey(fmt.Sprintf("%d-%02d", year, month)), nil
+	case common.Quarter:
+		year, quarter := calendar.quarter(t)
+		return common.PeriodKey(fmt.Sprintf("%d-Q%d", year, quarter)), nil
+	case common.Year:
+		return common.PeriodKey(fmt.Sprintf("%d", calendar.fiscalYear(t))), nil
+	default:
+		return "", fmt.Errorf("invalid time grain: %s", grain)
+	}
+}
+
+// Bounds returns the start of a period and the start of the next one.
+func (calendar Calendar) Bounds(periodKey common.PeriodKey) (time.Time, time.Time, error) {
+	key, err := parseKey(periodKey)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	switch key.grain {
+	case common.Hour:
+		start := time.Date(key.clock.Year(), key.clock.Month(), key.clock.Day(), key.clock.Hour(), 0, 0, 0, calendar.location)
+		return start, start.Add(time.Hour), nil
+	case common.Day:
+		start := calendar.date(key.clock.Year(), key.clock.Month(), key.clock.Day())
+		return start, start.AddDate(0, 0, 1), nil
+	case common.Week:
+		var firstWeekStart time.Time
+		if calendar.retailWeeks == nil {
+			// The first week of a year is the one containing its 4th of January
+			firstWeekStart = calendar.startOfWeek(calendar.date(key.year, time.January, 4))
+		} else {
+			firstWeekStart = calendar.fiscalYearStart(key.year)
+		}
+		start := firstWeekStart.AddDate(0, 0, 7*(key.number-1))
+		return start, start.AddDate(0, 0, 7), nil
+	case common.Month:
+		if calendar.retailWeeks == nil {
+			start := calendar.date(key.year, time.Month(key.number), 1)
+			return start, start.AddDate(0, 1, 0), nil
+		}
+		start, end := calendar.retailMonthBounds(key.year, key.number)
+		return start, end, nil
+	case common.Quarter:
+		if calendar.retailWeeks == nil {
+			start := calendar.fiscalYearStart(key.year).AddDate(0, 3*(key.number-1), 0)
+			return start, start.AddDate(0, 3, 0), nil
+		}
+		start, _ := calendar.retailMonthBounds(key.year, 3*key.number-2)
+		_, end := calendar.retailMonthBounds(key.year, 3*key.number)
+		return start, end, nil
+	default:
+		return calendar.fiscalYearStart(key.year), calendar.fiscalYearStart(key.year + 1), nil
+	}
+}
+
+// NextPeriodKey returns the key of the period following a period.
+func (calendar Calendar) NextPeriodKey(periodKey common.PeriodKey) (common.PeriodKey, error) {
+	grain, err := TimeGrain(periodKey)
+	if err != nil {
+		return "", err
+	}
+	_, end, err := calendar.Bounds(periodKey)
+	if err != nil {
+		return "", err
+	}
+	return calendar.PeriodKey(end, grain)
+}
+
+func (calendar Calendar) date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, calendar.location)
+}
+
+func (calendar Calendar) startOfWeek(t time.Time) time.Time {
+	day := calendar.date(t.Year(), t.Month(), t.Day())
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(calendar.weekStart) + 7) % 7))
+}
+
+// fiscalYearStart returns the first day of a fiscal year.
+func (calendar Calendar) fiscalYearStart(year int) time.Time {
+	startYear := year
+	if calendar.fiscalYearStartMonth != time.January {
+		startYear = year - 1
+	}
+	start := calendar.date(startYear, calendar.fiscalYearStartMonth, 1)
+	if calendar.retailWeeks == nil {
+		return start
+	}
+	offset := (int(calendar.weekStart) - int(start.Weekday()) + 7) % 7
+	if offset > 3 {
+		offset -= 7
+	}
+	return start.AddDate(0, 0, offset)
+}
+
+func (calendar Calendar) fiscalYear(t time.Time) int {
+	year := t.Year()
+	if calendar.fiscalYearStartMonth != time.January && t.Month() >= calendar.fiscalYearStartMonth {
+		year++
+	}
+	// A retail year may start a few days before or after the first day of its start month
+	if t.Before(calendar.fiscalYearStart(year)) {
+		year--
+	} else if !t.Before(calendar.fiscalYearStart(year + 1)) {
+		year++
+	}
+	return year
+}
+
+// week returns the year and the number of the week containing a time.
+// Outside of a retail calendar, a week belongs to the year of its 4th day, as ISO weeks do.
+func (calendar Calendar) week(t time.Time) (int, int) {
+	weekStart := calendar.startOfWeek(t)
+	if calendar.retailWeeks == nil {
+		fourthDay := weekStart.AddDate(0, 0, 3)
+		return fourthDay.Year(), (fourthDay.YearDay()-1)/7 + 1
+	}
+	year := calendar.fiscalYear(t)
+	return year, daysBetween(calendar.fiscalYearStart(year), weekStart)/7 + 1
+}
+
+func (calendar Calendar) quarter(t time.Time) (int, int) {
+	if calendar.retailWeeks != nil {
+		year, month := calendar.retailMonth(t)
+		return year, (month-1)/3 + 1
+	}
+	monthsSinceYearStart := (int(t.Month()) - int(calendar.fiscalYearStartMonth) + 12) % 12
+	return calendar.fiscalYear(t), monthsSinceYearStart/3 + 1
+}
+
+// retailMonthWeeks returns the weeks of each month of a retail year, the 53rd week of a long year going to its last month.
+func (calendar Calendar) retailMonthWeeks(year int) []int {
+	monthWeeks := make([]int, 12)
+	for i := range monthWeeks {
+		monthWeeks[i] = calendar.retailWeeks[i%3]
+	}
+	yearWeeks := daysBetween(calendar.fiscalYearStart(year), calendar.fiscalYearStart(year+1)) / 7
+	monthWeeks[11] += yearWeeks - 52
+	return monthWeeks
+}
+
+func (calendar Calendar) retailMonth(t time.Time) (int, int) {
+	year, week := calendar.week(t)
+	weeksBeforeMonth := 0
+	for i, weeks := range calendar.retailMonthWeeks(year) {
+		weeksBeforeMonth += weeks
+		if week <= weeksBeforeMonth {
+			return year, i + 1
+		}
+	}
+	return year, 12
+}
+
+func (calendar Calendar) retailMonthBounds(year int, month int) (time.Time, time.Time) {
+	monthWeeks := calendar.retailMonthWeeks(year)
+	weeksBeforeMonth := 0
+	for _, weeks := range monthWeeks[:month-1] {
+		weeksBeforeMonth += weeks
+	}
+	start := calendar.fiscalYearStart(year).AddDate(0, 0, 7*weeksBeforeMonth)
+	return start, start.AddDate(0, 0, 7*monthWeeks[month-1])
+}
+
+// daysBetween counts the days between two dates, whatever the daylight saving time changes in between.
+func daysBetween(from time.Time, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
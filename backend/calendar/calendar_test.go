@@ -0,0 +1,140 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+func newCalendar(t *testing.T, config *common.CalendarConfig) Calendar {
+	calendar, err := New(config, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return calendar
+}
+
+func TestPeriodKey(t *testing.T) {
+	standard := Standard()
+	fiscal := newCalendar(t, &common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday"})
+	retail := newCalendar(t, &common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday", RetailPattern: "4-4-5"})
+
+	testCases := []struct {
+		calendar Calendar
+		date     time.Time
+		grain    common.TimeGrain
+		expected common.PeriodKey
+	}{
+		{standard, time.Date(2023, 5, 1, 14, 30, 0, 0, time.UTC), common.Hour, "2023-05-01T14"},
+		{standard, time.Date(2023, 5, 1, 14, 30, 0, 0, time.UTC), common.Day, "2023-05-01"},
+		{standard, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), common.Week, "2022-W52"},
+		{standard, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), common.Week, "2025-W01"},
+		{standard, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Month, "2023-05"},
+		{standard, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Quarter, "2023-Q2"},
+		{standard, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Year, "2023"},
+		// Sunday 1 January 2023 starts the first week of 2023
+		{fiscal, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), common.Week, "2023-W01"},
+		{fiscal, time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC), common.Quarter, "2023-Q4"},
+		{fiscal, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), common.Quarter, "2024-Q1"},
+		{fiscal, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Quarter, "2024-Q2"},
+		{fiscal, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Month, "2023-05"},
+		{fiscal, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), common.Year, "2024"},
+		// The retail year 2024 runs from Sunday 29 January 2023 to Saturday 3 February 2024
+		{retail, time.Date(2023, 1, 28, 0, 0, 0, 0, time.UTC), common.Year, "2023"},
+		{retail, time.Date(2023, 1, 29, 0, 0, 0, 0, time.UTC), common.Week, "2024-W01"},
+		{retail, time.Date(2023, 2, 25, 0, 0, 0, 0, time.UTC), common.Month, "2024-01"},
+		{retail, time.Date(2023, 2, 26, 0, 0, 0, 0, time.UTC), common.Month, "2024-02"},
+		{retail, time.Date(2023, 3, 26, 0, 0, 0, 0, time.UTC), common.Month, "2024-03"},
+		{retail, time.Date(2023, 4, 30, 0, 0, 0, 0, time.UTC), common.Quarter, "2024-Q2"},
+		{retail, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), common.Week, "2024-W53"},
+		{retail, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), common.Month, "2024-12"},
+		{retail, time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC), common.Year, "2025"},
+	}
+
+	for _, tc := range testCases {
+		key, err := tc.calendar.PeriodKey(tc.date, tc.grain)
+		if err != nil || key != tc.expected {
+			t.Errorf("PeriodKey(%s, %s) = %s, %v; want %s", tc.date.Format("2006-01-02"), tc.grain, key, err, tc.expected)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	standard := Standard()
+	fiscal := newCalendar(t, &common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday"})
+	retail := newCalendar(t, &common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday", RetailPattern: "4-4-5"})
+
+	testCases := []struct {
+		calendar  Calendar
+		periodKey common.PeriodKey
+		start     time.Time
+		end       time.Time
+		next      common.PeriodKey
+	}{
+		{standard, "2023-W01", time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC), "2023-W02"},
+		{standard, "2023-Q4", time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "2024-Q1"},
+		{fiscal, "2023-W01", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 8, 0, 0, 0, 0, time.UTC), "2023-W02"},
+		{fiscal, "2024-Q1", time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), "2024-Q2"},
+		{fiscal, "2024-Q4", time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "2025-Q1"},
+		{fiscal, "2024", time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "2025"},
+		{retail, "2024-01", time.Date(2023, 1, 29, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 26, 0, 0, 0, 0, time.UTC), "2024-02"},
+		{retail, "2024-03", time.Date(2023, 3, 26, 0, 0, 0, 0, time.UTC), time.Date(2023, 4, 30, 0, 0, 0, 0, time.UTC), "2024-04"},
+		{retail, "2024-Q1", time.Date(2023, 1, 29, 0, 0, 0, 0, time.UTC), time.Date(2023, 4, 30, 0, 0, 0, 0, time.UTC), "2024-Q2"},
+		// The 53rd week of a long retail year goes to its last month
+		{retail, "2024-12", time.Date(2023, 12, 24, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC), "2025-01"},
+		{retail, "2024", time.Date(2023, 1, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC), "2025"},
+	}
+
+	for _, tc := range testCases {
+		start, end, err := tc.calendar.Bounds(tc.periodKey)
+		if err != nil || !start.Equal(tc.start) || !end.Equal(tc.end) {
+			t.Errorf("Bounds(%s) = %v, %v, %v; want %v, %v", tc.periodKey, start, end, err, tc.start, tc.end)
+		}
+		if next, err := tc.calendar.NextPeriodKey(tc.periodKey); err != nil || next != tc.next {
+			t.Errorf("NextPeriodKey(%s) = %s, %v; want %s", tc.periodKey, next, err, tc.next)
+		}
+	}
+}
+
+func TestBounds_Location(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	calendar, _ := New(nil, paris)
+	start, _, _ := calendar.Bounds("2023-05")
+	if !start.Equal(time.Date(2023, 4, 30, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected May to start at midnight in Paris, got %v", start)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for _, config := range []common.CalendarConfig{
+		{FiscalYearStartMonth: 13},
+		{WeekStart: "friday"},
+		{RetailPattern: "4-4-4"},
+	} {
+		if _, err := New(&config, time.UTC); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
+
+func TestTimeGrain(t *testing.T) {
+	testCases := map[common.PeriodKey]common.TimeGrain{
+		"2023-05-01T14": common.Hour,
+		"2023-05-01":    common.Day,
+		"2023-W17":      common.Week,
+		"2023-05":       common.Month,
+		"2023-Q2":       common.Quarter,
+		"2023":          common.Year,
+	}
+	for periodKey, expected := range testCases {
+		if timeGrain, err := TimeGrain(periodKey); err != nil || timeGrain != expected {
+			t.Errorf("TimeGrain(%s) = %s, %v; want %s", periodKey, timeGrain, err, expected)
+		}
+	}
+	for _, periodKey := range []common.PeriodKey{"2023-W54", "2023-Q5", "May 2023"} {
+		if _, err := TimeGrain(periodKey); err == nil {
+			t.Errorf("Expected an error for %s", periodKey)
+		}
+	}
+}
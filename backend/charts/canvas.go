@@ -0,0 +1,19 @@
+package charts
+
+// canvas is a surface the charts are drawn on, in the coordinates of a width x height chart.
+type canvas interface {
+	rect(x, y, w, h float64, color string, tooltip string)
+	line(x1, y1, x2, y2 float64, color string, strokeWidth float64, dashed bool)
+	polyline(points []Point, color string, strokeWidth float64, tooltip string)
+	circle(x, y, radius float64, color string, tooltip string)
+	// text draws a label vertically centered on y, anchored at x by its start, middle or end.
+	text(x, y float64, label string, size float64, anchor string, color string)
+	// verticalText draws a label rotated a quarter turn counterclockwise, centered on x, y.
+	verticalText(x, y float64, label string, size float64, color string)
+}
+
+const (
+	anchorStart  = "start"
+	anchorMiddle = "middle"
+	anchorEnd    = "end"
+)
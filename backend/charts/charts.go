@@ -0,0 +1,220 @@
+package charts
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/data-drift/data-drift/helpers"
+)
+
+const (
+	width        = 640
+	height       = 320
+	marginTop    = 36
+	marginRight  = 16
+	marginBottom = 48
+	marginLeft   = 72
+	plotWidth    = width - marginLeft - marginRight
+	plotHeight   = height - marginTop - marginBottom
+
+	increaseColor = "#2e9e5b"
+	decreaseColor = "#d6453d"
+	totalColor    = "#6b7a90"
+	gridColor     = "#e3e7ed"
+	textColor     = "#36404d"
+)
+
+// WaterfallStep is a bar of a waterfall chart: a total starting from zero, or a change starting from the previous total.
+type WaterfallStep struct {
+	Label   string
+	Value   float64
+	IsTotal bool
+}
+
+// Point is a point of a line chart.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Series is a line of a line chart, its color being derived from its name.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Waterfall renders the steps of a KPI as an SVG waterfall chart.
+func Waterfall(title string, steps []WaterfallStep) string {
+	svg := newSVGCanvas(title)
+	drawWaterfall(svg, steps)
+	return svg.String()
+}
+
+// WaterfallPNG renders the steps of a KPI as a PNG waterfall chart.
+func WaterfallPNG(title string, steps []WaterfallStep) ([]byte, error) {
+	image := newPNGCanvas()
+	drawWaterfall(image, steps)
+	return image.encode()
+}
+
+// LineChart renders series as an SVG line chart, with a legend of their names.
+func LineChart(title string, xLabel string, yLabel string, series []Series) string {
+	svg := newSVGCanvas(title)
+	drawLineChart(svg, xLabel, yLabel, series)
+	return svg.String()
+}
+
+// LineChartPNG renders series as a PNG line chart, with a legend of their names.
+func LineChartPNG(title string, xLabel string, yLabel string, series []Series) ([]byte, error) {
+	image := newPNGCanvas()
+	drawLineChart(image, xLabel, yLabel, series)
+	return image.encode()
+}
+
+func drawWaterfall(c canvas, steps []WaterfallStep) {
+	starts := make([]float64, len(steps))
+	ends := make([]float64, len(steps))
+	total := 0.0
+	for i, step := range steps {
+		if step.IsTotal {
+			starts[i], ends[i] = 0, step.Value
+		} else {
+			starts[i], ends[i] = total, total+step.Value
+		}
+		total = ends[i]
+	}
+	low, high := 0.0, 0.0
+	for i := range steps {
+		low = math.Min(low, math.Min(starts[i], ends[i]))
+		high = math.Max(high, math.Max(starts[i], ends[i]))
+	}
+	yAxis := newAxis(low, high)
+
+	yAxis.render(c)
+	if len(steps) == 0 {
+		return
+	}
+	slot := float64(plotWidth) / float64(len(steps))
+	barWidth := math.Max(slot*0.7, 1)
+	labelEvery := int(math.Ceil(float64(len(steps)) / 12))
+	for i, step := range steps {
+		color := totalColor
+		if !step.IsTotal && step.Value >= 0 {
+			color = increaseColor
+		} else if !step.IsTotal {
+			color = decreaseColor
+		}
+		x := marginLeft + slot*float64(i) + (slot-barWidth)/2
+		top := yAxis.position(math.Max(starts[i], ends[i]))
+		barHeight := math.Max(yAxis.position(math.Min(starts[i], ends[i]))-top, 1)
+		c.rect(x, top, barWidth, barHeight, color, step.Label+": "+formatValue(step.Value))
+		if i+1 < len(steps) {
+			connectorY := yAxis.position(ends[i])
+			c.line(x+barWidth, connectorY, x+slot, connectorY, totalColor, 1, true)
+		}
+		if i%labelEvery == 0 {
+			c.text(x+barWidth/2, height-marginBottom+12, step.Label, 10, anchorMiddle, textColor)
+		}
+	}
+}
+
+func drawLineChart(c canvas, xLabel string, yLabel string, series []Series) {
+	xLow, xHigh, yLow, yHigh := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, line := range series {
+		for _, point := range line.Points {
+			xLow, xHigh = math.Min(xLow, point.X), math.Max(xHigh, point.X)
+			yLow, yHigh = math.Min(yLow, point.Y), math.Max(yHigh, point.Y)
+		}
+	}
+	if math.IsInf(xLow, 1) {
+		xLow, xHigh, yLow, yHigh = 0, 1, 0, 1
+	}
+	xAxis := newAxis(xLow, xHigh)
+	yAxis := newAxis(yLow, yHigh)
+
+	yAxis.render(c)
+	for _, tick := range xAxis.ticks() {
+		c.text(xAxis.horizontalPosition(tick), height-marginBottom+12, formatValue(tick), 10, anchorMiddle, textColor)
+	}
+	c.text(marginLeft+plotWidth/2, height-12, xLabel, 11, anchorMiddle, textColor)
+	c.verticalText(14, marginTop+plotHeight/2, yLabel, 11, textColor)
+
+	for i, line := range series {
+		color := helpers.GetColorFromString(line.Name)
+		points := make([]Point, len(line.Points))
+		for j, point := range line.Points {
+			points[j] = Point{X: xAxis.horizontalPosition(point.X), Y: yAxis.position(point.Y)}
+		}
+		c.polyline(points, color, 1.5, line.Name)
+		for j, point := range points {
+			c.circle(point.X, point.Y, 2, color, line.Name+": "+formatValue(line.Points[j].Y))
+		}
+		legendX := float64(marginLeft + (i%6)*(plotWidth/6))
+		legendY := float64(10 + (i/6)*12)
+		if legendY < marginTop-8 {
+			c.rect(legendX, legendY-4, 8, 8, color, "")
+			c.text(legendX+11, legendY, line.Name, 10, anchorStart, textColor)
+		}
+	}
+}
+
+// axis maps the values of a range rounded to nice ticks onto the plot.
+type axis struct {
+	low  float64
+	high float64
+	step float64
+}
+
+func newAxis(low float64, high float64) axis {
+	if low == high {
+		low, high = low-1, high+1
+	}
+	step := niceStep((high - low) / 5)
+	return axis{low: math.Floor(low/step) * step, high: math.Ceil(high/step) * step, step: step}
+}
+
+// niceStep rounds a step up to 1, 2, 5 or 10 times a power of ten.
+func niceStep(rawStep float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(rawStep)))
+	for _, factor := range []float64{1, 2, 5} {
+		if rawStep <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func (a axis) ticks() []float64 {
+	var ticks []float64
+	for i := 0; a.low+float64(i)*a.step <= a.high+a.step/2; i++ {
+		tick := math.Round((a.low+float64(i)*a.step)/a.step) * a.step
+		if tick == 0 {
+			// no negative zero
+			tick = 0
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+// position is the vertical coordinate of a value.
+func (a axis) position(value float64) float64 {
+	return marginTop + plotHeight*(a.high-value)/(a.high-a.low)
+}
+
+func (a axis) horizontalPosition(value float64) float64 {
+	return marginLeft + plotWidth*(value-a.low)/(a.high-a.low)
+}
+
+// render draws the grid lines and the labels of a vertical axis.
+func (a axis) render(c canvas) {
+	for _, tick := range a.ticks() {
+		y := a.position(tick)
+		c.line(marginLeft, y, width-marginRight, y, gridColor, 1, false)
+		c.text(marginLeft-6, y, formatValue(tick), 10, anchorEnd, textColor)
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
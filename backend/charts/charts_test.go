@@ -0,0 +1,82 @@
+package charts
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// assertWellFormed fails when the chart is not a well formed SVG document.
+func assertWellFormed(t *testing.T, svg string) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Malformed SVG: %v\n%s", err, svg)
+		}
+	}
+}
+
+func TestWaterfall(t *testing.T) {
+	svg := Waterfall("revenue <2023-05>", []WaterfallStep{
+		{Label: "Initial", Value: 1000, IsTotal: true},
+		{Label: "2023-06-02", Value: 30},
+		{Label: "2023-06-10", Value: -80},
+		{Label: "Latest", Value: 950, IsTotal: true},
+	})
+	assertWellFormed(t, svg)
+
+	if strings.Count(svg, "<rect") != 4 {
+		t.Errorf("Expected 4 bars, got %s", svg)
+	}
+	for _, expected := range []string{increaseColor, decreaseColor, totalColor, "revenue &lt;2023-05&gt;", "2023-06-10: -80", ">1000<"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected the chart to contain %s, got %s", expected, svg)
+		}
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	svg := LineChart("Cohorts month", "Days after the period", "Drift (%)", []Series{
+		{Name: "2023-04", Points: []Point{{0, 0}, {3, 1.5}, {10, 2}}},
+		{Name: "2023-05", Points: []Point{{0, 0}, {5, -0.5}}},
+	})
+	assertWellFormed(t, svg)
+
+	if strings.Count(svg, "<polyline") != 2 || !strings.Contains(svg, ">2023-05</text>") {
+		t.Errorf("Expected a line and a legend per cohort, got %s", svg)
+	}
+	assertWellFormed(t, LineChart("Empty", "x", "y", nil))
+}
+
+func TestAxisTicks(t *testing.T) {
+	testCases := []struct {
+		low      float64
+		high     float64
+		expected []float64
+	}{
+		{0, 1000, []float64{0, 200, 400, 600, 800, 1000}},
+		{-80, 30, []float64{-100, -50, 0, 50}},
+		{0.1, 0.27, []float64{0.1, 0.15, 0.2, 0.25, 0.3}},
+		{5, 5, []float64{4, 4.5, 5, 5.5, 6}},
+	}
+
+	for _, tc := range testCases {
+		ticks := newAxis(tc.low, tc.high).ticks()
+		if len(ticks) != len(tc.expected) {
+			t.Errorf("newAxis(%v, %v).ticks() = %v, want %v", tc.low, tc.high, ticks, tc.expected)
+			continue
+		}
+		for i := range ticks {
+			if formatValue(ticks[i]) != formatValue(tc.expected[i]) {
+				t.Errorf("newAxis(%v, %v).ticks() = %v, want %v", tc.low, tc.high, ticks, tc.expected)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package charts
+
+import (
+	"sort"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/reducers"
+)
+
+// WaterfallSteps returns the initial value of a KPI report, its drifts dated in the location of the metric and its latest value.
+func WaterfallSteps(report common.KPIReport, location *time.Location) []WaterfallStep {
+	steps := []WaterfallStep{{Label: "Initial", Value: helpers.GetFloat(report.InitialValue), IsTotal: true}}
+	for _, event := range report.Events {
+		if event.EventType == common.EventTypeUpdate {
+			steps = append(steps, WaterfallStep{Label: time.Unix(event.CommitTimestamp, 0).In(location).Format("2006-01-02"), Value: event.Diff})
+		}
+	}
+	return append(steps, WaterfallStep{Label: "Latest", Value: helpers.GetFloat(report.LatestValue), IsTotal: true})
+}
+
+// CohortSeries returns a series per cohort of the relative drift by days after its first computation,
+// the points repeating the previous value being dropped except the last one.
+func CohortSeries(data reducers.CohortsData) []Series {
+	var series []Series
+	for _, cohortDate := range data.CohortDates {
+		var points []Point
+		for _, event := range data.CohortsMetricsMetadata[cohortDate].RelativeHistory {
+			points = append(points, Point{X: helpers.GetFloat(event.DaysFromHistorization), Y: helpers.GetFloat(event.RelativeValue)})
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].X < points[j].X })
+		line := Series{Name: cohortDate}
+		for i, point := range points {
+			if i == 0 || i == len(points)-1 || point.Y != points[i-1].Y {
+				line.Points = append(line.Points, point)
+			}
+		}
+		series = append(series, line)
+	}
+	return series
+}
+
+// CohortChart renders the cohorts of a time grain as an SVG line chart.
+func CohortChart(data reducers.CohortsData) string {
+	return LineChart(cohortChartTitle(data), cohortChartXLabel, cohortChartYLabel, CohortSeries(data))
+}
+
+// CohortChartPNG renders the cohorts of a time grain as a PNG line chart.
+func CohortChartPNG(data reducers.CohortsData) ([]byte, error) {
+	return LineChartPNG(cohortChartTitle(data), cohortChartXLabel, cohortChartYLabel, CohortSeries(data))
+}
+
+const (
+	cohortChartXLabel = "Days after the first computation"
+	cohortChartYLabel = "Drift (%)"
+)
+
+func cohortChartTitle(data reducers.CohortsData) string {
+	return "Cohorts " + string(data.TimeGrain)
+}
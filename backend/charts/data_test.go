@@ -0,0 +1,58 @@
+package charts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/shopspring/decimal"
+)
+
+func TestWaterfallSteps(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	report := common.KPIReport{
+		InitialValue: decimal.NewFromInt(1000),
+		LatestValue:  decimal.NewFromInt(950),
+		Events: []common.EventObject{
+			{CommitTimestamp: 1685570400, EventType: common.EventTypeCreate, Current: decimal.NewFromInt(1000)},
+			// 2023-06-01T22:30:00Z, already June 2 in Paris
+			{CommitTimestamp: 1685658600, EventType: common.EventTypeUpdate, Diff: -50, Current: decimal.NewFromInt(950)},
+		},
+	}
+
+	expected := []WaterfallStep{
+		{Label: "Initial", Value: 1000, IsTotal: true},
+		{Label: "2023-06-02", Value: -50},
+		{Label: "Latest", Value: 950, IsTotal: true},
+	}
+	if steps := WaterfallSteps(report, paris); !reflect.DeepEqual(steps, expected) {
+		t.Errorf("WaterfallSteps() = %v, want %v", steps, expected)
+	}
+}
+
+func TestCohortSeries(t *testing.T) {
+	event := func(days int64, value int64) reducers.RelativeHistoricalEvent {
+		return reducers.RelativeHistoricalEvent{DaysFromHistorization: decimal.NewFromInt(days), RelativeValue: decimal.NewFromInt(value)}
+	}
+	data := reducers.CohortsData{
+		TimeGrain:   common.Month,
+		CohortDates: []string{"2023-05"},
+		CohortsMetricsMetadata: map[string]reducers.MetricMetadata{
+			"2023-05": {RelativeHistory: map[time.Duration]reducers.RelativeHistoricalEvent{
+				4 * 24 * time.Hour: event(4, 1),
+				0:                  event(0, 0),
+				2 * 24 * time.Hour: event(2, 1),
+				3 * 24 * time.Hour: event(3, 1),
+				24 * time.Hour:     event(1, 1),
+			}},
+		},
+	}
+
+	// the repeated values of days 2 and 3 are dropped, the last point being kept
+	expected := []Series{{Name: "2023-05", Points: []Point{{0, 0}, {1, 1}, {4, 1}}}}
+	if series := CohortSeries(data); !reflect.DeepEqual(series, expected) {
+		t.Errorf("CohortSeries() = %v, want %v", series, expected)
+	}
+}
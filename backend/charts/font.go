@@ -0,0 +1,76 @@
+package charts
+
+import "unicode"
+
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+// glyphs is a 5x7 bitmap font drawing the text of the PNG charts, lower case letters being drawn upper case.
+var glyphs = map[rune][glyphHeight]string{
+	' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	'%':  {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'_':  {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'<':  {"...#.", "..#..", ".#...", "#....", ".#...", "..#..", "...#."},
+	'>':  {".#...", "..#..", "...#.", "....#", "...#.", "..#..", ".#..."},
+	'=':  {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'!':  {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	'#':  {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'&':  {".##..", "#..#.", "#.#..", ".#...", "#.#.#", "#..#.", ".##.#"},
+	'\'': {"..#..", "..#..", ".#...", ".....", ".....", ".....", "....."},
+}
+
+// glyph returns the bitmap of a character, a question mark for the characters missing from the font.
+func glyph(r rune) [glyphHeight]string {
+	if bitmap, ok := glyphs[unicode.ToUpper(r)]; ok {
+		return bitmap
+	}
+	return glyphs['?']
+}
@@ -0,0 +1,163 @@
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pngScale is the number of pixels per unit of the chart coordinates.
+const pngScale = 2
+
+// pngCanvas rasterizes the shapes of a chart on a white image, the text being drawn with a bitmap font.
+type pngCanvas struct {
+	image *image.RGBA
+}
+
+func newPNGCanvas() *pngCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width*pngScale, height*pngScale))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	return &pngCanvas{image: img}
+}
+
+func (canvas *pngCanvas) encode() ([]byte, error) {
+	var content bytes.Buffer
+	if err := png.Encode(&content, canvas.image); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// fill paints the pixels of a rectangle of pixel coordinates, clipped to the image.
+func (canvas *pngCanvas) fill(x0, y0, x1, y1 int, c color.RGBA) {
+	bounds := canvas.image.Bounds()
+	for y := maxInt(y0, bounds.Min.Y); y < minInt(y1, bounds.Max.Y); y++ {
+		for x := maxInt(x0, bounds.Min.X); x < minInt(x1, bounds.Max.X); x++ {
+			canvas.image.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func (canvas *pngCanvas) rect(x, y, w, h float64, hexColor string, tooltip string) {
+	canvas.fill(toPixel(x), toPixel(y), toPixel(x+w), toPixel(y+h), parseHexColor(hexColor))
+}
+
+func (canvas *pngCanvas) line(x1, y1, x2, y2 float64, hexColor string, strokeWidth float64, dashed bool) {
+	c := parseHexColor(hexColor)
+	thickness := maxInt(int(math.Round(strokeWidth*pngScale)), 1)
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := maxInt(int(math.Ceil(length*pngScale)), 1)
+	for i := 0; i <= steps; i++ {
+		progress := float64(i) / float64(steps)
+		// dashes of 2 units every 4 units
+		if dashed && math.Mod(progress*length, 4) >= 2 {
+			continue
+		}
+		x := toPixel(x1+(x2-x1)*progress) - thickness/2
+		y := toPixel(y1+(y2-y1)*progress) - thickness/2
+		canvas.fill(x, y, x+thickness, y+thickness, c)
+	}
+}
+
+func (canvas *pngCanvas) polyline(points []Point, hexColor string, strokeWidth float64, tooltip string) {
+	for i := 1; i < len(points); i++ {
+		canvas.line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, hexColor, strokeWidth, false)
+	}
+}
+
+func (canvas *pngCanvas) circle(x, y, radius float64, hexColor string, tooltip string) {
+	c := parseHexColor(hexColor)
+	centerX, centerY, pixelRadius := x*pngScale, y*pngScale, radius*pngScale
+	for py := int(centerY - pixelRadius); py <= int(centerY+pixelRadius); py++ {
+		for px := int(centerX - pixelRadius); px <= int(centerX+pixelRadius); px++ {
+			if math.Hypot(float64(px)+0.5-centerX, float64(py)+0.5-centerY) <= pixelRadius {
+				canvas.fill(px, py, px+1, py+1, c)
+			}
+		}
+	}
+}
+
+// dotSize is the size in pixels of a dot of the bitmap font for a font size.
+func dotSize(size float64) int {
+	return maxInt(int(math.Round(size*pngScale/10)), 1)
+}
+
+func (canvas *pngCanvas) text(x, y float64, label string, size float64, anchor string, hexColor string) {
+	dot := dotSize(size)
+	textWidth := (len([]rune(label))*glyphAdvance - 1) * dot
+	left := toPixel(x)
+	switch anchor {
+	case anchorMiddle:
+		left -= textWidth / 2
+	case anchorEnd:
+		left -= textWidth
+	}
+	top := toPixel(y) - glyphHeight*dot/2
+	c := parseHexColor(hexColor)
+	for i, r := range []rune(label) {
+		bitmap := glyph(r)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if bitmap[row][column] == '#' {
+					px := left + (i*glyphAdvance+column)*dot
+					py := top + row*dot
+					canvas.fill(px, py, px+dot, py+dot, c)
+				}
+			}
+		}
+	}
+}
+
+func (canvas *pngCanvas) verticalText(x, y float64, label string, size float64, hexColor string) {
+	dot := dotSize(size)
+	textWidth := (len([]rune(label))*glyphAdvance - 1) * dot
+	// the text goes up from its bottom, the top of the glyphs facing left
+	bottom := toPixel(y) + textWidth/2
+	left := toPixel(x) - glyphHeight*dot/2
+	c := parseHexColor(hexColor)
+	for i, r := range []rune(label) {
+		bitmap := glyph(r)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if bitmap[row][column] == '#' {
+					px := left + row*dot
+					py := bottom - (i*glyphAdvance+column+1)*dot
+					canvas.fill(px, py, px+dot, py+dot, c)
+				}
+			}
+		}
+	}
+}
+
+func toPixel(value float64) int {
+	return int(math.Round(value * pngScale))
+}
+
+// parseHexColor reads a #rrggbb color, black when it is malformed.
+func parseHexColor(hexColor string) color.RGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hexColor, "#"), 16, 32)
+	if err != nil || len(hexColor) != 7 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
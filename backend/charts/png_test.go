@@ -0,0 +1,102 @@
+package charts
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/data-drift/data-drift/helpers"
+)
+
+func TestWaterfallPNG(t *testing.T) {
+	content, err := WaterfallPNG("revenue 2023-05", []WaterfallStep{
+		{Label: "Initial", Value: 1000, IsTotal: true},
+		{Label: "2023-06-02", Value: 300},
+		{Label: "2023-06-10", Value: -500},
+		{Label: "Latest", Value: 800, IsTotal: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != width*pngScale || img.Bounds().Dy() != height*pngScale {
+		t.Errorf("Expected a %dx%d image, got %v", width*pngScale, height*pngScale, img.Bounds())
+	}
+
+	// the y axis goes from 0 to 1400 and the bars are centered in 4 slots
+	slot := float64(plotWidth) / 4
+	barColorAt := func(step int, value float64) color.Color {
+		x := marginLeft + slot*(float64(step)+0.5)
+		y := marginTop + plotHeight*(1400-value)/1400
+		return img.At(toPixel(x), toPixel(y))
+	}
+	testCases := []struct {
+		step     int
+		value    float64
+		expected string
+	}{
+		{0, 500, totalColor},
+		{1, 1150, increaseColor},
+		{2, 1000, decreaseColor},
+		{3, 400, totalColor},
+	}
+	for _, tc := range testCases {
+		if got := barColorAt(tc.step, tc.value); !sameColor(got, parseHexColor(tc.expected)) {
+			t.Errorf("Expected bar %d to be %s, got %v", tc.step, tc.expected, got)
+		}
+	}
+	if got := img.At(2, 2); !sameColor(got, color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("Expected a white background, got %v", got)
+	}
+}
+
+func TestLineChartPNG(t *testing.T) {
+	content, err := LineChartPNG("Cohorts month", "Days after the period", "Drift (%)", []Series{
+		{Name: "2023-04", Points: []Point{{0, 0}, {10, 2}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+
+	lineColor := parseHexColor(helpers.GetColorFromString("2023-04"))
+	// the line goes from the bottom left to the top right corner of the plot
+	middle := img.At(toPixel(marginLeft+plotWidth/2), toPixel(marginTop+plotHeight/2))
+	if !sameColor(middle, lineColor) {
+		t.Errorf("Expected the line to cross the middle of the plot, got %v", middle)
+	}
+	textPixels := 0
+	textColorRGBA := parseHexColor(textColor)
+	for y := toPixel(height - 20); y < toPixel(height); y++ {
+		for x := 0; x < width*pngScale; x++ {
+			if sameColor(img.At(x, y), textColorRGBA) {
+				textPixels++
+			}
+		}
+	}
+	if textPixels == 0 {
+		t.Errorf("Expected the x label to be drawn")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	if got := parseHexColor("#2e9e5b"); got != (color.RGBA{0x2e, 0x9e, 0x5b, 0xff}) {
+		t.Errorf("parseHexColor(#2e9e5b) = %v", got)
+	}
+	if got := parseHexColor("green"); got != (color.RGBA{A: 0xff}) {
+		t.Errorf("Expected black for a malformed color, got %v", got)
+	}
+}
+
+func sameColor(a color.Color, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
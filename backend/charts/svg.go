@@ -0,0 +1,77 @@
+package charts
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// svgCanvas writes the shapes of a chart as SVG markup.
+type svgCanvas struct {
+	builder strings.Builder
+}
+
+func newSVGCanvas(title string) *svgCanvas {
+	svg := &svgCanvas{}
+	fmt.Fprintf(&svg.builder, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" role="img" aria-label="%s">`, width, height, width, height, escape(title))
+	fmt.Fprintf(&svg.builder, `<title>%s</title>`, escape(title))
+	return svg
+}
+
+func (svg *svgCanvas) String() string {
+	return svg.builder.String() + "</svg>"
+}
+
+func (svg *svgCanvas) rect(x, y, w, h float64, color string, tooltip string) {
+	fmt.Fprintf(&svg.builder, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s">%s</rect>`,
+		formatCoordinate(x), formatCoordinate(y), formatCoordinate(w), formatCoordinate(h), color, svgTooltip(tooltip))
+}
+
+func (svg *svgCanvas) line(x1, y1, x2, y2 float64, color string, strokeWidth float64, dashed bool) {
+	dashArray := ""
+	if dashed {
+		dashArray = ` stroke-dasharray="2,2"`
+	}
+	fmt.Fprintf(&svg.builder, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"%s/>`,
+		formatCoordinate(x1), formatCoordinate(y1), formatCoordinate(x2), formatCoordinate(y2), color, formatCoordinate(strokeWidth), dashArray)
+}
+
+func (svg *svgCanvas) polyline(points []Point, color string, strokeWidth float64, tooltip string) {
+	coordinates := make([]string, len(points))
+	for i, point := range points {
+		coordinates[i] = formatCoordinate(point.X) + "," + formatCoordinate(point.Y)
+	}
+	fmt.Fprintf(&svg.builder, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s">%s</polyline>`,
+		strings.Join(coordinates, " "), color, formatCoordinate(strokeWidth), svgTooltip(tooltip))
+}
+
+func (svg *svgCanvas) circle(x, y, radius float64, color string, tooltip string) {
+	fmt.Fprintf(&svg.builder, `<circle cx="%s" cy="%s" r="%s" fill="%s">%s</circle>`,
+		formatCoordinate(x), formatCoordinate(y), formatCoordinate(radius), color, svgTooltip(tooltip))
+}
+
+func (svg *svgCanvas) text(x, y float64, label string, size float64, anchor string, color string) {
+	fmt.Fprintf(&svg.builder, `<text x="%s" y="%s" font-size="%s" text-anchor="%s" dominant-baseline="middle" fill="%s">%s</text>`,
+		formatCoordinate(x), formatCoordinate(y), formatCoordinate(size), anchor, color, escape(label))
+}
+
+func (svg *svgCanvas) verticalText(x, y float64, label string, size float64, color string) {
+	fmt.Fprintf(&svg.builder, `<text x="%s" y="%s" font-size="%s" text-anchor="middle" dominant-baseline="middle" fill="%s" transform="rotate(-90 %s %s)">%s</text>`,
+		formatCoordinate(x), formatCoordinate(y), formatCoordinate(size), color, formatCoordinate(x), formatCoordinate(y), escape(label))
+}
+
+func svgTooltip(tooltip string) string {
+	if tooltip == "" {
+		return ""
+	}
+	return "<title>" + escape(tooltip) + "</title>"
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+func escape(text string) string {
+	return html.EscapeString(text)
+}
@@ -0,0 +1,77 @@
+package common
+
+import "fmt"
+
+// AlertRule selects the drifts of a metric worth an alert, every condition it sets having to hold.
+type AlertRule struct {
+	// Minimum absolute change of the KPI
+	AbsoluteDelta float64 `json:"absoluteDelta,omitempty"`
+	// Minimum change of the KPI, in % of its initial value
+	RelativeDelta float64 `json:"relativeDelta,omitempty"`
+	// Only alert on the drifts committed once the period is closed
+	OnlyAfterPeriod bool `json:"onlyAfterPeriod,omitempty"`
+	// Minimum number of days between the end of the period and the drift
+	MinDaysAfterPeriod int `json:"minDaysAfterPeriod,omitempty"`
+	// Minimum anomaly level of the drift, unusual or anomalous
+	MinAnomalyLevel AnomalyLevel `json:"minAnomalyLevel,omitempty"`
+	// Alert on the drifts without enough history to be scored, which a minimum anomaly level skips otherwise
+	AlertUnscored bool `json:"alertUnscored,omitempty"`
+	// Names of the notifiers of the config the alerts are sent to
+	Notifiers []string `json:"notifiers"`
+}
+
+const (
+	WebhookNotifier = "webhook"
+	SlackNotifier   = "slack"
+	EmailNotifier   = "email"
+)
+
+// NotifierConfig is a destination of the alerts: a webhook receiving them as JSON, a Slack incoming webhook or email recipients.
+type NotifierConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// URL of the webhook and slack notifiers
+	Url string `json:"url,omitempty"`
+	// Sender and recipients of the email notifier, the SMTP server being configured on the server
+	From string   `json:"from,omitempty"`
+	To   []string `json:"to,omitempty"`
+}
+
+// ValidateAlerts checks that the notifiers are well defined and that every alert rule sends its alerts to one of them.
+func (config Config) ValidateAlerts() error {
+	notifierNames := make(map[string]bool)
+	for _, notifier := range config.Notifiers {
+		if notifier.Name == "" || notifierNames[notifier.Name] {
+			return fmt.Errorf("notifiers need a unique name, got %q", notifier.Name)
+		}
+		notifierNames[notifier.Name] = true
+		switch notifier.Type {
+		case WebhookNotifier, SlackNotifier:
+			if notifier.Url == "" {
+				return fmt.Errorf("notifier %s needs a url", notifier.Name)
+			}
+		case EmailNotifier:
+			if notifier.From == "" || len(notifier.To) == 0 {
+				return fmt.Errorf("notifier %s needs a from address and recipients", notifier.Name)
+			}
+		default:
+			return fmt.Errorf("notifier %s has an unknown type %s", notifier.Name, notifier.Type)
+		}
+	}
+	for _, metric := range config.Metrics {
+		for _, rule := range metric.Alerts {
+			if rule.MinAnomalyLevel != "" && !rule.MinAnomalyLevel.IsValid() {
+				return fmt.Errorf("an alert of %s has an unknown anomaly level %s", metric.MetricName, rule.MinAnomalyLevel)
+			}
+			if len(rule.Notifiers) == 0 {
+				return fmt.Errorf("an alert of %s has no notifier", metric.MetricName)
+			}
+			for _, notifierName := range rule.Notifiers {
+				if !notifierNames[notifierName] {
+					return fmt.Errorf("an alert of %s uses the unknown notifier %s", metric.MetricName, notifierName)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package common
+
+type AnomalyLevel string
+
+const (
+	AnomalyNormal    AnomalyLevel = "normal"
+	AnomalyUnusual   AnomalyLevel = "unusual"
+	AnomalyAnomalous AnomalyLevel = "anomalous"
+)
+
+// AnomalyScore compares a drift to the drifts the previous periods of the metric had at the same age.
+type AnomalyScore struct {
+	// Robust z-score of the drift: its distance to the median of the previous drifts, in scaled median absolute deviations
+	Score float64      `json:"score"`
+	Level AnomalyLevel `json:"level"`
+	// Number of previous periods the drift is compared to
+	ReferenceCohorts int `json:"referenceCohorts"`
+}
+
+var anomalyLevelRanks = map[AnomalyLevel]int{AnomalyNormal: 0, AnomalyUnusual: 1, AnomalyAnomalous: 2}
+
+// IsValid tells whether the level is one of the known levels.
+func (level AnomalyLevel) IsValid() bool {
+	_, ok := anomalyLevelRanks[level]
+	return ok
+}
+
+// AtLeast tells whether the level is as severe as another one.
+func (level AnomalyLevel) AtLeast(other AnomalyLevel) bool {
+	return anomalyLevelRanks[level] >= anomalyLevelRanks[other]
+}
@@ -1,6 +1,10 @@
 package common
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/data-drift/data-drift/filters"
 	"github.com/shopspring/decimal"
 )
 
@@ -27,12 +31,14 @@ type SyncConfig struct {
 }
 
 type EventObject struct {
-	CommitTimestamp int64            `json:"commitTimestamp"`
-	CommitUrl       string           `json:"commitUrl"`
-	Diff            float64          `json:"diff"`
-	Current         decimal.Decimal  `json:"current"`
-	EventType       EventType        `json:"eventType"`
-	CommitComments  []CommitComments `json:"commitComments"`
+	CommitTimestamp int64             `json:"commitTimestamp"`
+	CommitUrl       string            `json:"commitUrl"`
+	Diff            float64           `json:"diff"`
+	Current         decimal.Decimal   `json:"current"`
+	EventType       EventType         `json:"eventType"`
+	CommitComments  []CommitComments  `json:"commitComments"`
+	Attribution     *DriftAttribution `json:"attribution,omitempty"`
+	Anomaly         *AnomalyScore     `json:"anomaly,omitempty"`
 }
 
 type EventType string
@@ -55,6 +61,35 @@ type CommitData struct {
 	IsAfterPeriod   bool
 	CommitUrl       string
 	CommitComments  []CommitComments
+	Attribution     *DriftAttribution `json:",omitempty"`
+}
+
+type RowChangeType string
+
+const (
+	RowAdded    RowChangeType = "added"
+	RowRemoved  RowChangeType = "removed"
+	RowModified RowChangeType = "modified"
+)
+
+// RowContribution is the part of a KPI drift explained by a single row, identified by its unique key.
+type RowContribution struct {
+	RowKey        string          `json:"rowKey"`
+	ChangeType    RowChangeType   `json:"changeType"`
+	PreviousValue decimal.Decimal `json:"previousValue"`
+	CurrentValue  decimal.Decimal `json:"currentValue"`
+	Contribution  decimal.Decimal `json:"contribution"`
+	Share         float64         `json:"share"`
+}
+
+// DriftAttribution explains the KPI drift of a period and dimension between two snapshots.
+// Modified rows are the rows present in both snapshots whose KPI value changed.
+type DriftAttribution struct {
+	Delta           decimal.Decimal   `json:"delta"`
+	AddedRows       int               `json:"addedRows"`
+	RemovedRows     int               `json:"removedRows"`
+	ModifiedRows    int               `json:"modifiedRows"`
+	TopContributors []RowContribution `json:"topContributors"`
 }
 
 type MeasurementMetaData struct {
@@ -87,23 +122,71 @@ const NoDimensionValue DimensionValue = "No dimension"
 
 type MetricHistory map[CommitSha]CommitData
 type Metric struct {
-	TimeGrain      TimeGrain
-	Period         PeriodKey
-	Dimension      Dimension
-	DimensionValue DimensionValue
-	History        MetricHistory
+	TimeGrain       TimeGrain
+	Period          PeriodKey
+	Dimension       Dimension
+	DimensionValue  DimensionValue
+	DimensionValues DimensionValues `json:",omitempty"`
+	Timezone        string          `json:",omitempty"`
+	Calendar        *CalendarConfig `json:",omitempty"`
+	History         MetricHistory
 }
 type Metrics map[PeriodAndDimensionKey]Metric
 
+// MetricWatermark records the last commit whose snapshot has been merged into a stored metric history.
+type MetricWatermark struct {
+	CommitSha       CommitSha `json:"commitSha"`
+	CommitTimestamp int64     `json:"commitTimestamp"`
+	ConfigHash      string    `json:"configHash"`
+}
+
 type Config struct {
-	NotionAPIToken   string         `json:"notionAPIToken"`
-	NotionDatabaseID string         `json:"notionDatabaseId"`
-	Metrics          []MetricConfig `json:"metrics"`
+	NotionAPIToken   string              `json:"notionAPIToken,omitempty"`
+	NotionDatabaseID string              `json:"notionDatabaseId,omitempty"`
+	Timezone         string              `json:"timezone,omitempty"`
+	Calendar         *CalendarConfig     `json:"calendar,omitempty"`
+	Notifiers        []NotifierConfig    `json:"notifiers,omitempty"`
+	Slack            *SlackConfig        `json:"slack,omitempty"`
+	StaticReport     *StaticReportConfig `json:"staticReport,omitempty"`
+	Metrics          []MetricConfig      `json:"metrics"`
+}
+
+const (
+	StaticReportHTML     = "html"
+	StaticReportMarkdown = "markdown"
+)
+
+// StaticReportConfig is the static site the reports are published to, a page per metric,
+// in a directory of the server or in a branch of the repository.
+type StaticReportConfig struct {
+	// html, with the charts inlined, or markdown, with the charts next to the pages
+	Format string `json:"format,omitempty"`
+	// Directory of the site, relative to STATIC_REPORTS_DIR/<owner>/<repo> or to the root of the branch
+	Directory string `json:"directory,omitempty"`
+	// Branch of the repository the site is committed to, the site being written on the server when empty
+	Branch string `json:"branch,omitempty"`
+}
+
+// SlackConfig is the channel the drifts are reported to, through the Web API of Slack or of a compatible server.
+// The bot token and the Web API are settings of the server, read from the SLACK_BOT_TOKEN and SLACK_API_URL environment variables.
+type SlackConfig struct {
+	Channel string `json:"channel"`
+}
+
+// CalendarConfig sets the weeks, quarters and years of the periods, ISO weeks and calendar years by default.
+type CalendarConfig struct {
+	// First month of the fiscal year, fiscal years being named after the calendar year in which they end
+	FiscalYearStartMonth int `json:"fiscalYearStartMonth,omitempty"`
+	// First day of the week, monday or sunday
+	WeekStart string `json:"weekStart,omitempty"`
+	// Weeks of the months of each quarter of a retail calendar, e.g. 4-4-5
+	RetailPattern string `json:"retailPattern,omitempty"`
 }
 
 type TimeGrain string
 
 const (
+	Hour    TimeGrain = "hour"
 	Day     TimeGrain = "day"
 	Week    TimeGrain = "week"
 	Month   TimeGrain = "month"
@@ -112,11 +195,116 @@ const (
 )
 
 type MetricConfig struct {
-	Filepath       string      `json:"filepath"`
-	DateColumnName string      `json:"dateColumnName"`
-	KPIColumnName  string      `json:"KPIColumnName"`
-	MetricName     string      `json:"metricName"`
-	TimeGrains     []TimeGrain `json:"timeGrains"`
-	Dimensions     []string    `json:"dimensions"`
-	UpstreamFiles  []string    `json:"upstreamFiles"`
+	Filepath           string          `json:"filepath"`
+	DateColumnName     string          `json:"dateColumnName"`
+	KPIColumnName      string          `json:"KPIColumnName"`
+	MetricName         string          `json:"metricName"`
+	TimeGrains         []TimeGrain     `json:"timeGrains"`
+	Dimensions         []string        `json:"dimensions"`
+	DimensionGroups    [][]string      `json:"dimensionGroups,omitempty"`
+	MaxDimensionValues int             `json:"maxDimensionValues,omitempty"`
+	UpstreamFiles      []string        `json:"upstreamFiles"`
+	UniqueKey          []string        `json:"uniqueKey,omitempty"`
+	AttributionTopN    int             `json:"attributionTopN,omitempty"`
+	Aggregation        string          `json:"aggregation,omitempty"`
+	NumeratorColumn    string          `json:"numeratorColumn,omitempty"`
+	DenominatorColumn  string          `json:"denominatorColumn,omitempty"`
+	Filter             *filters.Filter `json:"filter,omitempty"`
+	Since              string          `json:"since,omitempty"`
+	Until              string          `json:"until,omitempty"`
+	Timezone           string          `json:"timezone,omitempty"`
+	Calendar           *CalendarConfig `json:"calendar,omitempty"`
+	Alerts             []AlertRule     `json:"alerts,omitempty"`
+	KPIs               []KPIConfig     `json:"kpis,omitempty"`
+}
+
+// KPIConfig is a KPI of a metric set, computed from the same file as the other KPIs of the set.
+type KPIConfig struct {
+	MetricName        string `json:"metricName"`
+	KPIColumnName     string `json:"KPIColumnName"`
+	Aggregation       string `json:"aggregation,omitempty"`
+	NumeratorColumn   string `json:"numeratorColumn,omitempty"`
+	DenominatorColumn string `json:"denominatorColumn,omitempty"`
+}
+
+// ExpandKPIs returns a metric config per KPI of a metric set, or the metric config itself when it has no KPI list.
+func (metric MetricConfig) ExpandKPIs() []MetricConfig {
+	if len(metric.KPIs) == 0 {
+		return []MetricConfig{metric}
+	}
+	metrics := make([]MetricConfig, 0, len(metric.KPIs))
+	for _, kpi := range metric.KPIs {
+		kpiMetric := metric
+		kpiMetric.KPIs = nil
+		kpiMetric.MetricName = kpi.MetricName
+		kpiMetric.KPIColumnName = kpi.KPIColumnName
+		kpiMetric.Aggregation = kpi.Aggregation
+		kpiMetric.NumeratorColumn = kpi.NumeratorColumn
+		kpiMetric.DenominatorColumn = kpi.DenominatorColumn
+		metrics = append(metrics, kpiMetric)
+	}
+	return metrics
+}
+
+// ExpandedMetrics returns the metric configs of every KPI, each one being addressed by its metric name.
+func (config Config) ExpandedMetrics() []MetricConfig {
+	var metrics []MetricConfig
+	for _, metric := range config.Metrics {
+		metrics = append(metrics, metric.ExpandKPIs()...)
+	}
+	return metrics
+}
+
+// ValidateMetricNames checks that every KPI can be addressed by its metric name.
+func (config Config) ValidateMetricNames() error {
+	metricNames := make(map[string]bool)
+	for _, metric := range config.ExpandedMetrics() {
+		if metricNames[metric.MetricName] {
+			return fmt.Errorf("duplicate metric name %s", metric.MetricName)
+		}
+		metricNames[metric.MetricName] = true
+	}
+	return nil
+}
+
+// WithDefaults returns the config whose metrics inherit its settings when they do not override them.
+func (config Config) WithDefaults() Config {
+	metrics := make([]MetricConfig, len(config.Metrics))
+	for i, metric := range config.Metrics {
+		if metric.Timezone == "" {
+			metric.Timezone = config.Timezone
+		}
+		if metric.Calendar == nil {
+			metric.Calendar = config.Calendar
+		}
+		metrics[i] = metric
+	}
+	config.Metrics = metrics
+	return config
+}
+
+// LoadLocation returns the location of an IANA timezone name, UTC when the name is empty.
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %v", timezone, err.Error())
+	}
+	return location, nil
+}
+
+// GetLocation returns the location in which the rows of the metric are bucketed into periods and its periods close.
+func (metric MetricConfig) GetLocation() (*time.Location, error) {
+	return LoadLocation(metric.Timezone)
+}
+
+// GetLocation returns the location of the periods of a stored metric, UTC when its timezone is unknown.
+func (metric Metric) GetLocation() *time.Location {
+	location, err := LoadLocation(metric.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
 }
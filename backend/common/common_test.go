@@ -0,0 +1,150 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestExpandKPIs(t *testing.T) {
+	metricSet := MetricConfig{
+		MetricName:     "orders",
+		Filepath:       "orders.csv",
+		DateColumnName: "created_at",
+		Dimensions:     []string{"country"},
+		KPIs: []KPIConfig{
+			{MetricName: "revenue", KPIColumnName: "amount"},
+			{MetricName: "order count", KPIColumnName: "order_id", Aggregation: "count_distinct"},
+		},
+	}
+
+	metrics := metricSet.ExpandKPIs()
+
+	if len(metrics) != 2 {
+		t.Fatalf("Expected 2 metrics, but got %d", len(metrics))
+	}
+	orderCount := metrics[1]
+	if orderCount.MetricName != "order count" || orderCount.KPIColumnName != "order_id" || orderCount.Aggregation != "count_distinct" {
+		t.Errorf("Unexpected KPI %+v", orderCount)
+	}
+	if orderCount.Filepath != "orders.csv" || orderCount.DateColumnName != "created_at" || len(orderCount.Dimensions) != 1 || orderCount.KPIs != nil {
+		t.Errorf("Expected the KPI to inherit the metric set, got %+v", orderCount)
+	}
+
+	single := MetricConfig{MetricName: "revenue", KPIColumnName: "amount"}
+	if expanded := single.ExpandKPIs(); len(expanded) != 1 || expanded[0].MetricName != "revenue" {
+		t.Errorf("Expected a metric without KPI list to be kept, got %+v", expanded)
+	}
+
+	config := Config{Metrics: []MetricConfig{metricSet, single}}
+	if err := config.ValidateMetricNames(); err == nil {
+		t.Error("Expected an error for the duplicate revenue metric")
+	}
+}
+
+func TestJsonSchemaValidatesMetricSets(t *testing.T) {
+	schemaLoader := gojsonschema.NewReferenceLoader("file://../json-schema.json")
+	testCases := []struct {
+		metric string
+		valid  bool
+	}{
+		{`{"metricName": "orders", "filepath": "orders.csv", "dateColumnName": "date", "kpis": [{"metricName": "revenue", "KPIColumnName": "amount"}, {"metricName": "conversion", "aggregation": "ratio", "numeratorColumn": "orders", "denominatorColumn": "visits"}]}`, true},
+		{`{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount"}`, true},
+		{`{"metricName": "orders", "filepath": "orders.csv", "dateColumnName": "date"}`, false},
+		{`{"metricName": "orders", "filepath": "orders.csv", "dateColumnName": "date", "kpis": [{"metricName": "revenue"}]}`, false},
+		{`{"metricName": "orders", "filepath": "orders.csv", "dateColumnName": "date", "kpis": [{"metricName": "conversion", "aggregation": "ratio", "numeratorColumn": "orders"}]}`, false},
+		{`{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount", "calendar": {"fiscalYearStartMonth": 2, "weekStart": "sunday", "retailPattern": "4-4-5"}}`, true},
+		{`{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount", "calendar": {"weekStart": "friday"}}`, false},
+		{`{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount", "alerts": [{"relativeDelta": 5, "onlyAfterPeriod": true, "notifiers": ["team"]}]}`, true},
+		{`{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount", "alerts": [{"absoluteDelta": 100}]}`, false},
+	}
+
+	for _, tc := range testCases {
+		config := `{"notionAPIToken": "token", "notionDatabaseId": "id", "metrics": [` + tc.metric + `]}`
+		result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewStringLoader(config))
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		if result.Valid() != tc.valid {
+			t.Errorf("%s: expected valid to be %v, got errors %v", tc.metric, tc.valid, result.Errors())
+		}
+	}
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	config := Config{
+		Timezone: "Europe/Paris",
+		Metrics:  []MetricConfig{{MetricName: "sales"}, {MetricName: "leads", Timezone: "America/New_York"}},
+	}
+	metrics := config.WithDefaults().Metrics
+	if metrics[0].Timezone != "Europe/Paris" || metrics[1].Timezone != "America/New_York" {
+		t.Errorf("Unexpected timezones %s and %s", metrics[0].Timezone, metrics[1].Timezone)
+	}
+	if config.Metrics[0].Timezone != "" {
+		t.Error("Expected the config to be left unchanged")
+	}
+
+	if location, err := LoadLocation(""); err != nil || location != time.UTC {
+		t.Errorf("Expected UTC by default, got %v", location)
+	}
+	if _, err := (MetricConfig{Timezone: "Mars/Olympus"}).GetLocation(); err == nil {
+		t.Error("Expected an error for an invalid timezone")
+	}
+}
+
+func TestValidateAlerts(t *testing.T) {
+	notifiers := []NotifierConfig{
+		{Name: "team", Type: SlackNotifier, Url: "https://hooks.example.com/team"},
+		{Name: "finance", Type: EmailNotifier, From: "drift@example.com", To: []string{"finance@example.com"}},
+	}
+	metrics := []MetricConfig{{MetricName: "revenue", Alerts: []AlertRule{{AbsoluteDelta: 100, Notifiers: []string{"team", "finance"}}}}}
+	if err := (Config{Notifiers: notifiers, Metrics: metrics}).ValidateAlerts(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if err := (Config{Notifiers: notifiers[:1], Metrics: metrics}).ValidateAlerts(); err == nil {
+		t.Error("Expected an error for the unknown finance notifier")
+	}
+	if err := (Config{Notifiers: []NotifierConfig{{Name: "team", Type: "pager"}}}).ValidateAlerts(); err == nil {
+		t.Error("Expected an error for an unknown notifier type")
+	}
+	if err := (Config{Notifiers: []NotifierConfig{{Name: "team", Type: WebhookNotifier}}}).ValidateAlerts(); err == nil {
+		t.Error("Expected an error for a webhook without url")
+	}
+	unknownLevel := []MetricConfig{{MetricName: "revenue", Alerts: []AlertRule{{MinAnomalyLevel: "critical", Notifiers: []string{"team"}}}}}
+	if err := (Config{Notifiers: notifiers, Metrics: unknownLevel}).ValidateAlerts(); err == nil {
+		t.Error("Expected an error for an unknown anomaly level")
+	}
+}
+
+func TestJsonSchemaValidatesReporters(t *testing.T) {
+	schemaLoader := gojsonschema.NewReferenceLoader("file://../json-schema.json")
+	metrics := `"metrics": [{"metricName": "revenue", "filepath": "orders.csv", "dateColumnName": "date", "KPIColumnName": "amount"}]`
+	testCases := []struct {
+		config string
+		valid  bool
+	}{
+		{`{` + metrics + `}`, true},
+		{`{"notionAPIToken": "token", "notionDatabaseId": "id", ` + metrics + `}`, true},
+		{`{"notionAPIToken": "token", ` + metrics + `}`, false},
+		{`{"slack": {"channel": "C123"}, ` + metrics + `}`, true},
+		{`{"notionAPIToken": "token", "notionDatabaseId": "id", "slack": {"channel": "C123"}, ` + metrics + `}`, true},
+		{`{"slack": {}, ` + metrics + `}`, false},
+		// the bot token is a secret of the server, not of the repository
+		{`{"slack": {"channel": "C123", "token": "xoxb"}, ` + metrics + `}`, false},
+		{`{"slack": {"channel": "C123", "apiUrl": "https://example.com/api"}, ` + metrics + `}`, false},
+		{`{"staticReport": {"format": "markdown", "directory": "docs", "branch": "gh-pages"}, ` + metrics + `}`, true},
+		{`{"staticReport": {"format": "pdf"}, ` + metrics + `}`, false},
+	}
+
+	for _, tc := range testCases {
+		result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewStringLoader(tc.config))
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		if result.Valid() != tc.valid {
+			t.Errorf("%s: expected valid to be %v, got errors %v", tc.config, tc.valid, result.Errors())
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NoDimension is the dimension of the metrics aggregating every row of a period.
+const NoDimension Dimension = "none"
+
+// OtherDimensionValue is the bucket of the combinations of values beyond the cardinality cap of a dimension group.
+const OtherDimensionValue = "(other)"
+
+// DimensionValues are the values of the columns of a dimension group, e.g. {"country": "FR", "channel": "ads"}.
+type DimensionValues map[string]string
+
+// NewDimension names the dimension of a group of columns.
+func NewDimension(columns []string) Dimension {
+	if len(columns) == 0 {
+		return NoDimension
+	}
+	return Dimension(strings.Join(columns, ","))
+}
+
+// Columns returns the columns of the dimension, in the order of its group.
+func (dimension Dimension) Columns() []string {
+	if dimension == NoDimension || dimension == "" {
+		return nil
+	}
+	return strings.Split(string(dimension), ",")
+}
+
+// NewPeriodAndDimensionKey identifies the metric of a period and combination of dimension values.
+// The values are URL encoded after the period, e.g. 2023-05?channel=ads&country=FR, so that keys never collide.
+func NewPeriodAndDimensionKey(period PeriodKey, values DimensionValues) PeriodAndDimensionKey {
+	if len(values) == 0 {
+		return PeriodAndDimensionKey(period)
+	}
+	return PeriodAndDimensionKey(string(period) + "?" + values.Encode())
+}
+
+func (values DimensionValues) Encode() string {
+	query := url.Values{}
+	for column, value := range values {
+		query.Set(column, value)
+	}
+	return query.Encode()
+}
+
+// Display returns the values in the order of the columns of the group, as shown in reports.
+func (values DimensionValues) Display(columns []string) DimensionValue {
+	if len(columns) == 0 {
+		return NoDimensionValue
+	}
+	displayedValues := make([]string, len(columns))
+	for i, column := range columns {
+		displayedValues[i] = values[column]
+	}
+	return DimensionValue(strings.Join(displayedValues, ", "))
+}
+
+// IsOther tells whether the values are the bucket of the values beyond the cardinality cap.
+func (values DimensionValues) IsOther() bool {
+	for _, value := range values {
+		if value != OtherDimensionValue {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+// DimensionFilter selects the metrics of a dimension group, and of a combination of values when they are given.
+// A zero filter selects the metrics without dimension.
+type DimensionFilter struct {
+	Columns []string
+	Values  DimensionValues
+	// Value of a legacy ?dimensionValue=FR link, given without its dimension, until it is resolved
+	legacyValue string
+}
+
+// ParseDimensionFilter reads the dimension filter of a query string, given as dimension and dimensionValue pairs:
+// ?dimension=country&dimensionValue=FR&dimension=channel&dimensionValue=ads
+// A single dimensionValue without dimension, as in the legacy links, must be resolved with ResolveLegacyValue.
+func ParseDimensionFilter(query url.Values) (DimensionFilter, error) {
+	columns := query["dimension"]
+	values := query["dimensionValue"]
+	if len(columns) == 0 && len(values) == 1 {
+		return DimensionFilter{legacyValue: values[0]}, nil
+	}
+	if len(values) > 0 && len(values) != len(columns) {
+		return DimensionFilter{}, fmt.Errorf("each dimension needs a dimensionValue")
+	}
+	filter := DimensionFilter{Columns: columns}
+	if len(values) > 0 {
+		filter.Values = make(DimensionValues)
+		for i, column := range columns {
+			filter.Values[column] = values[i]
+		}
+	}
+	return filter, nil
+}
+
+// ResolveLegacyValue finds the dimension of the value of a legacy link among the single column dimensions of the metrics.
+// Other filters are returned as they are.
+func (filter DimensionFilter) ResolveLegacyValue(metrics Metrics) (DimensionFilter, error) {
+	if filter.legacyValue == "" {
+		return filter, nil
+	}
+	var resolvedColumn string
+	for _, metric := range metrics {
+		columns := metric.Dimension.Columns()
+		if len(columns) != 1 || metric.DimensionValues[columns[0]] != filter.legacyValue || columns[0] == resolvedColumn {
+			continue
+		}
+		if resolvedColumn != "" {
+			return DimensionFilter{}, fmt.Errorf("dimensionValue %s is a value of several dimensions, give its dimension", filter.legacyValue)
+		}
+		resolvedColumn = columns[0]
+	}
+	if resolvedColumn == "" {
+		return DimensionFilter{}, fmt.Errorf("dimensionValue %s is not a value of any dimension, give its dimension", filter.legacyValue)
+	}
+	return DimensionFilter{Columns: []string{resolvedColumn}, Values: DimensionValues{resolvedColumn: filter.legacyValue}}, nil
+}
+
+// IsEmpty tells whether the filter selects the metrics without dimension.
+func (filter DimensionFilter) IsEmpty() bool {
+	return len(filter.Columns) == 0
+}
+
+// Matches tells whether a metric belongs to the dimension group, and has the values of the filter when they are given.
+func (filter DimensionFilter) Matches(metric Metric) bool {
+	if filter.IsEmpty() {
+		return metric.Dimension == NoDimension
+	}
+	metricColumns := metric.Dimension.Columns()
+	if len(metricColumns) != len(filter.Columns) {
+		return false
+	}
+	for _, column := range filter.Columns {
+		if _, ok := metric.DimensionValues[column]; !ok {
+			return false
+		}
+	}
+	for column, value := range filter.Values {
+		if metric.DimensionValues[column] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// DimensionQuery returns the query string selecting the metric in the reports, empty for the metrics without dimension.
+func (metric Metric) DimensionQuery() url.Values {
+	query := url.Values{}
+	for _, column := range metric.Dimension.Columns() {
+		query.Add("dimension", column)
+		query.Add("dimensionValue", metric.DimensionValues[column])
+	}
+	return query
+}
+
+// GetDimensionGroups returns the groups of columns whose combinations of values are tracked,
+// every dimension being a group of its own. Groups of the same columns in another order are the same group,
+// their metrics share the same keys, so only the first one is kept.
+func (metric MetricConfig) GetDimensionGroups() [][]string {
+	var groups [][]string
+	seenGroups := make(map[Dimension]bool)
+	for _, group := range append(stringsToGroups(metric.Dimensions), metric.DimensionGroups...) {
+		sortedGroup := append([]string(nil), group...)
+		sort.Strings(sortedGroup)
+		dimension := NewDimension(sortedGroup)
+		if len(group) == 0 || seenGroups[dimension] {
+			continue
+		}
+		seenGroups[dimension] = true
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func stringsToGroups(columns []string) [][]string {
+	groups := make([][]string, len(columns))
+	for i, column := range columns {
+		groups[i] = []string{column}
+	}
+	return groups
+}
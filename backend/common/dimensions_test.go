@@ -0,0 +1,104 @@
+package common
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestNewPeriodAndDimensionKey(t *testing.T) {
+	if key := NewPeriodAndDimensionKey("2023-05", nil); key != "2023-05" {
+		t.Errorf("Expected the period alone without dimension, got %s", key)
+	}
+
+	key := NewPeriodAndDimensionKey("2023-05", DimensionValues{"country": "FR", "channel": "ads"})
+	if key != "2023-05?channel=ads&country=FR" {
+		t.Errorf("Unexpected key %s", key)
+	}
+
+	// Values of different dimensions no longer collide.
+	countryKey := NewPeriodAndDimensionKey("2023-05", DimensionValues{"country": "FR"})
+	channelKey := NewPeriodAndDimensionKey("2023-05", DimensionValues{"channel": "FR"})
+	spacedKey := NewPeriodAndDimensionKey("2023-05", DimensionValues{"country": "FR US"})
+	if countryKey == channelKey || countryKey == spacedKey {
+		t.Errorf("Expected distinct keys, got %s, %s and %s", countryKey, channelKey, spacedKey)
+	}
+}
+
+func TestParseDimensionFilter(t *testing.T) {
+	query, _ := url.ParseQuery("dimension=country&dimensionValue=FR&dimension=channel&dimensionValue=ads")
+	filter, err := ParseDimensionFilter(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(filter.Values, DimensionValues{"country": "FR", "channel": "ads"}) {
+		t.Errorf("Unexpected values %v", filter.Values)
+	}
+
+	combination := Metric{Dimension: NewDimension([]string{"country", "channel"}), DimensionValues: DimensionValues{"country": "FR", "channel": "ads"}}
+	otherCombination := Metric{Dimension: NewDimension([]string{"country", "channel"}), DimensionValues: DimensionValues{"country": "FR", "channel": "seo"}}
+	country := Metric{Dimension: "country", DimensionValues: DimensionValues{"country": "FR"}}
+	if !filter.Matches(combination) || filter.Matches(otherCombination) || filter.Matches(country) {
+		t.Error("Expected the filter to only match its combination of values")
+	}
+
+	groupFilter, _ := ParseDimensionFilter(url.Values{"dimension": {"channel", "country"}})
+	if !groupFilter.Matches(combination) || !groupFilter.Matches(otherCombination) || groupFilter.Matches(country) {
+		t.Error("Expected a filter without values to match every combination of its group")
+	}
+
+	emptyFilter, _ := ParseDimensionFilter(url.Values{})
+	if !emptyFilter.Matches(Metric{Dimension: NoDimension}) || emptyFilter.Matches(country) {
+		t.Error("Expected an empty filter to match the metrics without dimension")
+	}
+
+	if _, err := ParseDimensionFilter(url.Values{"dimension": {"country", "channel"}, "dimensionValue": {"FR"}}); err == nil {
+		t.Error("Expected an error when a dimension has no value")
+	}
+}
+
+func TestResolveLegacyValue(t *testing.T) {
+	metrics := Metrics{
+		"2023-05?country=FR":             {Dimension: "country", DimensionValues: DimensionValues{"country": "FR"}},
+		"2023-05?country=US":             {Dimension: "country", DimensionValues: DimensionValues{"country": "US"}},
+		"2023-05?channel=ads":            {Dimension: "channel", DimensionValues: DimensionValues{"channel": "ads"}},
+		"2023-05?channel=US":             {Dimension: "channel", DimensionValues: DimensionValues{"channel": "US"}},
+		"2023-05?channel=seo&country=DE": {Dimension: NewDimension([]string{"country", "channel"}), DimensionValues: DimensionValues{"country": "DE", "channel": "seo"}},
+	}
+
+	legacyFilter, err := ParseDimensionFilter(url.Values{"dimensionValue": {"FR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter, err := legacyFilter.ResolveLegacyValue(metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(filter, DimensionFilter{Columns: []string{"country"}, Values: DimensionValues{"country": "FR"}}) {
+		t.Errorf("Expected the value to be resolved against the country dimension, got %+v", filter)
+	}
+
+	for _, value := range []string{"US", "DE", "unknown"} {
+		legacyFilter, _ := ParseDimensionFilter(url.Values{"dimensionValue": {value}})
+		if _, err := legacyFilter.ResolveLegacyValue(metrics); err == nil {
+			t.Errorf("Expected an error for %s, which is not the value of exactly one single column dimension", value)
+		}
+	}
+
+	query, _ := url.ParseQuery("dimension=channel&dimensionValue=ads")
+	channelFilter, _ := ParseDimensionFilter(query)
+	if filter, err := channelFilter.ResolveLegacyValue(metrics); err != nil || !reflect.DeepEqual(filter, channelFilter) {
+		t.Errorf("Expected a filter with its dimension to be left as is, got %+v %v", filter, err)
+	}
+}
+
+func TestGetDimensionGroups(t *testing.T) {
+	metric := MetricConfig{
+		Dimensions:      []string{"country", "channel"},
+		DimensionGroups: [][]string{{"country", "channel"}, {"country"}, {}, {"channel", "country"}},
+	}
+	expected := [][]string{{"country"}, {"channel"}, {"country", "channel"}}
+	if groups := metric.GetDimensionGroups(); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+}
@@ -8,12 +8,24 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
 type MetricStorageKey string
 
+// MetricStore persists the computed history of each metric, one Metrics map per MetricStorageKey.
+type MetricStore interface {
+	ReadMetricKPI(path MetricStorageKey) (Metrics, error)
+	WriteMetricKPI(repoOwner string, repoName string, metricName string, lineCountAndKPIByDateByVersion Metrics) (MetricStorageKey, error)
+	ListMetricKeys(prefix string) ([]MetricStorageKey, error)
+	DeleteMetricKPI(path MetricStorageKey) error
+	ReadWatermark(path MetricStorageKey) (MetricWatermark, error)
+	WriteWatermark(path MetricStorageKey, watermark MetricWatermark) error
+}
+
 func GetRedisClient() (*redis.Client, error) {
 
 	var redisURL = os.Getenv("REDIS_TLS_URL")
@@ -22,15 +34,16 @@ func GetRedisClient() (*redis.Client, error) {
 	}
 
 	redisOpt, redisErr := redis.ParseURL(redisURL)
-	redisOpt.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 
 	if redisErr != nil {
 		return nil, redisErr
 	}
+	redisOpt.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 	rdb := redis.NewClient(redisOpt)
 	return rdb, nil
 }
 
+// KpiRepository is the Redis implementation of MetricStore.
 type KpiRepository struct {
 	RedisClient *redis.Client
 }
@@ -56,20 +69,106 @@ func (h *KpiRepository) ReadMetricKPI(path MetricStorageKey) (Metrics, error) {
 	return data, nil
 }
 
-func (h *KpiRepository) WriteMetricKPI(repoOwner string, repoName string, metricName string, lineCountAndKPIByDateByVersion Metrics) MetricStorageKey {
+func (h *KpiRepository) WriteMetricKPI(repoOwner string, repoName string, metricName string, lineCountAndKPIByDateByVersion Metrics) (MetricStorageKey, error) {
 	metricStoredFilePath := NewGetMetricStorageKey(repoOwner, repoName, metricName)
 
 	jsonData, err := json.Marshal(lineCountAndKPIByDateByVersion)
 	if err != nil {
-		fmt.Printf("Error occurred during marshaling. Err: %s", err)
+		return "", fmt.Errorf("error occurred during marshaling: %v", err)
 	}
 	var ctx = context.Background() // TODO: use context from gin
 
 	err = h.RedisClient.Set(ctx, string(metricStoredFilePath), jsonData, 0).Err()
 	if err != nil {
-		fmt.Printf("Could not set key. Err: %s", err)
+		return "", fmt.Errorf("could not set key: %v", err)
+	}
+	return metricStoredFilePath, nil
+}
+
+// escapeGlobPattern escapes the special characters of a Redis match pattern, so that a prefix only matches itself.
+func escapeGlobPattern(prefix string) string {
+	return globPatternEscaper.Replace(prefix)
+}
+
+var globPatternEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// escapeLikePattern escapes the wildcards of a LIKE pattern, the escape character being a backslash.
+func escapeLikePattern(prefix string) string {
+	return likePatternEscaper.Replace(prefix)
+}
+
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (h *KpiRepository) ListMetricKeys(prefix string) ([]MetricStorageKey, error) {
+	var ctx = context.Background() // TODO: use context from gin
+
+	keys := []MetricStorageKey{}
+	iter := h.RedisClient.Scan(ctx, 0, escapeGlobPattern(prefix)+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		if strings.HasSuffix(iter.Val(), ":watermark") {
+			continue
+		}
+		keys = append(keys, MetricStorageKey(iter.Val()))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (h *KpiRepository) DeleteMetricKPI(path MetricStorageKey) error {
+	var ctx = context.Background() // TODO: use context from gin
+
+	return h.RedisClient.Del(ctx, string(path), watermarkRedisKey(path)).Err()
+}
+
+func watermarkRedisKey(path MetricStorageKey) string {
+	return string(path) + ":watermark"
+}
+
+func (h *KpiRepository) ReadWatermark(path MetricStorageKey) (MetricWatermark, error) {
+	var ctx = context.Background() // TODO: use context from gin
+
+	jsonData, err := h.RedisClient.Get(ctx, watermarkRedisKey(path)).Bytes()
+	if err != nil {
+		return MetricWatermark{}, err
+	}
+
+	var watermark MetricWatermark
+	err = json.Unmarshal(jsonData, &watermark)
+	return watermark, err
+}
+
+func (h *KpiRepository) WriteWatermark(path MetricStorageKey, watermark MetricWatermark) error {
+	var ctx = context.Background() // TODO: use context from gin
+
+	jsonData, err := json.Marshal(watermark)
+	if err != nil {
+		return err
+	}
+	return h.RedisClient.Set(ctx, watermarkRedisKey(path), jsonData, 0).Err()
+}
+
+// NewMetricStoreFromEnv builds the store selected by METRIC_STORE (redis, postgres or file).
+// The Postgres store reuses the given connection, the file store writes under METRIC_STORE_DIR.
+func NewMetricStoreFromEnv(db *gorm.DB) (MetricStore, error) {
+	switch strings.ToLower(os.Getenv("METRIC_STORE")) {
+	case "", "redis":
+		redisClient, err := GetRedisClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewKpiRepository(redisClient), nil
+	case "postgres":
+		if db == nil {
+			return nil, fmt.Errorf("postgres metric store requires a database connection")
+		}
+		return NewPostgresMetricStore(db)
+	case "file":
+		return NewFileMetricStore(os.Getenv("METRIC_STORE_DIR"))
+	default:
+		return nil, fmt.Errorf("unknown metric store: %s", os.Getenv("METRIC_STORE"))
 	}
-	return metricStoredFilePath
 }
 
 func LegacyGetMetricStorageKey(installationId string, metricName string) MetricStorageKey {
@@ -0,0 +1,156 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	metricFileExtension    = ".json"
+	watermarkFileExtension = ".watermark"
+)
+
+// FileMetricStore is the MetricStore for single-node installs, one JSON file per MetricStorageKey.
+type FileMetricStore struct {
+	RootDir string
+}
+
+// NewFileMetricStore stores metrics under rootDir, defaulting to ~/.datadrift/metrics.
+func NewFileMetricStore(rootDir string) (*FileMetricStore, error) {
+	if rootDir == "" {
+		currentUser, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		rootDir = filepath.Join(currentUser.HomeDir, ".datadrift", "metrics")
+	}
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileMetricStore{RootDir: rootDir}, nil
+}
+
+func (h *FileMetricStore) filePath(path MetricStorageKey, extension string) (string, error) {
+	filePath := filepath.Join(h.RootDir, filepath.FromSlash(string(path))+extension)
+	relPath, err := filepath.Rel(h.RootDir, filePath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid metric storage key: %s", path)
+	}
+	return filePath, nil
+}
+
+func (h *FileMetricStore) ReadMetricKPI(path MetricStorageKey) (Metrics, error) {
+	filePath, err := h.filePath(path, metricFileExtension)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Metrics
+	err = json.Unmarshal(jsonData, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (h *FileMetricStore) WriteMetricKPI(repoOwner string, repoName string, metricName string, lineCountAndKPIByDateByVersion Metrics) (MetricStorageKey, error) {
+	metricStoredFilePath := NewGetMetricStorageKey(repoOwner, repoName, metricName)
+	filePath, err := h.filePath(metricStoredFilePath, metricFileExtension)
+	if err != nil {
+		return "", err
+	}
+
+	jsonData, err := json.Marshal(lineCountAndKPIByDateByVersion)
+	if err != nil {
+		return "", fmt.Errorf("error occurred during marshaling: %v", err)
+	}
+	if err := writeFileAtomically(filePath, jsonData); err != nil {
+		return "", err
+	}
+	return metricStoredFilePath, nil
+}
+
+// writeFileAtomically writes to a temporary file first so that readers never see a partial file.
+func writeFileAtomically(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	tmpFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tmpFilePath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, filePath)
+}
+
+func (h *FileMetricStore) ListMetricKeys(prefix string) ([]MetricStorageKey, error) {
+	keys := []MetricStorageKey{}
+	err := filepath.WalkDir(h.RootDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), metricFileExtension) {
+			return nil
+		}
+		relPath, err := filepath.Rel(h.RootDir, path)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(relPath), metricFileExtension)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, MetricStorageKey(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (h *FileMetricStore) DeleteMetricKPI(path MetricStorageKey) error {
+	filePath, err := h.filePath(path, metricFileExtension)
+	if err != nil {
+		return err
+	}
+	watermarkFilePath, _ := h.filePath(path, watermarkFileExtension)
+	if err := os.Remove(watermarkFilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(filePath)
+}
+
+func (h *FileMetricStore) ReadWatermark(path MetricStorageKey) (MetricWatermark, error) {
+	filePath, err := h.filePath(path, watermarkFileExtension)
+	if err != nil {
+		return MetricWatermark{}, err
+	}
+	jsonData, err := os.ReadFile(filePath)
+	if err != nil {
+		return MetricWatermark{}, err
+	}
+
+	var watermark MetricWatermark
+	err = json.Unmarshal(jsonData, &watermark)
+	return watermark, err
+}
+
+func (h *FileMetricStore) WriteWatermark(path MetricStorageKey, watermark MetricWatermark) error {
+	filePath, err := h.filePath(path, watermarkFileExtension)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(watermark)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(filePath, jsonData)
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFileMetricStore(t *testing.T) {
+	store, err := NewFileMetricStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileMetricStore returned an error: %v", err)
+	}
+
+	metrics := Metrics{
+		"2023-05": {
+			TimeGrain:      Month,
+			Period:         "2023-05",
+			Dimension:      "none",
+			DimensionValue: NoDimensionValue,
+			History: MetricHistory{
+				"1f38fc06b5af2e642e12855619dd5347e5feb07e": {Lines: 2, KPI: decimal.NewFromFloat(110891.3), CommitTimestamp: 1681911827},
+			},
+		},
+	}
+
+	key, err := store.WriteMetricKPI("data-drift", "examples", "revenue/mrr", metrics)
+	if err != nil {
+		t.Fatalf("WriteMetricKPI returned an error: %v", err)
+	}
+	if key != "data-drift/examples/revenue%2Fmrr" {
+		t.Errorf("Expected key data-drift/examples/revenue%%2Fmrr, but got %s", key)
+	}
+
+	result, err := store.ReadMetricKPI(key)
+	if err != nil {
+		t.Fatalf("ReadMetricKPI returned an error: %v", err)
+	}
+	if !result["2023-05"].History["1f38fc06b5af2e642e12855619dd5347e5feb07e"].KPI.Equal(decimal.NewFromFloat(110891.3)) {
+		t.Errorf("Expected KPI 110891.3, but got %s", result["2023-05"].History["1f38fc06b5af2e642e12855619dd5347e5feb07e"].KPI)
+	}
+
+	keys, err := store.ListMetricKeys("data-drift/examples/")
+	if err != nil {
+		t.Fatalf("ListMetricKeys returned an error: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != key {
+		t.Errorf("Expected keys [%s], but got %v", key, keys)
+	}
+
+	if _, err := store.ReadMetricKPI("../outside"); err == nil {
+		t.Errorf("Expected an error when reading a key outside of the store")
+	}
+
+	if err := store.DeleteMetricKPI(key); err != nil {
+		t.Fatalf("DeleteMetricKPI returned an error: %v", err)
+	}
+	if _, err := store.ReadMetricKPI(key); err == nil {
+		t.Errorf("Expected an error when reading a deleted metric")
+	}
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StoredMetric is the row holding the serialized Metrics of one MetricStorageKey.
+type StoredMetric struct {
+	Key  string `gorm:"primaryKey"`
+	Data []byte `gorm:"type:jsonb;not null"`
+}
+
+// StoredMetricWatermark is the row holding the MetricWatermark of one MetricStorageKey.
+type StoredMetricWatermark struct {
+	Key             string `gorm:"primaryKey"`
+	CommitSha       string
+	CommitTimestamp int64
+	ConfigHash      string
+}
+
+// PostgresMetricStore is the MetricStore backed by the application database.
+type PostgresMetricStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresMetricStore(db *gorm.DB) (*PostgresMetricStore, error) {
+	if err := db.AutoMigrate(&StoredMetric{}, &StoredMetricWatermark{}); err != nil {
+		return nil, err
+	}
+	return &PostgresMetricStore{DB: db}, nil
+}
+
+func (h *PostgresMetricStore) ReadMetricKPI(path MetricStorageKey) (Metrics, error) {
+	var storedMetric StoredMetric
+	result := h.DB.Where("key = ?", string(path)).First(&storedMetric)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var data Metrics
+	err := json.Unmarshal(storedMetric.Data, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (h *PostgresMetricStore) WriteMetricKPI(repoOwner string, repoName string, metricName string, lineCountAndKPIByDateByVersion Metrics) (MetricStorageKey, error) {
+	metricStoredFilePath := NewGetMetricStorageKey(repoOwner, repoName, metricName)
+
+	jsonData, err := json.Marshal(lineCountAndKPIByDateByVersion)
+	if err != nil {
+		return "", fmt.Errorf("error occurred during marshaling: %v", err)
+	}
+
+	result := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data"}),
+	}).Create(&StoredMetric{Key: string(metricStoredFilePath), Data: jsonData})
+	if result.Error != nil {
+		return "", fmt.Errorf("could not store metric: %v", result.Error)
+	}
+	return metricStoredFilePath, nil
+}
+
+func (h *PostgresMetricStore) ListMetricKeys(prefix string) ([]MetricStorageKey, error) {
+	var storedKeys []string
+	result := h.DB.Model(&StoredMetric{}).Where(`key LIKE ? ESCAPE '\'`, escapeLikePattern(prefix)+"%").Order("key").Pluck("key", &storedKeys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	keys := make([]MetricStorageKey, 0, len(storedKeys))
+	for _, key := range storedKeys {
+		keys = append(keys, MetricStorageKey(key))
+	}
+	return keys, nil
+}
+
+func (h *PostgresMetricStore) DeleteMetricKPI(path MetricStorageKey) error {
+	if err := h.DB.Where("key = ?", string(path)).Delete(&StoredMetricWatermark{}).Error; err != nil {
+		return err
+	}
+	return h.DB.Where("key = ?", string(path)).Delete(&StoredMetric{}).Error
+}
+
+func (h *PostgresMetricStore) ReadWatermark(path MetricStorageKey) (MetricWatermark, error) {
+	var storedWatermark StoredMetricWatermark
+	result := h.DB.Where("key = ?", string(path)).First(&storedWatermark)
+	if result.Error != nil {
+		return MetricWatermark{}, result.Error
+	}
+	return MetricWatermark{
+		CommitSha:       CommitSha(storedWatermark.CommitSha),
+		CommitTimestamp: storedWatermark.CommitTimestamp,
+		ConfigHash:      storedWatermark.ConfigHash,
+	}, nil
+}
+
+func (h *PostgresMetricStore) WriteWatermark(path MetricStorageKey, watermark MetricWatermark) error {
+	return h.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&StoredMetricWatermark{
+		Key:             string(path),
+		CommitSha:       string(watermark.CommitSha),
+		CommitTimestamp: watermark.CommitTimestamp,
+		ConfigHash:      watermark.ConfigHash,
+	}).Error
+}
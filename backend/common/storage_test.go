@@ -0,0 +1,12 @@
+package common
+
+import "testing"
+
+func TestEscapePatterns(t *testing.T) {
+	if pattern := escapeLikePattern(`acme/my_repo/100%\x`); pattern != `acme/my\_repo/100\%\\x` {
+		t.Errorf("Unexpected LIKE pattern %s", pattern)
+	}
+	if pattern := escapeGlobPattern(`acme/repo?/[a]*\x`); pattern != `acme/repo\?/\[a\]\*\\x` {
+		t.Errorf("Unexpected match pattern %s", pattern)
+	}
+}
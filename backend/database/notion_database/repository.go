@@ -23,6 +23,9 @@ const PROPERTY_DATADRIFT_PERIOD = "datadrift-period"
 const PROPERTY_DATADRIFT_DRIFT_VALUE = "datadrift-drift-value"
 const PROPERTY_DATADRIFT_DIMENSION = "datadrift-dimension"
 
+// notionMaxTextLength is the maximum number of characters of a rich text.
+const notionMaxTextLength = 2000
+
 var DefaultPropertiesToDelete = []string{"Tags", "Status", "Étiquette", "Étiquettes"}
 
 func FindOrCreateReportPageId(apiKey string, databaseId string, reportName string, period string, timeGrain common.TimeGrain, dimensionValue common.DimensionValue) (string, bool, error) {
@@ -325,6 +328,7 @@ func AssertDatabaseHasDatadriftProperties(databaseID, apiKey string) error {
 				Type: notion.DBPropTypeSelect,
 				Select: &notion.SelectMetadata{
 					Options: []notion.SelectOptions{
+						{Name: string(common.Hour), Color: notion.ColorBlue},
 						{Name: string(common.Day), Color: notion.ColorYellow},
 						{Name: string(common.Month), Color: notion.ColorOrange},
 						{Name: string(common.Week), Color: notion.ColorRed},
@@ -538,6 +542,32 @@ func updatePageProperties(ctx context.Context, KPIInfo common.KPIReport, client
 	}
 }
 
+func getEventBlocks(event common.EventObject) []notion.Block {
+	blocks := []notion.Block{
+		notion.ParagraphBlock{
+			RichText: []notion.RichText{
+				{
+					Text: &notion.Text{
+						Content: displayCommitComments(event),
+					},
+				},
+			},
+		},
+	}
+	if event.Attribution != nil {
+		blocks = append(blocks, notion.ParagraphBlock{
+			RichText: []notion.RichText{
+				{
+					Text: &notion.Text{
+						Content: displayDriftAttribution(event),
+					},
+				},
+			},
+		})
+	}
+	return blocks
+}
+
 func createEventInNotionReport(event common.EventObject, client *notion.Client, ctx context.Context, changeLogDatabaseId string) error {
 	eventEmoji := getEventEmoji(event.Diff)
 	log.Println("Adding changeLog to report", event.CommitTimestamp, eventEmoji)
@@ -548,17 +578,7 @@ func createEventInNotionReport(event common.EventObject, client *notion.Client,
 			Type:  notion.IconTypeEmoji,
 			Emoji: &eventEmoji,
 		},
-		Children: []notion.Block{
-			notion.ParagraphBlock{
-				RichText: []notion.RichText{
-					{
-						Text: &notion.Text{
-							Content: displayCommitComments(event),
-						},
-					},
-				},
-			},
-		},
+		Children: getEventBlocks(event),
 		DatabasePageProperties: &notion.DatabasePageProperties{
 			"Name": notion.DatabasePageProperty{
 				Title: []notion.RichText{
@@ -796,10 +816,33 @@ func displayCommitComments(event common.EventObject) string {
 		result += "\n"
 	}
 
-	if len(result) > 2000 {
-		result = result[:2000]
+	return truncateText(result)
+}
+
+// truncateText cuts a text to the length Notion accepts in a rich text, without splitting a character.
+func truncateText(text string) string {
+	runes := []rune(text)
+	if len(runes) > notionMaxTextLength {
+		return string(runes[:notionMaxTextLength])
+	}
+	return text
+}
+
+func displayDriftAttribution(event common.EventObject) string {
+	if event.Attribution == nil {
+		return ""
 	}
-	return result
+	attribution := event.Attribution
+
+	result := fmt.Sprintf("Rows: %d added, %d removed, %d modified\n", attribution.AddedRows, attribution.RemovedRows, attribution.ModifiedRows)
+	if len(attribution.TopContributors) > 0 {
+		result += "Top contributors:\n"
+	}
+	for _, contributor := range attribution.TopContributors {
+		result += fmt.Sprintf("- %s (%s): %s, %.0f%% of the drift\n", contributor.RowKey, contributor.ChangeType, displayDiff(contributor.Contribution), contributor.Share*100)
+	}
+
+	return truncateText(result)
 }
 
 func getEventEmoji(diff float64) string {
@@ -1,7 +1,9 @@
 package notion_database
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/data-drift/data-drift/common"
 	"github.com/go-playground/assert/v2"
@@ -30,3 +32,33 @@ func TestDisplayEventTitle(t *testing.T) {
 	}
 	assert.Equal(t, "New Drift -1.75", displayEventTitle(deleteEvent))
 }
+
+func TestDisplayDriftAttribution(t *testing.T) {
+	assert.Equal(t, 1, len(getEventBlocks(common.EventObject{})))
+
+	event := common.EventObject{
+		EventType: common.EventTypeUpdate,
+		Attribution: &common.DriftAttribution{
+			Delta:        decimal.NewFromInt(40),
+			AddedRows:    1,
+			ModifiedRows: 1,
+			TopContributors: []common.RowContribution{
+				{RowKey: "b", ChangeType: common.RowModified, Contribution: decimal.NewFromInt(30), Share: 0.75},
+				{RowKey: "d", ChangeType: common.RowAdded, Contribution: decimal.NewFromInt(10), Share: 0.25},
+			},
+		},
+	}
+	expected := "Rows: 1 added, 0 removed, 1 modified\nTop contributors:\n- b (modified): +30, 75% of the drift\n- d (added): +10, 25% of the drift\n"
+	assert.Equal(t, expected, displayDriftAttribution(event))
+	assert.Equal(t, 2, len(getEventBlocks(event)))
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short"))
+
+	text := strings.Repeat("a", notionMaxTextLength-1) + "éé"
+	truncated := truncateText(text)
+	assert.Equal(t, notionMaxTextLength, utf8.RuneCountInString(truncated))
+	assert.Equal(t, true, utf8.ValidString(truncated))
+	assert.Equal(t, true, strings.HasSuffix(truncated, "é"))
+}
@@ -30,7 +30,10 @@ func DebugFunction() {
 	filepath := common.MetricStorageKey(os.Getenv("DEFAULT_FILE_PATH"))
 	githubApplicationId, _ := strconv.ParseInt(githubApplicationIdStr, 10, 64)
 
-	redisClient, _ := common.GetRedisClient()
+	kpiRepository, err := common.NewMetricStoreFromEnv(nil)
+	if err != nil {
+		panic("failed to create metric store: " + err.Error())
+	}
 
 	_ = notion_database.AssertDatabaseHasDatadriftProperties(notionDatabaseID, notionAPIKey)
 
@@ -47,12 +50,14 @@ func DebugFunction() {
 		if client == nil {
 			panic("Client not configured")
 		}
-		newFilepath, err := history.ProcessHistory(client, redisClient, githubRepoOwner, githubRepoName, metricConfig, int(githubApplicationId))
+		processedKPIs, err := history.ProcessHistory(client, kpiRepository, githubRepoOwner, githubRepoName, metricConfig, int(githubApplicationId))
 
 		if err != nil {
 			println(err)
 		}
-		filepath = newFilepath
+		if len(processedKPIs) > 0 {
+			filepath = processedKPIs[0].StorageKey
+		}
 	}
 	notionSyncConfig := common.SyncConfig{NotionAPIKey: notionAPIKey, NotionDatabaseID: notionDatabaseID}
 
@@ -67,7 +72,7 @@ func DebugFunction() {
 	// 	panic("Stop execution here")
 	// }
 
-	chartResults := reducers.ProcessMetricHistory(filepath, redisClient, common.MetricConfig{MetricName: "Default metric name"}, githubRepoOwner, githubRepoName)
+	chartResults := reducers.ProcessMetricHistory(filepath, kpiRepository, common.MetricConfig{MetricName: "Default metric name"}, githubRepoOwner, githubRepoName)
 
 	for _, chartResult := range chartResults {
 		err := reports.CreateReport(notionSyncConfig, chartResult)
@@ -76,11 +81,11 @@ func DebugFunction() {
 		}
 	}
 
-	metadataChartResults, metadataChartError := reducers.ProcessMetricMetadataCharts(filepath, metricConfig, redisClient)
+	metadataChartResults, metadataChartError := reducers.ProcessMetricMetadataCharts(filepath, metricConfig, kpiRepository, githubRepoOwner, githubRepoName)
 	if metadataChartError != nil {
 		fmt.Println("[DATADRIFT_ERROR] create summary report", metadataChartError.Error())
 	} else {
-		reports.CreateSummaryReport(notionSyncConfig, metricConfig, metadataChartResults, fmt.Sprint(githubApplicationId))
+		reports.CreateSummaryReport(notionSyncConfig, metricConfig, metadataChartResults)
 	}
 	println(filepath)
 }
@@ -0,0 +1,211 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type Operator string
+
+const (
+	Eq        Operator = "eq"
+	Neq       Operator = "neq"
+	Gt        Operator = "gt"
+	Gte       Operator = "gte"
+	Lt        Operator = "lt"
+	Lte       Operator = "lte"
+	In        Operator = "in"
+	NotIn     Operator = "not_in"
+	IsNull    Operator = "is_null"
+	IsNotNull Operator = "is_not_null"
+)
+
+// Filter is a condition on the rows of a snapshot: either a combination of filters with and/or, or a column comparison.
+// As in SQL, an empty cell is null and only matches is_null.
+type Filter struct {
+	And      []Filter `json:"and,omitempty"`
+	Or       []Filter `json:"or,omitempty"`
+	Column   string   `json:"column,omitempty"`
+	Operator Operator `json:"operator,omitempty"`
+	Value    *Value   `json:"value,omitempty"`
+	Values   []Value  `json:"values,omitempty"`
+}
+
+// Value is a value compared to a cell, written as a JSON string, number or boolean.
+type Value string
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch typedValue := value.(type) {
+	case string:
+		*v = Value(typedValue)
+	case float64, bool:
+		*v = Value(strings.TrimSpace(string(data)))
+	default:
+		return fmt.Errorf("filter value %s is neither a string, a number nor a boolean", string(data))
+	}
+	return nil
+}
+
+// Matcher tells whether a row matches a filter bound to the headers of a snapshot.
+type Matcher func(row []string) bool
+
+func matchAll(row []string) bool {
+	return true
+}
+
+func (f Filter) Validate() error {
+	kinds := 0
+	if len(f.And) > 0 {
+		kinds++
+	}
+	if len(f.Or) > 0 {
+		kinds++
+	}
+	if f.Column != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		return fmt.Errorf("a filter needs exactly one of and, or, column")
+	}
+	for _, subFilters := range [][]Filter{f.And, f.Or} {
+		for _, subFilter := range subFilters {
+			if err := subFilter.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if f.Column == "" {
+		return nil
+	}
+
+	switch f.Operator {
+	case Eq, Neq, Gt, Gte, Lt, Lte:
+		if f.Value == nil {
+			return fmt.Errorf("operator %s on %s requires a value", f.Operator, f.Column)
+		}
+	case In, NotIn:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("operator %s on %s requires values", f.Operator, f.Column)
+		}
+	case IsNull, IsNotNull:
+	default:
+		return fmt.Errorf("unknown operator %q on %s", f.Operator, f.Column)
+	}
+	return nil
+}
+
+// Compile binds a filter to the headers of a snapshot, a nil filter matching every row.
+func Compile(filter *Filter, headers []string) (Matcher, error) {
+	if filter == nil {
+		return matchAll, nil
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter.compile(headers)
+}
+
+func (f Filter) compile(headers []string) (Matcher, error) {
+	if len(f.And) > 0 || len(f.Or) > 0 {
+		subFilters := f.And
+		isAnd := len(f.And) > 0
+		if !isAnd {
+			subFilters = f.Or
+		}
+		matchers := make([]Matcher, len(subFilters))
+		for i, subFilter := range subFilters {
+			matcher, err := subFilter.compile(headers)
+			if err != nil {
+				return nil, err
+			}
+			matchers[i] = matcher
+		}
+		return func(row []string) bool {
+			for _, matcher := range matchers {
+				if matcher(row) != isAnd {
+					return !isAnd
+				}
+			}
+			return isAnd
+		}, nil
+	}
+
+	columnIndex := -1
+	for i, header := range headers {
+		if header == f.Column {
+			columnIndex = i
+		}
+	}
+	if columnIndex == -1 {
+		return nil, fmt.Errorf("filter column %s not found", f.Column)
+	}
+
+	compareCell := f.compileComparison()
+	return func(row []string) bool {
+		cell := ""
+		if columnIndex < len(row) {
+			cell = strings.TrimSpace(row[columnIndex])
+		}
+		if cell == "" {
+			return f.Operator == IsNull
+		}
+		return compareCell(cell)
+	}, nil
+}
+
+// compileComparison returns the comparison of a non null cell.
+func (f Filter) compileComparison() func(cell string) bool {
+	switch f.Operator {
+	case IsNull:
+		return func(cell string) bool { return false }
+	case IsNotNull:
+		return func(cell string) bool { return true }
+	case In, NotIn:
+		isIn := f.Operator == In
+		return func(cell string) bool {
+			for _, value := range f.Values {
+				if compare(cell, string(value)) == 0 {
+					return isIn
+				}
+			}
+			return !isIn
+		}
+	}
+
+	value := string(*f.Value)
+	operator := f.Operator
+	return func(cell string) bool {
+		comparison := compare(cell, value)
+		switch operator {
+		case Eq:
+			return comparison == 0
+		case Neq:
+			return comparison != 0
+		case Gt:
+			return comparison > 0
+		case Gte:
+			return comparison >= 0
+		case Lt:
+			return comparison < 0
+		default:
+			return comparison <= 0
+		}
+	}
+}
+
+// compare compares numbers numerically and other values as strings, which orders ISO dates.
+func compare(cell string, value string) int {
+	cellNumber, cellErr := decimal.NewFromString(cell)
+	valueNumber, valueErr := decimal.NewFromString(value)
+	if cellErr == nil && valueErr == nil {
+		return cellNumber.Cmp(valueNumber)
+	}
+	return strings.Compare(cell, value)
+}
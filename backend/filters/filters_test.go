@@ -0,0 +1,108 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var testHeaders = []string{"unique_key", "country", "status", "amount", "date"}
+
+func compileJSON(t *testing.T, filterJSON string) Matcher {
+	var filter Filter
+	if err := json.Unmarshal([]byte(filterJSON), &filter); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	matcher, err := Compile(&filter, testHeaders)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	return matcher
+}
+
+func TestCompile(t *testing.T) {
+	rows := map[string][]string{
+		"fr-paid":     {"1", "FR", "paid", "100", "2023-05-01"},
+		"fr-refunded": {"2", "FR", "refunded", "50", "2023-05-02"},
+		"fr-null":     {"3", "FR", "", "20", "2023-06-01"},
+		"us-paid":     {"4", "US", "paid", "9.5", "2023-06-02"},
+	}
+	testCases := []struct {
+		filter string
+		want   []string
+	}{
+		{`{"and": [{"column": "country", "operator": "eq", "value": "FR"}, {"column": "status", "operator": "neq", "value": "refunded"}]}`, []string{"fr-paid"}},
+		{`{"or": [{"column": "country", "operator": "eq", "value": "US"}, {"column": "status", "operator": "is_null"}]}`, []string{"fr-null", "us-paid"}},
+		{`{"column": "amount", "operator": "gte", "value": 50}`, []string{"fr-paid", "fr-refunded"}},
+		{`{"column": "amount", "operator": "lt", "value": 20}`, []string{"us-paid"}},
+		{`{"column": "date", "operator": "gt", "value": "2023-05-31"}`, []string{"fr-null", "us-paid"}},
+		{`{"column": "status", "operator": "in", "values": ["paid", "pending"]}`, []string{"fr-paid", "us-paid"}},
+		{`{"column": "status", "operator": "not_in", "values": ["paid"]}`, []string{"fr-refunded"}},
+		{`{"column": "status", "operator": "is_not_null"}`, []string{"fr-paid", "fr-refunded", "us-paid"}},
+		{`{"column": "amount", "operator": "eq", "value": 100.0}`, []string{"fr-paid"}},
+	}
+
+	for _, tc := range testCases {
+		matcher := compileJSON(t, tc.filter)
+		expected := make(map[string]bool)
+		for _, name := range tc.want {
+			expected[name] = true
+		}
+		for name, row := range rows {
+			if matcher(row) != expected[name] {
+				t.Errorf("%s: expected %s to match %v", tc.filter, name, expected[name])
+			}
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	value := Value("FR")
+	invalidFilters := []Filter{
+		{},
+		{Column: "country", Operator: "like", Value: &value},
+		{Column: "country", Operator: Eq},
+		{Column: "country", Operator: In},
+		{Column: "missing", Operator: Eq, Value: &value},
+		{Column: "country", Operator: Eq, Value: &value, And: []Filter{{Column: "status", Operator: IsNull}}},
+		{And: []Filter{{Column: "status"}}},
+	}
+	for _, filter := range invalidFilters {
+		if _, err := Compile(&filter, testHeaders); err == nil {
+			t.Errorf("Expected an error for %+v", filter)
+		}
+	}
+
+	matcher, err := Compile(nil, testHeaders)
+	if err != nil || !matcher([]string{}) {
+		t.Error("Expected a nil filter to match every row")
+	}
+}
+
+func TestJsonSchemaValidatesFilters(t *testing.T) {
+	schemaLoader := gojsonschema.NewReferenceLoader("file://../json-schema.json")
+	config := `{"notionAPIToken": "token", "notionDatabaseId": "id", "metrics": [{"metricName": "revenue", "filepath": "revenue.csv", "dateColumnName": "date", "KPIColumnName": "amount", "filter": %s}]}`
+	testCases := []struct {
+		filter string
+		valid  bool
+	}{
+		{`{"and": [{"column": "country", "operator": "eq", "value": "FR"}, {"or": [{"column": "status", "operator": "in", "values": ["paid", 1]}, {"column": "status", "operator": "is_null"}]}]}`, true},
+		{`{"column": "amount", "operator": "gt", "value": 10}`, true},
+		{`{"column": "amount", "operator": "gt"}`, false},
+		{`{"column": "status", "operator": "in", "values": []}`, false},
+		{`{"column": "status", "operator": "like", "value": "paid"}`, false},
+		{`{"and": [{"column": "status", "operator": "is_null", "value": "paid"}]}`, false},
+	}
+
+	for _, tc := range testCases {
+		result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewStringLoader(fmt.Sprintf(config, tc.filter)))
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		if result.Valid() != tc.valid {
+			t.Errorf("%s: expected valid to be %v, got errors %v", tc.filter, tc.valid, result.Errors())
+		}
+	}
+}
@@ -6,14 +6,36 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/history"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/v56/github"
 )
 
+// Maximum number of rows of each kind of change returned in a structured diff
+const maxDiffRows = 10000
+
+// duplicateKeysHeader gives the number of duplicate unique keys in the CSV file of a patch.
+const duplicateKeysHeader = "X-Duplicate-Keys"
+
+// getDuplicateKeyReport counts the unique keys shared by several rows of the records and describes them, "" when there are none.
+func getDuplicateKeyReport(records [][]string, keyColumns []string) (int, string) {
+	duplicateKeys := helpers.FindDuplicateKeys(records, keyColumns)
+	for i := range duplicateKeys {
+		duplicateKeys[i].Snapshot = "current"
+	}
+	diff := helpers.CsvDiff{KeyColumns: keyColumns, DuplicateKeys: duplicateKeys}
+	if err := diff.DuplicateKeyError(); err != nil {
+		return len(duplicateKeys), err.Error()
+	}
+	return 0, ""
+}
+
+// GetCommitDiff returns the patch of the CSV file of a commit, or the structured diff with ?format=structured.
 func GetCommitDiff(c *gin.Context) {
 	clientValue, exists := c.Get("github_client")
 	if !exists {
@@ -82,19 +104,44 @@ func GetCommitDiff(c *gin.Context) {
 
 	firstRecord := records[0]
 
+	if c.Query("format") == "structured" {
+		previousRecords := [][]string{{"No file"}}
+		if len(commit.Parents) > 0 {
+			previousRecords, err = getPreviousRecords(commit.Parents[0].GetSHA(), client, c, owner, repo, csvFile)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error getting previous version of the file"})
+				return
+			}
+		}
+		diff := helpers.ComputeCsvDiff(records, previousRecords, getUniqueKeyOfFile(c, client, owner, repo, csvFile.GetFilename())...)
+		diff.Truncate(maxDiffRows)
+		dataQualityError := ""
+		if err := diff.DuplicateKeyError(); err != nil {
+			dataQualityError = err.Error()
+		}
+		c.JSON(http.StatusOK, gin.H{"diff": diff, "dataQualityError": dataQualityError, "headers": firstRecord, "filename": csvFile.GetFilename(), "date": commit.GetCommit().GetCommitter().GetDate(), "commitLink": commit.GetHTMLURL()})
+		return
+	}
+
 	patch := csvFile.GetPatch()
 	patchToLarge := false
+	uniqueKey := getUniqueKeyOfFile(c, client, owner, repo, csvFile.GetFilename())
 
 	if patch == "" {
 		patchToLarge = true
-		patch, err = getPatchIfEmpty(client, owner, repo, commit.Parents[0].GetSHA(), csvFile, records)
+		patch, err = getPatchIfEmpty(client, owner, repo, commit.Parents[0].GetSHA(), csvFile, records, uniqueKey)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error getting patch when patch is empty"})
 			return
 		}
 	}
 
-	jsonData, err := json.Marshal(gin.H{"patch": patch, "headers": firstRecord, "filename": csvFile.GetFilename(), "date": commit.GetCommit().GetCommitter().GetDate(), "commitLink": commit.GetHTMLURL(), "patchToLarge": patchToLarge})
+	duplicateKeyCount, dataQualityError := getDuplicateKeyReport(records, uniqueKey)
+	if duplicateKeyCount > 0 {
+		c.Header(duplicateKeysHeader, strconv.Itoa(duplicateKeyCount))
+	}
+
+	jsonData, err := json.Marshal(gin.H{"patch": patch, "headers": firstRecord, "filename": csvFile.GetFilename(), "date": commit.GetCommit().GetCommitter().GetDate(), "commitLink": commit.GetHTMLURL(), "patchToLarge": patchToLarge, "duplicateKeyCount": duplicateKeyCount, "dataQualityError": dataQualityError})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "error marshaling JSON"})
 		return
@@ -120,7 +167,7 @@ func CompareCommit(c *gin.Context) {
 	baseCommitSha := c.Param("base-commit-sha")
 	headCommitSha := c.Param("head-commit-sha")
 	table := c.Query("table")
-	jsonData, err := compareCommit(client, c, owner, repo, baseCommitSha, headCommitSha, table)
+	jsonData, err := compareCommit(client, c, owner, repo, baseCommitSha, headCommitSha, table, getUniqueKeyOfFile(c, client, owner, repo, table))
 	if err != nil {
 
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -170,48 +217,35 @@ func CompareCommitBetweenDates(c *gin.Context) {
 	var firstCommit, latestCommit string
 
 	optBefore := &github.CommitsListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-			Page:    1,
-		},
 		Until: inclusiveBeginDate,
 		Path:  table,
 	}
-	commitsBefore, _, err := client.Repositories.ListCommits(c, owner, repo, optBefore)
+	firstCommit, err = getLatestCommitSha(c, client, owner, repo, optBefore)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if len(commitsBefore) > 0 {
-		firstCommit = commitsBefore[0].GetSHA()
-
-	} else {
+	if firstCommit == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No commits before date for table " + table})
 		return
 	}
 
 	optAfter := &github.CommitsListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-			Page:    1,
-		},
 		Since: beginDate,
 		Until: inclusiveEndDate,
 		Path:  table,
 	}
-	commitsAfter, _, err := client.Repositories.ListCommits(c, owner, repo, optAfter)
+	latestCommit, err = getLatestCommitSha(c, client, owner, repo, optAfter)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if len(commitsAfter) > 0 {
-		latestCommit = commitsAfter[0].GetSHA()
-	} else {
+	if latestCommit == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No commits between dates for table " + table})
 		return
 	}
 
-	jsonData, err := compareCommit(client, c, owner, repo, firstCommit, latestCommit, table)
+	jsonData, err := compareCommit(client, c, owner, repo, firstCommit, latestCommit, table, getUniqueKeyOfFile(c, client, owner, repo, table))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -219,7 +253,21 @@ func CompareCommitBetweenDates(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json", jsonData)
 }
 
-func compareCommit(client *github.Client, c context.Context, owner string, repo string, baseCommitSha string, headCommitSha string, table string) ([]byte, error) {
+// getLatestCommitSha returns the most recent commit matching the options, or an empty sha if there is none.
+// GitHub lists commits from the newest, so the first page holds it whatever the length of the history.
+func getLatestCommitSha(ctx context.Context, client *github.Client, owner string, repo string, opts *github.CommitsListOptions) (string, error) {
+	opts.ListOptions = github.ListOptions{PerPage: 1, Page: 1}
+	commits, _, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", nil
+	}
+	return commits[0].GetSHA(), nil
+}
+
+func compareCommit(client *github.Client, c context.Context, owner string, repo string, baseCommitSha string, headCommitSha string, table string, uniqueKey []string) ([]byte, error) {
 
 	baseCommit, _, _ := client.Repositories.GetCommit(c, owner, repo, baseCommitSha, nil)
 	headCommit, _, _ := client.Repositories.GetCommit(c, owner, repo, headCommitSha, nil)
@@ -267,7 +315,7 @@ func compareCommit(client *github.Client, c context.Context, owner string, repo
 	firstRecord := records[0]
 
 	patchToLarge := true
-	patch, err := getPatchIfEmpty(client, owner, repo, baseCommitSha, csvFile, records)
+	patch, err := getPatchIfEmpty(client, owner, repo, baseCommitSha, csvFile, records, uniqueKey)
 	if err != nil {
 		return nil, fmt.Errorf("error getting patch when patch is empty: %v", err)
 	}
@@ -278,7 +326,25 @@ func compareCommit(client *github.Client, c context.Context, owner string, repo
 	return jsonData, nil
 }
 
-func getPatchIfEmpty(client *github.Client, owner string, repo string, parentCommitSha string, file *github.CommitFile, currentRecord [][]string) (string, error) {
+// getUniqueKeyOfFile returns the key given with ?uniqueKey=column1,column2, or the one declared by the metric reading the file.
+// It returns nil to use the default key.
+func getUniqueKeyOfFile(c *gin.Context, client *github.Client, owner string, repo string, filename string) []string {
+	if uniqueKey := c.Query("uniqueKey"); uniqueKey != "" {
+		return strings.Split(uniqueKey, ",")
+	}
+	config, err := VerifyConfigFile(client, owner, repo, c)
+	if err != nil {
+		return nil
+	}
+	for _, metric := range config.Metrics {
+		if metric.Filepath == filename && len(metric.UniqueKey) > 0 {
+			return metric.UniqueKey
+		}
+	}
+	return nil
+}
+
+func getPatchIfEmpty(client *github.Client, owner string, repo string, parentCommitSha string, file *github.CommitFile, currentRecord [][]string, uniqueKey []string) (string, error) {
 	ctx := context.Background()
 	previousRecords, err := getPreviousRecords(parentCommitSha, client, ctx, owner, repo, file)
 
@@ -286,7 +352,7 @@ func getPatchIfEmpty(client *github.Client, owner string, repo string, parentCom
 		fmt.Println("Error getting PreviousRecords:", err)
 		return "", err
 	}
-	patch, err := helpers.GenerateCsvPatch(currentRecord, previousRecords)
+	patch, err := helpers.GenerateCsvPatch(currentRecord, previousRecords, uniqueKey...)
 	lines := strings.Split(patch, "\n")
 	if len(lines) > 10000 {
 		lines = lines[:10000]
@@ -322,6 +388,14 @@ func getPreviousRecords(parentCommitSha string, client *github.Client, ctx conte
 	return previousRecords, nil
 }
 
+// nextPageHeader is set when the commit list is truncated.
+const nextPageHeader = "X-Next-Page"
+
+// maxCommitListPages caps the pages of 100 commits listed for a day.
+const maxCommitListPages = 5
+
+// GetCommitList lists the latest page of commits, or the commits of a ?date= day. When there are more commits,
+// the X-Next-Page header gives the ?page= from which to list the rest.
 func GetCommitList(c *gin.Context) {
 	clientValue, exists := c.Get("github_client")
 	if !exists {
@@ -337,24 +411,38 @@ func GetCommitList(c *gin.Context) {
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	opt := &github.CommitsListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
+	opt := &github.CommitsListOptions{}
+	if page := c.Query("page"); page != "" {
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil || pageNumber < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page " + page})
+			return
+		}
+		opt.Page = pageNumber
 	}
 
+	// the latest page of commits, or the commits of a day up to maxCommitListPages pages
+	maxPages := 1
 	date := c.Query("date")
 	if date != "" {
-		start, _ := time.Parse(time.RFC3339, date+"T00:00:00Z")
-		end, _ := time.Parse(time.RFC3339, date+"T23:59:59Z")
+		start, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date " + date + ", expected 2006-01-02"})
+			return
+		}
 		opt.Since = start
-		opt.Until = end
+		opt.Until = start.Add(24*time.Hour - time.Second)
+		maxPages = maxCommitListPages
 	}
 
-	commits, _, err := client.Repositories.ListCommits(c, owner, repo, opt)
+	commits, nextPage, err := history.ListCommitPages(c, client, owner, repo, opt, maxPages)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if nextPage != 0 {
+		c.Header(nextPageHeader, strconv.Itoa(nextPage))
+	}
 
 	jsonData, err := json.Marshal(commits)
 	if err != nil {
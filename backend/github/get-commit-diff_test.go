@@ -0,0 +1,22 @@
+package github
+
+import "testing"
+
+func TestGetDuplicateKeyReport(t *testing.T) {
+	records := [][]string{
+		{"id", "amount"},
+		{"1", "10"},
+		{"2", "20"},
+		{"1", "30"},
+	}
+
+	count, report := getDuplicateKeyReport(records, []string{"id"})
+	if count != 1 || report != `1 duplicate keys for [id], e.g. "1" has 2 rows in the current snapshot` {
+		t.Errorf("Expected 1 duplicate key, got %d %q", count, report)
+	}
+
+	count, report = getDuplicateKeyReport(records[:3], []string{"id"})
+	if count != 0 || report != "" {
+		t.Errorf("Expected no duplicate key, got %d %q", count, report)
+	}
+}
@@ -0,0 +1,258 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reports"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	JobStatusFailed  JobStatus = "failed"
+	JobStatusDone    JobStatus = "done"
+	JobStatusDead    JobStatus = "dead"
+)
+
+const (
+	defaultJobWorkers     = 1
+	defaultJobMaxAttempts = 5
+	jobPollInterval       = 5 * time.Second
+	jobRetryBaseDelay     = 30 * time.Second
+	jobRetryMaxDelay      = time.Hour
+	// A job running for longer than this is considered lost, e.g. after a restart, and is queued again.
+	jobTimeout = 2 * time.Hour
+	// Interval between two cleanups of the expired rejected deliveries.
+	cleanupInterval = time.Hour
+	// Keep in sync with the where of the unique index on WebhookJob.DedupKey.
+	pendingJobCondition = "status = 'queued' OR status = 'failed'"
+)
+
+// WebhookJob is a sync of a repository requested by a webhook.
+// Failed jobs are retried with an exponential backoff and end up dead after too many attempts.
+// A repository has at most one pending job, queued or failed, which is enforced by a unique partial index.
+type WebhookJob struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	DedupKey       string     `gorm:"index;uniqueIndex:idx_webhook_jobs_pending_dedup_key,where:status = 'queued' OR status = 'failed'" json:"-"`
+	InstallationID int64      `json:"installationId"`
+	Owner          string     `json:"owner"`
+	Repository     string     `json:"repository"`
+	Status         JobStatus  `gorm:"index;not null" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	LastError      string     `json:"lastError"`
+	RunAfter       time.Time  `gorm:"index" json:"runAfter"`
+	StartedAt      *time.Time `json:"startedAt"`
+	FinishedAt     *time.Time `json:"finishedAt"`
+}
+
+func getJobDedupKey(installationId int64, ownerName string, repoName string) string {
+	return fmt.Sprintf("%d/%s/%s", installationId, ownerName, repoName)
+}
+
+// EnqueueWebhookJob queues a sync of the repository. Pending jobs of the same repository collapse into one.
+func (h *GithubService) EnqueueWebhookJob(installationId int64, ownerName string, repoName string) (WebhookJob, error) {
+	job := WebhookJob{
+		DedupKey:       getJobDedupKey(installationId, ownerName, repoName),
+		InstallationID: installationId,
+		Owner:          ownerName,
+		Repository:     repoName,
+		Status:         JobStatusQueued,
+		RunAfter:       time.Now(),
+	}
+	err := h.DB.Clauses(getEnqueueConflictClause(job)).Create(&job).Error
+	return job, err
+}
+
+// getEnqueueConflictClause resets the pending job of the repository instead of inserting a second one.
+func getEnqueueConflictClause(job WebhookJob) clause.OnConflict {
+	return clause.OnConflict{
+		Columns:     []clause.Column{{Name: "dedup_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: pendingJobCondition}}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":     JobStatusQueued,
+			"attempts":   0,
+			"last_error": "",
+			"run_after":  job.RunAfter,
+			"updated_at": job.RunAfter,
+		}),
+	}
+}
+
+// ProcessWebhookJobs runs the job workers, it never returns.
+// The pool size and the number of attempts are read from WEBHOOK_JOB_WORKERS and WEBHOOK_JOB_MAX_ATTEMPTS.
+func (h *GithubService) ProcessWebhookJobs(kpiRepository common.MetricStore) {
+	workers := getPositiveIntFromEnv("WEBHOOK_JOB_WORKERS", defaultJobWorkers)
+	maxAttempts := getPositiveIntFromEnv("WEBHOOK_JOB_MAX_ATTEMPTS", defaultJobMaxAttempts)
+	log.Printf("Starting %d webhook job workers", workers)
+
+	go func() {
+		for {
+			h.deleteExpiredRejectedDeliveries()
+			time.Sleep(cleanupInterval)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				h.requeueLostJobs()
+				job, err := h.claimNextJob()
+				if err != nil {
+					if !errors.Is(err, gorm.ErrRecordNotFound) {
+						log.Println("[DATADRIFT_ERROR] claiming job", err.Error())
+					}
+					time.Sleep(jobPollInterval)
+					continue
+				}
+				h.runJob(job, kpiRepository, maxAttempts)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// claimNextJob marks the next due job as running. Jobs of a repository that is already syncing are left queued.
+func (h *GithubService) claimNextJob() (WebhookJob, error) {
+	var job WebhookJob
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		runningDedupKeys := tx.Model(&WebhookJob{}).Select("dedup_key").Where("status = ?", JobStatusRunning)
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND run_after <= ?", []JobStatus{JobStatusQueued, JobStatusFailed}, time.Now()).
+			Where("dedup_key NOT IN (?)", runningDedupKeys).
+			Order("run_after").
+			First(&job)
+		if result.Error != nil {
+			return result.Error
+		}
+		now := time.Now()
+		job.Status = JobStatusRunning
+		job.Attempts++
+		job.StartedAt = &now
+		job.FinishedAt = nil
+		return tx.Save(&job).Error
+	})
+	return job, err
+}
+
+func (h *GithubService) requeueLostJobs() {
+	result := h.DB.Model(&WebhookJob{}).
+		Where("status = ? AND started_at < ?", JobStatusRunning, time.Now().Add(-jobTimeout)).
+		Updates(map[string]interface{}{"status": JobStatusFailed, "last_error": "job timed out", "run_after": time.Now()})
+	if result.Error != nil {
+		log.Println("[DATADRIFT_ERROR] requeuing lost jobs", result.Error.Error())
+	}
+}
+
+func (h *GithubService) runJob(job WebhookJob, kpiRepository common.MetricStore, maxAttempts int) {
+	log.Println("Running job", job.ID, job.InstallationID, job.Owner, job.Repository)
+	err := runWebhookJob(job, kpiRepository, reports.GormSlackThreadStore{DB: h.DB})
+
+	completeJob(&job, err, maxAttempts, time.Now())
+	if saveErr := h.DB.Save(&job).Error; saveErr != nil {
+		log.Println("[DATADRIFT_ERROR] saving job", job.ID, saveErr.Error())
+	}
+}
+
+// completeJob records the result of a run, a failed job is retried later until it has used all its attempts.
+func completeJob(job *WebhookJob, err error, maxAttempts int, now time.Time) {
+	job.FinishedAt = &now
+	if err == nil {
+		job.Status = JobStatusDone
+		job.LastError = ""
+		return
+	}
+	log.Println("[DATADRIFT_ERROR] job", job.ID, err.Error())
+	job.LastError = err.Error()
+	if job.Attempts >= maxAttempts {
+		job.Status = JobStatusDead
+	} else {
+		job.Status = JobStatusFailed
+		job.RunAfter = now.Add(getRetryDelay(job.Attempts))
+	}
+}
+
+func runWebhookJob(job WebhookJob, kpiRepository common.MetricStore, slackThreads reports.SlackThreadStore) error {
+	client, err := CreateClientFromGithubApp(job.InstallationID)
+	if err != nil {
+		return err
+	}
+	config, err := VerifyConfigFile(client, job.Owner, job.Repository, context.Background())
+	if err != nil {
+		return err
+	}
+	return processWebhookInTheBackground(config, kpiRepository, slackThreads, int(job.InstallationID), client, job.Owner, job.Repository)
+}
+
+// getRetryDelay doubles the delay after each attempt, up to jobRetryMaxDelay.
+func getRetryDelay(attempts int) time.Duration {
+	delay := jobRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= jobRetryMaxDelay {
+			return jobRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+func getPositiveIntFromEnv(name string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// JobsHandler lists the latest jobs, optionally filtered with ?status=queued|running|failed|done|dead.
+func (h *GithubService) JobsHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	query := h.DB.Order("updated_at DESC").Limit(limit)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var jobs []WebhookJob
+	if err := query.Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var counts []struct {
+		Status JobStatus
+		Count  int64
+	}
+	if err := h.DB.Model(&WebhookJob{}).Select("status, count(*) as count").Group("status").Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	countByStatus := map[JobStatus]int64{JobStatusQueued: 0, JobStatusRunning: 0, JobStatusFailed: 0, JobStatusDone: 0, JobStatusDead: 0}
+	for _, count := range counts {
+		countByStatus[count.Status] = count.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "counts": countByStatus})
+}
@@ -0,0 +1,188 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{10, time.Hour},
+	}
+
+	for _, tc := range testCases {
+		got := getRetryDelay(tc.attempts)
+		if got != tc.want {
+			t.Errorf("getRetryDelay(%d) = %v; want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestCompleteJob(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+	job := WebhookJob{Status: JobStatusRunning, Attempts: 1, LastError: "previous error"}
+	completeJob(&job, nil, 3, now)
+	if job.Status != JobStatusDone || job.LastError != "" || job.FinishedAt == nil {
+		t.Errorf("Expected a done job, but got %+v", job)
+	}
+
+	job = WebhookJob{Status: JobStatusRunning, Attempts: 2}
+	completeJob(&job, errors.New("sync failed"), 3, now)
+	if job.Status != JobStatusFailed || job.LastError != "sync failed" || !job.RunAfter.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected a job retried in a minute, but got %+v", job)
+	}
+
+	job = WebhookJob{Status: JobStatusRunning, Attempts: 3}
+	completeJob(&job, errors.New("sync failed"), 3, now)
+	if job.Status != JobStatusDead || job.LastError != "sync failed" {
+		t.Errorf("Expected a dead job, but got %+v", job)
+	}
+}
+
+func TestEnqueueConflictClause(t *testing.T) {
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := WebhookJob{DedupKey: "1/owner/repo", Status: JobStatusQueued, RunAfter: time.Now()}
+
+	sql := db.Clauses(getEnqueueConflictClause(job)).Create(&job).Statement.SQL.String()
+
+	if !strings.Contains(sql, `ON CONFLICT ("dedup_key")`) || !strings.Contains(sql, "WHERE "+pendingJobCondition+" DO UPDATE SET") {
+		t.Errorf("Expected the insert to target the pending jobs index, but got %s", sql)
+	}
+}
+
+// newTestGithubService runs against the Postgres database of TEST_DATABASE_URL, the claims rely on SKIP LOCKED.
+func newTestGithubService(t *testing.T) *GithubService {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrator().DropTable(&WebhookJob{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&WebhookJob{}); err != nil {
+		t.Fatal(err)
+	}
+	return &GithubService{DB: db}
+}
+
+func TestEnqueueWebhookJobDedup(t *testing.T) {
+	h := newTestGithubService(t)
+
+	first, err := h.EnqueueWebhookJob(1, "owner", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.EnqueueWebhookJob(1, "owner", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != second.ID {
+		t.Errorf("Expected the pending job %d to be reused, but got %d", first.ID, second.ID)
+	}
+
+	duplicate := WebhookJob{DedupKey: first.DedupKey, Status: JobStatusFailed, RunAfter: time.Now()}
+	if err := h.DB.Create(&duplicate).Error; err == nil {
+		t.Errorf("Expected the unique index to reject a second pending job")
+	}
+}
+
+func TestClaimNextJob(t *testing.T) {
+	h := newTestGithubService(t)
+	now := time.Now()
+	jobs := []WebhookJob{
+		{DedupKey: "1/owner/running", Status: JobStatusRunning, StartedAt: &now, RunAfter: now.Add(-time.Hour)},
+		{DedupKey: "1/owner/running", Status: JobStatusQueued, RunAfter: now.Add(-time.Hour)},
+		{DedupKey: "1/owner/later", Status: JobStatusFailed, RunAfter: now.Add(time.Hour)},
+		{DedupKey: "1/owner/due", Status: JobStatusFailed, Attempts: 1, RunAfter: now.Add(-time.Minute)},
+	}
+	if err := h.DB.Create(&jobs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := h.claimNextJob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != jobs[3].ID || job.Status != JobStatusRunning || job.Attempts != 2 || job.StartedAt == nil {
+		t.Errorf("Expected the due job to be claimed, but got %+v", job)
+	}
+
+	if _, err := h.claimNextJob(); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected no other job to be claimable, but got %v", err)
+	}
+}
+
+func TestRetryAndMarkDead(t *testing.T) {
+	h := newTestGithubService(t)
+	if _, err := h.EnqueueWebhookJob(1, "owner", "repo"); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		job, err := h.claimNextJob()
+		if err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+		completeJob(&job, errors.New("sync failed"), 2, time.Now().Add(-time.Hour))
+		if err := h.DB.Save(&job).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var job WebhookJob
+	if err := h.DB.First(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobStatusDead || job.Attempts != 2 {
+		t.Errorf("Expected the job to be dead after 2 attempts, but got %+v", job)
+	}
+	if _, err := h.claimNextJob(); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected a dead job not to be claimed, but got %v", err)
+	}
+}
+
+func TestRequeueLostJobs(t *testing.T) {
+	h := newTestGithubService(t)
+	lostStart := time.Now().Add(-jobTimeout - time.Minute)
+	recentStart := time.Now()
+	jobs := []WebhookJob{
+		{DedupKey: "1/owner/lost", Status: JobStatusRunning, StartedAt: &lostStart},
+		{DedupKey: "1/owner/running", Status: JobStatusRunning, StartedAt: &recentStart},
+	}
+	if err := h.DB.Create(&jobs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	h.requeueLostJobs()
+
+	var lost, running WebhookJob
+	h.DB.First(&lost, jobs[0].ID)
+	h.DB.First(&running, jobs[1].ID)
+	if lost.Status != JobStatusFailed || lost.LastError != "job timed out" {
+		t.Errorf("Expected the lost job to be queued again, but got %+v", lost)
+	}
+	if running.Status != JobStatusRunning {
+		t.Errorf("Expected the running job to be left running, but got %+v", running)
+	}
+}
@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"strings"
 
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/history"
 	"github.com/data-drift/data-drift/urlgen"
 	"github.com/google/go-github/v56/github"
+	"github.com/shopspring/decimal"
 )
 
 func handlePullRequestOpened(event *github.PullRequestEvent) error {
@@ -20,11 +25,13 @@ func handlePullRequestOpened(event *github.PullRequestEvent) error {
 
 	commitDiffUrl := urlgen.BuildReportDiffUrl(urlgen.BuildReportDiffBaseUrl(owner, repo), *event.PullRequest.Head.SHA, url.Values{})
 	log.Printf("commitDiffUrl: %s", commitDiffUrl)
-	comment := &github.IssueComment{
-		Body: github.String(fmt.Sprintf("The diff is available [here](%s).", commitDiffUrl)),
-	}
 	client, _ := CreateClientFromGithubApp(*event.Installation.ID)
 	number := event.GetNumber()
+	body := fmt.Sprintf("The diff is available [here](%s).", commitDiffUrl)
+	body += buildDriftAttributionComment(client, owner, repo, number, event.PullRequest.GetBase().GetSHA(), event.PullRequest.GetHead().GetSHA())
+	comment := &github.IssueComment{
+		Body: github.String(body),
+	}
 
 	_, _, err := client.Issues.CreateComment(context.Background(), owner, repo, number, comment)
 
@@ -33,3 +40,84 @@ func handlePullRequestOpened(event *github.PullRequestEvent) error {
 	}
 	return err
 }
+
+const maxDriftsInComment = 10
+
+// buildDriftAttributionComment explains the drifts of the metrics whose file is changed by the pull request.
+func buildDriftAttributionComment(client *github.Client, owner string, repo string, number int, baseSha string, headSha string) string {
+	ctx := context.Background()
+	config, err := VerifyConfigFile(client, owner, repo, ctx)
+	if err != nil {
+		return ""
+	}
+	files, _, err := client.PullRequests.ListFiles(ctx, owner, repo, number, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		log.Println("[DATADRIFT_ERROR] listing pull request files", err.Error())
+		return ""
+	}
+	changedFiles := make(map[string]bool)
+	for _, file := range files {
+		changedFiles[file.GetFilename()] = true
+	}
+
+	result := ""
+	for _, metric := range config.Metrics {
+		if !changedFiles[metric.Filepath] {
+			continue
+		}
+		previousRecords, err := history.GetFileContentsForCommit(client, owner, repo, metric.Filepath, baseSha)
+		if err != nil {
+			previousRecords = [][]string{}
+		}
+		currentRecords, err := history.GetFileContentsForCommit(client, owner, repo, metric.Filepath, headSha)
+		if err != nil {
+			log.Println("[DATADRIFT_ERROR] getting file of pull request", err.Error())
+			continue
+		}
+		for _, kpiMetric := range metric.ExpandKPIs() {
+			drifts, err := history.ComputeSnapshotDrifts(previousRecords, currentRecords, kpiMetric)
+			if err != nil {
+				log.Println("[DATADRIFT_ERROR] computing drifts of pull request", err.Error())
+				continue
+			}
+			result += formatSnapshotDrifts(kpiMetric.MetricName, drifts)
+		}
+	}
+	return result
+}
+
+func formatSnapshotDrifts(metricName string, drifts []history.SnapshotDrift) string {
+	var sb strings.Builder
+	displayedDrifts := 0
+	for _, drift := range drifts {
+		if drift.Dimension != common.NoDimension {
+			continue
+		}
+		if displayedDrifts == maxDriftsInComment {
+			sb.WriteString(fmt.Sprintf("\n_Other periods of %s drifted as well._\n", metricName))
+			break
+		}
+		if displayedDrifts == 0 {
+			sb.WriteString(fmt.Sprintf("\n\n### %s\n", metricName))
+		}
+		displayedDrifts++
+
+		attribution := drift.Attribution
+		sb.WriteString(fmt.Sprintf("\n**%s**: %s (%d rows added, %d removed, %d modified)\n", drift.Period, formatSignedDecimal(attribution.Delta), attribution.AddedRows, attribution.RemovedRows, attribution.ModifiedRows))
+		if len(attribution.TopContributors) == 0 {
+			continue
+		}
+		sb.WriteString("\n| Row | Change | Contribution | Share |\n|---|---|---|---|\n")
+		for _, contributor := range attribution.TopContributors {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.0f%% |\n", strings.ReplaceAll(contributor.RowKey, "|", "\\|"), contributor.ChangeType, formatSignedDecimal(contributor.Contribution), contributor.Share*100))
+		}
+	}
+	return sb.String()
+}
+
+func formatSignedDecimal(value decimal.Decimal) string {
+	if value.IsPositive() {
+		return "+" + helpers.FormatWithSeparator(value)
+	}
+	return helpers.FormatWithSeparator(value)
+}
@@ -9,15 +9,14 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"time"
 
+	"github.com/data-drift/data-drift/alerts"
+	"github.com/data-drift/data-drift/calendar"
 	"github.com/data-drift/data-drift/common"
-	"github.com/data-drift/data-drift/database/notion_database"
 	"github.com/data-drift/data-drift/history"
 	"github.com/data-drift/data-drift/reducers"
 	"github.com/data-drift/data-drift/reports"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	"github.com/google/go-github/v56/github"
 	"github.com/xeipuuv/gojsonschema"
 	"gorm.io/gorm"
@@ -32,6 +31,7 @@ type GithubConnection struct {
 	InstallationID int64 `gorm:"uniqueIndex"`
 	AuthRequired   bool  `gorm:"not null;default:false"`
 	Password       string
+	WebhookSecret  string
 }
 
 type GithubService struct {
@@ -111,8 +111,12 @@ func (h *GithubService) GithubClientGuard(c *gin.Context) {
 }
 
 func (h *GithubService) HandleWebhook(c *gin.Context) {
-	payload, err := github.ValidatePayload(c.Request, []byte(""))
+	payload, err := verifyWebhookPayload(c, gormWebhookDeliveryStore{DB: h.DB})
 
+	if errors.Is(err, errWebhookRejected) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
 		return
@@ -149,10 +153,19 @@ func (h *GithubService) HandleWebhook(c *gin.Context) {
 			return
 		}
 
-		fmt.Println("config", config)
-		c.JSON(http.StatusOK, gin.H{"message": "Webhook processed", "configIsValie": config, "installationId": InstallationId})
+		// The commits of the static report would otherwise trigger a sync each
+		if config.StaticReport != nil && config.StaticReport.Branch != "" && event.GetRef() == "refs/heads/"+config.StaticReport.Branch {
+			c.JSON(http.StatusOK, gin.H{"message": "Webhook ignored, push of the static report branch"})
+			return
+		}
 
-		webhookChannel <- WebhookToProcess{config: config, InstallationId: int(InstallationId), client: client, ownerName: ownerName, repoName: repoName}
+		fmt.Println("config", config)
+		job, err := h.EnqueueWebhookJob(InstallationId, ownerName, repoName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Webhook processed", "configIsValie": config, "installationId": InstallationId, "jobId": job.ID})
 
 	case *github.InstallationEvent:
 		fmt.Println("Installation ID: ", event.Installation.ID)
@@ -185,9 +198,12 @@ func (h *GithubService) HandleWebhook(c *gin.Context) {
 		}
 
 		fmt.Println("config", config)
-		c.JSON(http.StatusOK, gin.H{"message": "Webhook processed", "configIsValie": config, "installationId": InstallationId})
-
-		webhookChannel <- WebhookToProcess{config: config, InstallationId: int(InstallationId), client: client, ownerName: ownerName, repoName: repoName}
+		job, err := h.EnqueueWebhookJob(InstallationId, ownerName, repoName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Webhook processed", "configIsValie": config, "installationId": InstallationId, "jobId": job.ID})
 		return
 
 	case *github.PullRequestEvent:
@@ -213,63 +229,64 @@ func (h *GithubService) HandleWebhook(c *gin.Context) {
 
 }
 
-// Define a type for the webhook data.
-type WebhookToProcess struct {
-	config         common.Config
-	InstallationId int
-	client         *github.Client
-	ownerName      string
-	repoName       string
-}
-
-var webhookChannel = make(chan WebhookToProcess, 100)
-
-func ProcessWebhooks(redisClient *redis.Client) {
-	log.Println("Starting to consume the channel")
-	for {
-		webhookData := <-webhookChannel
-
-		log.Println("Consuming the channel", webhookData.InstallationId, webhookData.client, webhookData.ownerName, webhookData.repoName)
-		processWebhookInTheBackground(webhookData.config, redisClient, webhookData.InstallationId, webhookData.client, webhookData.ownerName, webhookData.repoName)
-		time.Sleep(10 * time.Second)
-	}
-}
-
-func processWebhookInTheBackground(config common.Config, redisClient *redis.Client, InstallationId int, client *github.Client, ownerName string, repoName string) bool {
+func processWebhookInTheBackground(config common.Config, kpiRepository common.MetricStore, slackThreads reports.SlackThreadStore, InstallationId int, client *github.Client, ownerName string, repoName string) error {
+	var syncErrors []error
 
 	fmt.Println("starting sync")
 
-	err := notion_database.AssertDatabaseHasDatadriftProperties(config.NotionDatabaseID, config.NotionAPIToken)
+	reporters := reports.NewReporters(config, client, kpiRepository, ownerName, repoName, slackThreads)
+	for _, reporter := range reporters {
+		if err := reporter.Init(); err != nil {
+			log.Println("[DATADRIFT_ERROR] init reporter", err.Error())
+		}
+	}
 
+	notifiers, err := alerts.NewNotifiers(config.Notifiers)
 	if err != nil {
-		fmt.Println("[DATADRIFT_ERROR] db notion", err.Error())
+		log.Println("[DATADRIFT_ERROR] notifiers", err.Error())
 	}
 
 	for _, metric := range config.Metrics {
 
-		filepath, err := history.ProcessHistory(client, redisClient, ownerName, repoName, metric, InstallationId)
+		processedKPIs, err := history.ProcessHistory(client, kpiRepository, ownerName, repoName, metric, InstallationId)
 		if err != nil {
 			fmt.Println("[DATADRIFT_ERROR] process history", err.Error())
-
+			syncErrors = append(syncErrors, fmt.Errorf("metric %s: %v", metric.MetricName, err))
 		}
 
-		chartResults := reducers.ProcessMetricHistory(filepath, redisClient, metric, ownerName, repoName)
+		for _, processedKPI := range processedKPIs {
+			kpiMetric, filepath := processedKPI.Metric, processedKPI.StorageKey
+			chartResults := reducers.ProcessMetricHistory(filepath, kpiRepository, kpiMetric, ownerName, repoName)
 
-		for _, chartResult := range chartResults {
-			err = reports.CreateReport(common.SyncConfig{NotionAPIKey: config.NotionAPIToken, NotionDatabaseID: config.NotionDatabaseID}, chartResult)
+			metricAlerts, err := alerts.Evaluate(kpiMetric, chartResults, processedKPI.ResumedFrom)
 			if err != nil {
-				fmt.Println("[DATADRIFT_ERROR] create report", err.Error())
+				log.Println("[DATADRIFT_ERROR] evaluate alerts", err.Error())
+			}
+			if err := alerts.Send(metricAlerts, notifiers); err != nil {
+				log.Println("[DATADRIFT_ERROR] send alerts", err.Error())
 			}
-		}
 
-		metadataChartResults, metadataChartError := reducers.ProcessMetricMetadataCharts(filepath, metric, redisClient)
-		if metadataChartError != nil {
-			fmt.Println("[DATADRIFT_ERROR] create summary report", metadataChartError.Error())
-		} else {
-			reports.CreateSummaryReport(common.SyncConfig{NotionAPIKey: config.NotionAPIToken, NotionDatabaseID: config.NotionDatabaseID}, metric, metadataChartResults, fmt.Sprint(InstallationId))
+			for _, chartResult := range chartResults {
+				for _, reporter := range reporters {
+					if err := reporter.UpsertKPIReport(kpiMetric, chartResult, processedKPI.ResumedFrom); err != nil {
+						log.Println("[DATADRIFT_ERROR] create report", err.Error())
+					}
+				}
+			}
+
+			metadataChartResults, metadataChartError := reducers.ProcessMetricMetadataCharts(filepath, kpiMetric, kpiRepository, ownerName, repoName)
+			if metadataChartError != nil {
+				log.Println("[DATADRIFT_ERROR] create summary report", metadataChartError.Error())
+			} else {
+				for _, reporter := range reporters {
+					if err := reporter.UpsertSummaryReport(kpiMetric, metadataChartResults); err != nil {
+						log.Println("[DATADRIFT_ERROR] create summary report", err.Error())
+					}
+				}
+			}
 		}
 	}
-	return false
+	return errors.Join(syncErrors...)
 }
 
 func VerifyConfigFile(client *github.Client, RepoOwner string, RepoName string, ctx context.Context) (common.Config, error) {
@@ -314,6 +331,21 @@ func VerifyConfigFile(client *github.Client, RepoOwner string, RepoName string,
 		fmt.Println("[DATADRIFT_ERROR]", err.Error())
 		return common.Config{}, err
 	}
+	if err := config.ValidateMetricNames(); err != nil {
+		log.Println("[DATADRIFT_ERROR]", err.Error())
+		return common.Config{}, err
+	}
+	config = config.WithDefaults()
+	for _, metric := range config.Metrics {
+		if _, err := calendar.ForMetricConfig(metric); err != nil {
+			log.Println("[DATADRIFT_ERROR]", err.Error())
+			return common.Config{}, err
+		}
+	}
+	if err := config.ValidateAlerts(); err != nil {
+		log.Println("[DATADRIFT_ERROR]", err.Error())
+		return common.Config{}, err
+	}
 	return config, nil
 }
 
@@ -343,6 +375,12 @@ func GetConfigHandler(c *gin.Context) {
 	}
 	config.NotionAPIToken = ""
 	config.NotionDatabaseID = ""
+	// The notifier urls are secrets as well
+	for i := range config.Notifiers {
+		config.Notifiers[i].Url = ""
+	}
+	// Each KPI of a metric set is listed as a metric of its own.
+	config.Metrics = config.ExpandedMetrics()
 	c.JSON(http.StatusOK, gin.H{"config": config})
 }
 
@@ -0,0 +1,280 @@
+package github
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v56/github"
+	"gorm.io/gorm"
+)
+
+const (
+	signatureHeader    = "X-Hub-Signature-256"
+	maxWebhookBodySize = 25 << 20 // GitHub caps payloads at 25MB
+	// Past this many rejected deliveries in the last hour, the next ones are only logged.
+	maxRejectedDeliveriesPerHour = 1000
+	// Rejected deliveries older than this are deleted by the job workers.
+	rejectedDeliveryRetention = 30 * 24 * time.Hour
+)
+
+var errWebhookRejected = errors.New("webhook rejected")
+
+// RejectedWebhookDelivery records a webhook delivery that failed the signature verification.
+type RejectedWebhookDelivery struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `json:"createdAt"`
+	DeliveryID     string    `json:"deliveryId"`
+	Event          string    `json:"event"`
+	InstallationID int64     `json:"installationId"`
+	Repository     string    `json:"repository"`
+	RemoteAddr     string    `json:"remoteAddr"`
+	Reason         string    `json:"reason"`
+}
+
+type webhookSender struct {
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// parseSecrets splits a comma separated list of secrets, the first one being the current secret
+// and the following ones the secrets still accepted during a rotation.
+func parseSecrets(secrets string) []string {
+	var result []string
+	for _, secret := range strings.Split(secrets, ",") {
+		secret = strings.TrimSpace(secret)
+		if secret != "" {
+			result = append(result, secret)
+		}
+	}
+	return result
+}
+
+// webhookDeliveryStore gives the secrets of the connections and records the rejected deliveries.
+type webhookDeliveryStore interface {
+	// GetConnectionSecrets returns the secrets of the connection of the installation, none when it is unknown.
+	GetConnectionSecrets(installationId int64) ([]string, error)
+	// HasConnectionSecrets tells whether any connection has a secret.
+	HasConnectionSecrets() (bool, error)
+	// CountRejectedDeliveriesSince counts the deliveries rejected after a time.
+	CountRejectedDeliveriesSince(since time.Time) (int64, error)
+	RecordRejectedDelivery(delivery RejectedWebhookDelivery) error
+}
+
+type gormWebhookDeliveryStore struct {
+	DB *gorm.DB
+}
+
+func (store gormWebhookDeliveryStore) GetConnectionSecrets(installationId int64) ([]string, error) {
+	var githubConnection GithubConnection
+	result := store.DB.Where("installation_id = ?", installationId).First(&githubConnection)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return parseSecrets(githubConnection.WebhookSecret), nil
+}
+
+func (store gormWebhookDeliveryStore) HasConnectionSecrets() (bool, error) {
+	var count int64
+	err := store.DB.Model(&GithubConnection{}).Where("webhook_secret IS NOT NULL AND webhook_secret <> ''").Count(&count).Error
+	return count > 0, err
+}
+
+func (store gormWebhookDeliveryStore) CountRejectedDeliveriesSince(since time.Time) (int64, error) {
+	var count int64
+	err := store.DB.Model(&RejectedWebhookDelivery{}).Where("created_at > ?", since).Count(&count).Error
+	return count, err
+}
+
+func (store gormWebhookDeliveryStore) RecordRejectedDelivery(delivery RejectedWebhookDelivery) error {
+	return store.DB.Create(&delivery).Error
+}
+
+// recordRejectedDelivery records a rejected delivery unless maxRejectedDeliveriesPerHour are already recorded,
+// so that a flood of unauthenticated requests can not fill the table.
+func recordRejectedDelivery(store webhookDeliveryStore, delivery RejectedWebhookDelivery, now time.Time) {
+	count, err := store.CountRejectedDeliveriesSince(now.Add(-time.Hour))
+	if err != nil {
+		log.Println("[DATADRIFT_ERROR] counting rejected deliveries", err.Error())
+		return
+	}
+	if count >= maxRejectedDeliveriesPerHour {
+		log.Println("[DATADRIFT_WARNING] too many rejected deliveries, not recording delivery", delivery.DeliveryID, delivery.RemoteAddr, delivery.Reason)
+		return
+	}
+	if err := store.RecordRejectedDelivery(delivery); err != nil {
+		log.Println("[DATADRIFT_ERROR] recording rejected delivery", err.Error())
+	}
+}
+
+// deleteExpiredRejectedDeliveries deletes the rejected deliveries older than rejectedDeliveryRetention.
+func (h *GithubService) deleteExpiredRejectedDeliveries() {
+	result := h.DB.Where("created_at < ?", time.Now().Add(-rejectedDeliveryRetention)).Delete(&RejectedWebhookDelivery{})
+	if result.Error != nil {
+		log.Println("[DATADRIFT_ERROR] deleting expired rejected deliveries", result.Error.Error())
+	}
+}
+
+// getWebhookSecrets returns the secrets accepted for a delivery: the ones of GITHUB_WEBHOOK_SECRET
+// and the ones of the connection of the installation.
+func getWebhookSecrets(store webhookDeliveryStore, installationId int64) ([]string, error) {
+	secrets := parseSecrets(os.Getenv("GITHUB_WEBHOOK_SECRET"))
+	if installationId == 0 {
+		return secrets, nil
+	}
+	connectionSecrets, err := store.GetConnectionSecrets(installationId)
+	return append(secrets, connectionSecrets...), err
+}
+
+// acceptsUnsignedWebhooks tells whether a delivery can skip the verification: only when GITHUB_WEBHOOK_ALLOW_UNSIGNED
+// is true and no secret is configured anywhere, neither in GITHUB_WEBHOOK_SECRET nor on a connection.
+func acceptsUnsignedWebhooks(store webhookDeliveryStore) bool {
+	if os.Getenv("GITHUB_WEBHOOK_ALLOW_UNSIGNED") != "true" || len(parseSecrets(os.Getenv("GITHUB_WEBHOOK_SECRET"))) > 0 {
+		return false
+	}
+	hasSecrets, err := store.HasConnectionSecrets()
+	if err != nil {
+		log.Println("[DATADRIFT_ERROR] looking up the webhook secrets", err.Error())
+		return false
+	}
+	return !hasSecrets
+}
+
+// verifyWebhookPayload checks the X-Hub-Signature-256 of the delivery against every accepted secret
+// and returns the JSON payload. The installation of the payload is not trusted until the signature matches,
+// so a delivery without a secret to check it against is rejected unless unsigned deliveries are allowed.
+func verifyWebhookPayload(c *gin.Context, store webhookDeliveryStore) ([]byte, error) {
+	contentType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		return nil, err
+	}
+	unverifiedPayload, err := github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sender webhookSender
+	_ = json.Unmarshal(unverifiedPayload, &sender)
+
+	rejectionReason := "invalid signature"
+	signature := c.Request.Header.Get(signatureHeader)
+	secrets, err := getWebhookSecrets(store, sender.Installation.ID)
+	if err != nil {
+		log.Println("[DATADRIFT_ERROR] looking up the webhook secrets", err.Error())
+		rejectionReason = "webhook secrets unavailable"
+	} else if len(secrets) == 0 {
+		if acceptsUnsignedWebhooks(store) {
+			log.Println("[DATADRIFT_WARNING] GITHUB_WEBHOOK_ALLOW_UNSIGNED is set, accepting unsigned delivery")
+			return unverifiedPayload, nil
+		}
+		rejectionReason = "no webhook secret configured for the installation"
+	} else if signature == "" {
+		rejectionReason = "missing " + signatureHeader + " header"
+	} else {
+		for _, secret := range secrets {
+			payload, err := github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), signature, []byte(secret))
+			if err == nil {
+				return payload, nil
+			}
+		}
+	}
+
+	rejectedDelivery := RejectedWebhookDelivery{
+		DeliveryID:     github.DeliveryID(c.Request),
+		Event:          github.WebHookType(c.Request),
+		InstallationID: sender.Installation.ID,
+		Repository:     sender.Repository.FullName,
+		RemoteAddr:     c.ClientIP(),
+		Reason:         rejectionReason,
+	}
+	recordRejectedDelivery(store, rejectedDelivery, time.Now())
+	return nil, fmt.Errorf("%w: %s", errWebhookRejected, rejectionReason)
+}
+
+// AdminGuard protects the admin endpoints with the bearer token of ADMIN_TOKEN, they are disabled without it.
+func AdminGuard(c *gin.Context) {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin endpoints are disabled, set ADMIN_TOKEN to enable them"})
+		return
+	}
+	token := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization required"})
+		return
+	}
+	c.Next()
+}
+
+func (h *GithubService) RejectedDeliveriesHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	var rejectedDeliveries []RejectedWebhookDelivery
+	if err := h.DB.Order("created_at DESC").Limit(limit).Find(&rejectedDeliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rejectedDeliveries": rejectedDeliveries})
+}
+
+type webhookSecretRequest struct {
+	// Secrets accepted for the connection, the current one first
+	Secrets []string `json:"secrets"`
+}
+
+// UpdateWebhookSecretHandler sets the webhook secrets of the connection of an installation.
+func (h *GithubService) UpdateWebhookSecretHandler(c *gin.Context) {
+	installationId, err := strconv.ParseInt(c.Param("installation-id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req webhookSecretRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, secret := range req.Secrets {
+		if strings.Contains(secret, ",") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "secrets can not contain a comma"})
+			return
+		}
+	}
+
+	result := h.DB.Model(&GithubConnection{}).Where("installation_id = ?", installationId).Update("webhook_secret", strings.Join(req.Secrets, ","))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "connection not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook secrets updated", "count": len(req.Secrets)})
+}
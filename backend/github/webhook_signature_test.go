@@ -0,0 +1,149 @@
+package github
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseSecrets(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"current", []string{"current"}},
+		{"current, previous", []string{"current", "previous"}},
+		{"current,,previous,", []string{"current", "previous"}},
+	}
+
+	for _, tc := range testCases {
+		got := parseSecrets(tc.input)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseSecrets(%q) = %v; want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+type fakeWebhookDeliveryStore struct {
+	secrets  map[int64]string
+	rejected []RejectedWebhookDelivery
+}
+
+func (store *fakeWebhookDeliveryStore) GetConnectionSecrets(installationId int64) ([]string, error) {
+	return parseSecrets(store.secrets[installationId]), nil
+}
+
+func (store *fakeWebhookDeliveryStore) HasConnectionSecrets() (bool, error) {
+	for _, secrets := range store.secrets {
+		if len(parseSecrets(secrets)) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (store *fakeWebhookDeliveryStore) CountRejectedDeliveriesSince(since time.Time) (int64, error) {
+	var count int64
+	for _, delivery := range store.rejected {
+		if delivery.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *fakeWebhookDeliveryStore) RecordRejectedDelivery(delivery RejectedWebhookDelivery) error {
+	store.rejected = append(store.rejected, delivery)
+	return nil
+}
+
+func newWebhookContext(installationId int64, secret string) *gin.Context {
+	body := []byte(fmt.Sprintf(`{"installation":{"id":%d},"repository":{"full_name":"owner/repo"}}`, installationId))
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("X-GitHub-Event", "push")
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		c.Request.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return c
+}
+
+func TestVerifyWebhookPayload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name           string
+		globalSecret   string
+		allowUnsigned  string
+		secrets        map[int64]string
+		installationId int64
+		signedWith     string
+		wantRejection  string
+	}{
+		{name: "valid signature", secrets: map[int64]string{1: "current,previous"}, installationId: 1, signedWith: "current"},
+		{name: "rotated secret", secrets: map[int64]string{1: "current,previous"}, installationId: 1, signedWith: "previous"},
+		{name: "global secret", globalSecret: "global", installationId: 2, signedWith: "global"},
+		{name: "invalid signature", secrets: map[int64]string{1: "current"}, installationId: 1, signedWith: "other", wantRejection: "invalid signature"},
+		{name: "missing signature", secrets: map[int64]string{1: "current"}, installationId: 1, wantRejection: "missing " + signatureHeader + " header"},
+		{name: "unknown installation", allowUnsigned: "true", secrets: map[int64]string{1: "current"}, installationId: 2, wantRejection: "no webhook secret configured for the installation"},
+		{name: "no secret without opt-in", installationId: 1, wantRejection: "no webhook secret configured for the installation"},
+		{name: "no secret with opt-in", allowUnsigned: "true", installationId: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_WEBHOOK_SECRET", tc.globalSecret)
+			t.Setenv("GITHUB_WEBHOOK_ALLOW_UNSIGNED", tc.allowUnsigned)
+			store := &fakeWebhookDeliveryStore{secrets: tc.secrets}
+
+			payload, err := verifyWebhookPayload(newWebhookContext(tc.installationId, tc.signedWith), store)
+
+			if tc.wantRejection == "" {
+				if err != nil || payload == nil {
+					t.Fatalf("Expected the delivery to be accepted, but got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errWebhookRejected) {
+				t.Fatalf("Expected the delivery to be rejected, but got %v", err)
+			}
+			if len(store.rejected) != 1 || store.rejected[0].Reason != tc.wantRejection || store.rejected[0].InstallationID != tc.installationId {
+				t.Errorf("Expected a rejected delivery with reason %q, but got %+v", tc.wantRejection, store.rejected)
+			}
+		})
+	}
+}
+
+func TestRecordRejectedDeliveryIsCapped(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeWebhookDeliveryStore{}
+	for i := 0; i < maxRejectedDeliveriesPerHour; i++ {
+		store.rejected = append(store.rejected, RejectedWebhookDelivery{CreatedAt: now.Add(-2 * time.Hour)})
+	}
+
+	recordRejectedDelivery(store, RejectedWebhookDelivery{CreatedAt: now, DeliveryID: "recorded"}, now)
+	if len(store.rejected) != maxRejectedDeliveriesPerHour+1 {
+		t.Fatalf("Expected the deliveries of more than an hour ago not to count, got %d deliveries", len(store.rejected))
+	}
+
+	for i := 1; i < maxRejectedDeliveriesPerHour; i++ {
+		store.rejected = append(store.rejected, RejectedWebhookDelivery{CreatedAt: now.Add(-time.Minute)})
+	}
+	recordRejectedDelivery(store, RejectedWebhookDelivery{CreatedAt: now, DeliveryID: "dropped"}, now)
+	if last := store.rejected[len(store.rejected)-1]; last.DeliveryID == "dropped" {
+		t.Errorf("Expected the delivery past the hourly cap not to be recorded")
+	}
+}
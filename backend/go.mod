@@ -77,7 +77,6 @@ require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/sergi/go-diff v1.3.1 // indirect
 	github.com/shopspring/decimal v1.3.1
-	github.com/snabb/isoweek v1.0.3
 	github.com/xeipuuv/gojsonschema v1.2.0
 	golang.org/x/oauth2 v0.7.0
 	gorm.io/driver/postgres v1.5.4
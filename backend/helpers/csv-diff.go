@@ -0,0 +1,370 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultUniqueKey is the key of the snapshots that do not declare one.
+var DefaultUniqueKey = []string{"unique_key"}
+
+// Number of unchanged lines kept around the changes of a patch, as diff -u does.
+const patchContextLines = 3
+
+type CsvRow struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+type CsvColumnChange struct {
+	Column   string `json:"column"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+type CsvModifiedRow struct {
+	Key       string            `json:"key"`
+	OldValues []string          `json:"oldValues"`
+	NewValues []string          `json:"newValues"`
+	Changes   []CsvColumnChange `json:"changes"`
+}
+
+// CsvDuplicateKey is a key shared by several rows of a snapshot, a data quality error.
+type CsvDuplicateKey struct {
+	Snapshot string `json:"snapshot,omitempty"`
+	Key      string `json:"key"`
+	RowCount int    `json:"rowCount"`
+}
+
+// CsvDiff is the row level difference between two snapshots of a table, rows being matched on their unique key.
+type CsvDiff struct {
+	Headers         []string `json:"headers"`
+	PreviousHeaders []string `json:"previousHeaders"`
+	// Columns of the key used to match rows, empty when whole rows are compared
+	KeyColumns        []string          `json:"keyColumns"`
+	DuplicateKeys     []CsvDuplicateKey `json:"duplicateKeys"`
+	AddedRows         []CsvRow          `json:"addedRows"`
+	RemovedRows       []CsvRow          `json:"removedRows"`
+	ModifiedRows      []CsvModifiedRow  `json:"modifiedRows"`
+	UnchangedRowCount int               `json:"unchangedRowCount"`
+	Truncated         bool              `json:"truncated"`
+
+	// Every row in key order, used to render the unified patch with its context lines
+	lines []csvDiffLine
+}
+
+type csvDiffLineType int
+
+const (
+	csvDiffLineUnchanged csvDiffLineType = iota
+	csvDiffLineAdded
+	csvDiffLineRemoved
+	csvDiffLineModified
+)
+
+type csvDiffLine struct {
+	lineType  csvDiffLineType
+	oldValues []string
+	newValues []string
+}
+
+type keyedCsvRows struct {
+	keys        []string
+	rowsByKey   map[string][][]string
+	columnIndex map[string]int
+}
+
+// GetRowKeyFunction returns how the rows of a snapshot are identified by the key columns.
+// A single column key is its value, a composite key is the CSV line of its values so that it stays unambiguous.
+// It returns false when a key column is missing from the headers.
+func GetRowKeyFunction(headers []string, keyColumns []string) (func(row []string) string, bool) {
+	if len(keyColumns) == 0 {
+		return nil, false
+	}
+	keyIndexes := make([]int, len(keyColumns))
+	for i, keyColumn := range keyColumns {
+		keyIndexes[i] = indexOfColumn(headers, keyColumn)
+		if keyIndexes[i] == -1 {
+			return nil, false
+		}
+	}
+	return func(row []string) string {
+		keyValues := make([]string, len(keyIndexes))
+		for i, keyIndex := range keyIndexes {
+			if keyIndex < len(row) {
+				keyValues[i] = row[keyIndex]
+			}
+		}
+		if len(keyValues) == 1 {
+			return keyValues[0]
+		}
+		return csvLine(keyValues)
+	}, true
+}
+
+// FindDuplicateKeys returns the keys shared by several rows of a snapshot given as records, headers first.
+func FindDuplicateKeys(records [][]string, keyColumns []string) []CsvDuplicateKey {
+	if len(records) == 0 {
+		return nil
+	}
+	getKey, ok := GetRowKeyFunction(records[0], keyColumns)
+	if !ok {
+		return nil
+	}
+	return groupRowsByKey(records, getKey).duplicateKeys("")
+}
+
+func indexOfColumn(headers []string, columnName string) int {
+	for i, header := range headers {
+		if header == columnName {
+			return i
+		}
+	}
+	return -1
+}
+
+func (grouped keyedCsvRows) duplicateKeys(snapshot string) []CsvDuplicateKey {
+	var duplicateKeys []CsvDuplicateKey
+	for _, key := range grouped.keys {
+		if rowCount := len(grouped.rowsByKey[key]); rowCount > 1 {
+			duplicateKeys = append(duplicateKeys, CsvDuplicateKey{Snapshot: snapshot, Key: key, RowCount: rowCount})
+		}
+	}
+	return duplicateKeys
+}
+
+// DuplicateKeyError reports the duplicate keys of a diff, nil when every key is unique.
+func (diff CsvDiff) DuplicateKeyError() error {
+	if len(diff.DuplicateKeys) == 0 {
+		return nil
+	}
+	duplicateKey := diff.DuplicateKeys[0]
+	return fmt.Errorf("%d duplicate keys for %v, e.g. %q has %d rows in the %s snapshot", len(diff.DuplicateKeys), diff.KeyColumns, duplicateKey.Key, duplicateKey.RowCount, duplicateKey.Snapshot)
+}
+
+func groupRowsByKey(records [][]string, getKey func(row []string) string) keyedCsvRows {
+	grouped := keyedCsvRows{rowsByKey: make(map[string][][]string), columnIndex: make(map[string]int)}
+	if len(records) == 0 {
+		return grouped
+	}
+	for i, header := range records[0] {
+		grouped.columnIndex[header] = i
+	}
+	for _, row := range records[1:] {
+		key := getKey(row)
+		if _, ok := grouped.rowsByKey[key]; !ok {
+			grouped.keys = append(grouped.keys, key)
+		}
+		grouped.rowsByKey[key] = append(grouped.rowsByKey[key], row)
+	}
+	return grouped
+}
+
+func getValue(row []string, columnIndex map[string]int, column string) string {
+	index, ok := columnIndex[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
+// ComputeCsvDiff compares two snapshots of a table given as records, headers first.
+// Rows are matched on the key columns, unique_key by default. When a snapshot misses a key column,
+// whole rows are compared instead.
+func ComputeCsvDiff(currentCsv [][]string, previousCsv [][]string, keyColumns ...string) CsvDiff {
+	var diff CsvDiff
+	if len(currentCsv) > 0 {
+		diff.Headers = currentCsv[0]
+	}
+	if len(previousCsv) > 0 {
+		diff.PreviousHeaders = previousCsv[0]
+	}
+	if len(keyColumns) == 0 {
+		keyColumns = DefaultUniqueKey
+	}
+
+	getCurrentKey, hasCurrentKey := GetRowKeyFunction(diff.Headers, keyColumns)
+	getPreviousKey, hasPreviousKey := GetRowKeyFunction(diff.PreviousHeaders, keyColumns)
+	if hasCurrentKey && hasPreviousKey {
+		diff.KeyColumns = keyColumns
+	} else {
+		getCurrentKey, getPreviousKey = csvLine, csvLine
+	}
+	current := groupRowsByKey(currentCsv, getCurrentKey)
+	previous := groupRowsByKey(previousCsv, getPreviousKey)
+	if diff.KeyColumns != nil {
+		diff.DuplicateKeys = append(current.duplicateKeys("current"), previous.duplicateKeys("previous")...)
+	}
+
+	columns := append([]string{}, diff.Headers...)
+	for _, header := range diff.PreviousHeaders {
+		if _, ok := current.columnIndex[header]; !ok {
+			columns = append(columns, header)
+		}
+	}
+
+	keys := append([]string{}, current.keys...)
+	for _, key := range previous.keys {
+		if _, ok := current.rowsByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		currentRows := current.rowsByKey[key]
+		previousRows := previous.rowsByKey[key]
+		for i := 0; i < len(currentRows) || i < len(previousRows); i++ {
+			if i >= len(previousRows) {
+				diff.AddedRows = append(diff.AddedRows, CsvRow{Key: key, Values: currentRows[i]})
+				diff.lines = append(diff.lines, csvDiffLine{lineType: csvDiffLineAdded, newValues: currentRows[i]})
+				continue
+			}
+			if i >= len(currentRows) {
+				diff.RemovedRows = append(diff.RemovedRows, CsvRow{Key: key, Values: previousRows[i]})
+				diff.lines = append(diff.lines, csvDiffLine{lineType: csvDiffLineRemoved, oldValues: previousRows[i]})
+				continue
+			}
+			var changes []CsvColumnChange
+			for _, column := range columns {
+				oldValue := getValue(previousRows[i], previous.columnIndex, column)
+				newValue := getValue(currentRows[i], current.columnIndex, column)
+				if oldValue != newValue {
+					changes = append(changes, CsvColumnChange{Column: column, OldValue: oldValue, NewValue: newValue})
+				}
+			}
+			if len(changes) == 0 {
+				diff.UnchangedRowCount++
+				diff.lines = append(diff.lines, csvDiffLine{lineType: csvDiffLineUnchanged, oldValues: previousRows[i], newValues: currentRows[i]})
+				continue
+			}
+			diff.ModifiedRows = append(diff.ModifiedRows, CsvModifiedRow{Key: key, OldValues: previousRows[i], NewValues: currentRows[i], Changes: changes})
+			diff.lines = append(diff.lines, csvDiffLine{lineType: csvDiffLineModified, oldValues: previousRows[i], newValues: currentRows[i]})
+		}
+	}
+	return diff
+}
+
+// Truncate keeps at most maxRows rows of each kind of change.
+func (diff *CsvDiff) Truncate(maxRows int) {
+	if len(diff.AddedRows) > maxRows {
+		diff.AddedRows = diff.AddedRows[:maxRows]
+		diff.Truncated = true
+	}
+	if len(diff.RemovedRows) > maxRows {
+		diff.RemovedRows = diff.RemovedRows[:maxRows]
+		diff.Truncated = true
+	}
+	if len(diff.ModifiedRows) > maxRows {
+		diff.ModifiedRows = diff.ModifiedRows[:maxRows]
+		diff.Truncated = true
+	}
+}
+
+type patchLine struct {
+	prefix byte
+	text   string
+}
+
+// UnifiedPatch renders the diff as the hunks of a diff -u of the snapshots sorted by key.
+// The header is always part of the patch, so that the patch can be read on its own.
+func (diff CsvDiff) UnifiedPatch() string {
+	var lines []patchLine
+	// The trailing space of the previous header makes sure it will be present in the patch
+	removedLines := []patchLine{{'-', csvLine(diff.PreviousHeaders) + " "}}
+	addedLines := []patchLine{{'+', csvLine(diff.Headers)}}
+	flushChanges := func() {
+		lines = append(lines, removedLines...)
+		lines = append(lines, addedLines...)
+		removedLines, addedLines = nil, nil
+	}
+	for _, line := range diff.lines {
+		switch line.lineType {
+		case csvDiffLineUnchanged:
+			flushChanges()
+			lines = append(lines, patchLine{' ', csvLine(line.newValues)})
+		case csvDiffLineAdded:
+			addedLines = append(addedLines, patchLine{'+', csvLine(line.newValues)})
+		case csvDiffLineRemoved:
+			removedLines = append(removedLines, patchLine{'-', csvLine(line.oldValues)})
+		case csvDiffLineModified:
+			removedLines = append(removedLines, patchLine{'-', csvLine(line.oldValues)})
+			addedLines = append(addedLines, patchLine{'+', csvLine(line.newValues)})
+		}
+	}
+	flushChanges()
+	return renderHunks(lines)
+}
+
+func renderHunks(lines []patchLine) string {
+	var hunks []string
+	oldLineNumber, newLineNumber := 0, 0
+	for start := 0; start < len(lines); {
+		firstChange := start
+		for firstChange < len(lines) && lines[firstChange].prefix == ' ' {
+			firstChange++
+		}
+		if firstChange == len(lines) {
+			break
+		}
+		// Extend the hunk while the next change is close enough for the contexts to overlap
+		lastChange := firstChange
+		for i := firstChange + 1; i < len(lines) && i-lastChange <= 2*patchContextLines+1; i++ {
+			if lines[i].prefix != ' ' {
+				lastChange = i
+			}
+		}
+		hunkStart := firstChange - patchContextLines
+		if hunkStart < start {
+			hunkStart = start
+		}
+		hunkEnd := lastChange + patchContextLines + 1
+		if hunkEnd > len(lines) {
+			hunkEnd = len(lines)
+		}
+
+		// Lines between two hunks are unchanged
+		oldLineNumber += hunkStart - start
+		newLineNumber += hunkStart - start
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, line := range lines[hunkStart:hunkEnd] {
+			if line.prefix != '+' {
+				oldCount++
+			}
+			if line.prefix != '-' {
+				newCount++
+			}
+			body.WriteString("\n")
+			body.WriteByte(line.prefix)
+			body.WriteString(line.text)
+		}
+		hunks = append(hunks, fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldLineNumber, oldCount), hunkRange(newLineNumber, newCount))+body.String())
+		oldLineNumber += oldCount
+		newLineNumber += newCount
+		start = hunkEnd
+	}
+	return strings.Join(hunks, "\n")
+}
+
+// hunkRange formats the range of a hunk the way diff -u does, linesBefore being the number of lines preceding it.
+func hunkRange(linesBefore int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", linesBefore)
+	case 1:
+		return fmt.Sprintf("%d", linesBefore+1)
+	default:
+		return fmt.Sprintf("%d,%d", linesBefore+1, count)
+	}
+}
+
+func csvLine(row []string) string {
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	writer.Write(row)
+	writer.Flush()
+	return strings.TrimSuffix(line.String(), "\n")
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeCsvDiff(t *testing.T) {
+	currentCsv := [][]string{
+		{"unique_key", "name", "amount"},
+		{"3", "Charlie", "30"},
+		{"1", "Alice", "12"},
+		{"4", "Didou", "40"},
+	}
+	previousCsv := [][]string{
+		{"unique_key", "name", "amount"},
+		{"1", "Alice", "10"},
+		{"2", "Bob", "20"},
+		{"3", "Charlie", "30"},
+	}
+
+	diff := ComputeCsvDiff(currentCsv, previousCsv)
+
+	expectedAdded := []CsvRow{{Key: "4", Values: []string{"4", "Didou", "40"}}}
+	if !reflect.DeepEqual(diff.AddedRows, expectedAdded) {
+		t.Errorf("Expected added rows %v, but got %v", expectedAdded, diff.AddedRows)
+	}
+	expectedRemoved := []CsvRow{{Key: "2", Values: []string{"2", "Bob", "20"}}}
+	if !reflect.DeepEqual(diff.RemovedRows, expectedRemoved) {
+		t.Errorf("Expected removed rows %v, but got %v", expectedRemoved, diff.RemovedRows)
+	}
+	expectedModified := []CsvModifiedRow{{
+		Key:       "1",
+		OldValues: []string{"1", "Alice", "10"},
+		NewValues: []string{"1", "Alice", "12"},
+		Changes:   []CsvColumnChange{{Column: "amount", OldValue: "10", NewValue: "12"}},
+	}}
+	if !reflect.DeepEqual(diff.ModifiedRows, expectedModified) {
+		t.Errorf("Expected modified rows %v, but got %v", expectedModified, diff.ModifiedRows)
+	}
+	if diff.UnchangedRowCount != 1 {
+		t.Errorf("Expected 1 unchanged row, but got %d", diff.UnchangedRowCount)
+	}
+
+	expectedPatch := "@@ -1,4 +1,4 @@\n-unique_key,name,amount \n-1,Alice,10\n-2,Bob,20\n+unique_key,name,amount\n+1,Alice,12\n 3,Charlie,30\n+4,Didou,40"
+	if patch := diff.UnifiedPatch(); patch != expectedPatch {
+		t.Errorf("Expected patch:\n%s\nbut got:\n%s", expectedPatch, patch)
+	}
+	if previousCsv[0][2] != "amount" {
+		t.Errorf("ComputeCsvDiff should not modify its input, got header %v", previousCsv[0])
+	}
+}
+
+func TestComputeCsvDiffWithoutUniqueKey(t *testing.T) {
+	currentCsv := [][]string{{"name", "amount"}, {"Alice", "12"}}
+	previousCsv := [][]string{{"name", "amount"}, {"Alice", "10"}}
+
+	diff := ComputeCsvDiff(currentCsv, previousCsv)
+
+	if len(diff.AddedRows) != 1 || len(diff.RemovedRows) != 1 || len(diff.ModifiedRows) != 0 {
+		t.Errorf("Expected rows without unique key to be added and removed, but got %+v", diff)
+	}
+}
+
+func TestComputeCsvDiffWithCompositeKey(t *testing.T) {
+	currentCsv := [][]string{
+		{"customer_id", "invoice_date", "amount"},
+		{"1", "2023-01-01", "10"},
+		{"1", "2023-02-01", "25"},
+		{"2", "2023-01-01", "5"},
+		{"2", "2023-01-01", "6"},
+	}
+	previousCsv := [][]string{
+		{"customer_id", "invoice_date", "amount"},
+		{"1", "2023-01-01", "10"},
+		{"1", "2023-02-01", "20"},
+	}
+
+	diff := ComputeCsvDiff(currentCsv, previousCsv, "customer_id", "invoice_date")
+
+	if !reflect.DeepEqual(diff.KeyColumns, []string{"customer_id", "invoice_date"}) {
+		t.Errorf("Expected key columns [customer_id invoice_date], but got %v", diff.KeyColumns)
+	}
+	if len(diff.ModifiedRows) != 1 || diff.ModifiedRows[0].Key != "1,2023-02-01" {
+		t.Errorf("Expected row 1,2023-02-01 to be modified, but got %v", diff.ModifiedRows)
+	}
+	expectedDuplicates := []CsvDuplicateKey{{Snapshot: "current", Key: "2,2023-01-01", RowCount: 2}}
+	if !reflect.DeepEqual(diff.DuplicateKeys, expectedDuplicates) {
+		t.Errorf("Expected duplicate keys %v, but got %v", expectedDuplicates, diff.DuplicateKeys)
+	}
+	if diff.DuplicateKeyError() == nil {
+		t.Errorf("Expected a data quality error for the duplicate keys")
+	}
+}
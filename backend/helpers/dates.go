@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts are the layouts of the date columns, timestamps first so that the hour of a row is kept.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDateTime parses the value of a date column, either a date or a timestamp, in UTC.
+func ParseDateTime(value string) (time.Time, error) {
+	return ParseDateTimeInLocation(value, time.UTC)
+}
+
+// ParseDateTimeInLocation parses the value of a date column in a location: dates and timestamps without offset
+// are wall clock times of the location, timestamps with an offset are converted to it.
+// Values in an unknown layout fall back to their first 10 characters, e.g. 2023-05-01 00:00:00.000 UTC.
+func ParseDateTimeInLocation(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateTimeLayouts {
+		if parsedTime, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsedTime.In(location), nil
+		}
+	}
+	if len(value) > 10 {
+		if parsedTime, err := time.ParseInLocation("2006-01-02", value[:10], location); err == nil {
+			return parsedTime, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateTime(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2023-05-01", time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2023-05-01T10:30:00Z", time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"2023-05-01T10:30:00+02:00", time.Date(2023, 5, 1, 8, 30, 0, 0, time.UTC)},
+		{"2023-05-01 10:30:00", time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"2023-05-01T10:30", time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"2023-05-01 10:30:00.000 UTC", time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseDateTime(tc.input)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseDateTime(%v) = %v, %v; want %v", tc.input, got, err, tc.want)
+		}
+	}
+
+	if _, err := ParseDateTime("not a date"); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+}
+
+func TestParseDateTimeInLocation(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	got, err := ParseDateTimeInLocation("2023-05-01 23:30:00", paris)
+	if err != nil || !got.Equal(time.Date(2023, 5, 1, 21, 30, 0, 0, time.UTC)) || got.Location() != paris {
+		t.Errorf("Expected a local wall clock, got %v, %v", got, err)
+	}
+	got, _ = ParseDateTimeInLocation("2023-05-01T21:30:00Z", paris)
+	if got.Format("2006-01-02 15:04") != "2023-05-01 23:30" {
+		t.Errorf("Expected a timestamp with an offset converted to the location, got %v", got)
+	}
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/google/go-github/v56/github"
+)
+
+// ListAllCommits follows the pagination of the commit list until the last page.
+func ListAllCommits(ctx context.Context, client *github.Client, repoOwner string, repoName string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, error) {
+	commits, _, err := ListCommitPages(ctx, client, repoOwner, repoName, opts, 0)
+	return commits, err
+}
+
+// ListCommitPages follows the pagination of the commit list from the page of opts for at most maxPages pages of 100 commits,
+// every page when maxPages is zero. It returns the next page to list, zero after the last page.
+func ListCommitPages(ctx context.Context, client *github.Client, repoOwner string, repoName string, opts *github.CommitsListOptions, maxPages int) ([]*github.RepositoryCommit, int, error) {
+	pageOpts := *opts
+	pageOpts.PerPage = 100
+	var allCommits []*github.RepositoryCommit
+	for page := 1; ; page++ {
+		commits, resp, err := client.Repositories.ListCommits(ctx, repoOwner, repoName, &pageOpts)
+		if err != nil {
+			return nil, 0, err
+		}
+		allCommits = append(allCommits, commits...)
+		if resp.NextPage == 0 || page == maxPages {
+			return allCommits, resp.NextPage, nil
+		}
+		pageOpts.Page = resp.NextPage
+	}
+}
+
+// ListCommitsAhead returns the committer dates of the commits of the default branch that are not ancestors of a base commit.
+func ListCommitsAhead(ctx context.Context, client *github.Client, repoOwner string, repoName string, base common.CommitSha) (map[common.CommitSha]time.Time, error) {
+	repository, _, err := client.Repositories.Get(ctx, repoOwner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	aheadCommits := make(map[common.CommitSha]time.Time)
+	pageOpts := &github.ListOptions{PerPage: 100}
+	for {
+		comparison, resp, err := client.Repositories.CompareCommits(ctx, repoOwner, repoName, string(base), repository.GetDefaultBranch(), pageOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range comparison.Commits {
+			aheadCommits[common.CommitSha(commit.GetSHA())] = commit.GetCommit().GetCommitter().GetDate().Time
+		}
+		if resp.NextPage == 0 || len(comparison.Commits) == 0 {
+			break
+		}
+		pageOpts.Page = resp.NextPage
+	}
+	return aheadCommits, nil
+}
+
+// GetCommitWindow returns the since and until dates of the commits to process for a metric.
+// A zero since means from the first commit, until defaults to now. The dates are days of the timezone of the metric.
+func GetCommitWindow(metric common.MetricConfig) (time.Time, time.Time, error) {
+	var since time.Time
+	until := time.Now()
+	location, err := metric.GetLocation()
+	if err != nil {
+		return since, until, err
+	}
+	if metric.Since != "" {
+		sinceDate, err := time.ParseInLocation("2006-01-02", metric.Since, location)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid since date %s: %v", metric.Since, err.Error())
+		}
+		since = sinceDate
+	}
+	if metric.Until != "" {
+		untilDate, err := time.ParseInLocation("2006-01-02", metric.Until, location)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid until date %s: %v", metric.Until, err.Error())
+		}
+		// The until date is inclusive
+		until = untilDate.AddDate(0, 0, 1)
+	}
+	if !since.IsZero() && !until.After(since) {
+		return since, until, fmt.Errorf("until date %s is before since date %s", metric.Until, metric.Since)
+	}
+	return since, until, nil
+}
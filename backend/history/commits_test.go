@@ -0,0 +1,76 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/google/go-github/v56/github"
+)
+
+func TestGetCommitWindow(t *testing.T) {
+	since, until, err := GetCommitWindow(common.MetricConfig{Since: "2023-01-01", Until: "2023-03-31"})
+	if err != nil {
+		t.Fatalf("GetCommitWindow returned an error: %v", err)
+	}
+	if !since.Equal(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected since 2023-01-01, but got %v", since)
+	}
+	if !until.Equal(time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected until 2023-04-01, but got %v", until)
+	}
+
+	since, _, err = GetCommitWindow(common.MetricConfig{})
+	if err != nil || !since.IsZero() {
+		t.Errorf("Expected no since date without config, but got %v (%v)", since, err)
+	}
+
+	if _, _, err := GetCommitWindow(common.MetricConfig{Since: "2023-03-01", Until: "2023-01-31"}); err == nil {
+		t.Errorf("Expected an error when until is before since")
+	}
+}
+
+func TestListCommitPages(t *testing.T) {
+	requestedPages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPages++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		// 10 pages of a single commit
+		if page < 10 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, "http://"+r.Host, r.URL.Path, page+1))
+		}
+		fmt.Fprintf(w, `[{"sha": "sha-%d"}]`, page)
+	}))
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	commits, nextPage, err := ListCommitPages(context.Background(), client, "acme", "data", &github.CommitsListOptions{}, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(commits) != 3 || requestedPages != 3 || commits[2].GetSHA() != "sha-3" || nextPage != 4 {
+		t.Errorf("Expected the first 3 pages and the next page 4, got %d commits in %d requests and %d", len(commits), requestedPages, nextPage)
+	}
+
+	requestedPages = 0
+	commits, nextPage, err = ListCommitPages(context.Background(), client, "acme", "data", &github.CommitsListOptions{ListOptions: github.ListOptions{Page: 9}}, 3)
+	if err != nil || len(commits) != 2 || commits[0].GetSHA() != "sha-9" || nextPage != 0 {
+		t.Errorf("Expected the last 2 pages from page 9, got %d commits, next page %d, %v", len(commits), nextPage, err)
+	}
+
+	requestedPages = 0
+	commits, err = ListAllCommits(context.Background(), client, "acme", "data", &github.CommitsListOptions{})
+	if err != nil || len(commits) != 10 || requestedPages != 10 {
+		t.Errorf("Expected every page, got %d commits in %d requests, %v", len(commits), requestedPages, err)
+	}
+}
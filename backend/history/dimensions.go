@@ -0,0 +1,125 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+// dimensionGroup is a group of columns of the metric file whose combinations of values are tracked.
+type dimensionGroup struct {
+	dimension common.Dimension
+	columns   []string
+	indexes   []int
+}
+
+// getDimensionGroups binds the dimension groups of a metric to the headers of a snapshot, dropping the groups with a missing column.
+func getDimensionGroups(headers []string, metric common.MetricConfig) []dimensionGroup {
+	columnIndexes := make(map[string]int)
+	for i, header := range headers {
+		columnIndexes[header] = i
+	}
+
+	var groups []dimensionGroup
+	for _, columns := range metric.GetDimensionGroups() {
+		group := dimensionGroup{dimension: common.NewDimension(columns), columns: columns}
+		for _, column := range columns {
+			index, ok := columnIndexes[column]
+			if !ok {
+				break
+			}
+			group.indexes = append(group.indexes, index)
+		}
+		if len(group.indexes) == len(columns) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func (group dimensionGroup) values(record []string) common.DimensionValues {
+	values := make(common.DimensionValues, len(group.columns))
+	for i, column := range group.columns {
+		values[column] = record[group.indexes[i]]
+	}
+	return values
+}
+
+// dimensionTracker caps the number of combinations of values tracked per dimension group.
+// A combination keeps its own metric once tracked, the others being rolled into the other bucket,
+// so that the history of a combination never moves in and out of the other bucket.
+type dimensionTracker struct {
+	maxValues int
+	tracked   map[common.Dimension]map[string]bool
+}
+
+// newDimensionTracker tracks the combinations of the stored history of the metric, a nil tracker leaving every combination apart.
+func newDimensionTracker(metric common.MetricConfig, storedMetrics common.Metrics) *dimensionTracker {
+	if metric.MaxDimensionValues <= 0 {
+		return nil
+	}
+	tracker := &dimensionTracker{
+		maxValues: metric.MaxDimensionValues,
+		tracked:   make(map[common.Dimension]map[string]bool),
+	}
+	for _, storedMetric := range storedMetrics {
+		if storedMetric.Dimension == common.NoDimension || storedMetric.DimensionValues.IsOther() {
+			continue
+		}
+		tracker.track(storedMetric.Dimension, storedMetric.DimensionValues.Encode())
+	}
+	return tracker
+}
+
+func (tracker *dimensionTracker) track(dimension common.Dimension, encodedValues string) {
+	if tracker.tracked[dimension] == nil {
+		tracker.tracked[dimension] = make(map[string]bool)
+	}
+	tracker.tracked[dimension][encodedValues] = true
+}
+
+// admit gives the free slots of each group to its most frequent untracked combinations of the snapshot.
+func (tracker *dimensionTracker) admit(records [][]string, groups []dimensionGroup, match func(row []string) bool) {
+	if tracker == nil {
+		return
+	}
+	for _, group := range groups {
+		rowCounts := make(map[string]int)
+		for _, record := range records[1:] {
+			if match(record) {
+				rowCounts[group.values(record).Encode()]++
+			}
+		}
+
+		var candidates []string
+		for encodedValues := range rowCounts {
+			if !tracker.tracked[group.dimension][encodedValues] {
+				candidates = append(candidates, encodedValues)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if rowCounts[candidates[i]] != rowCounts[candidates[j]] {
+				return rowCounts[candidates[i]] > rowCounts[candidates[j]]
+			}
+			return candidates[i] < candidates[j]
+		})
+		for _, encodedValues := range candidates {
+			if len(tracker.tracked[group.dimension]) >= tracker.maxValues {
+				break
+			}
+			tracker.track(group.dimension, encodedValues)
+		}
+	}
+}
+
+// resolve returns the values under which a combination is aggregated, the other bucket when it is not tracked.
+func (tracker *dimensionTracker) resolve(group dimensionGroup, values common.DimensionValues) common.DimensionValues {
+	if tracker == nil || tracker.tracked[group.dimension][values.Encode()] {
+		return values
+	}
+	otherValues := make(common.DimensionValues, len(group.columns))
+	for _, column := range group.columns {
+		otherValues[column] = common.OtherDimensionValue
+	}
+	return otherValues
+}
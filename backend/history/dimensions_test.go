@@ -0,0 +1,95 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+var dimensionRecords = [][]string{
+	{"unique_key", "date", "amount", "country", "channel"},
+	{"a", "2023-05-01", "10", "FR", "ads"},
+	{"b", "2023-05-02", "20", "FR", "ads"},
+	{"c", "2023-05-03", "5", "FR", "seo"},
+	{"d", "2023-05-04", "7", "US", "ads"},
+	{"e", "2023-05-05", "1", "DE", "ads"},
+}
+
+func TestBuildMetricSnapshot_DimensionGroups(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName:   "amount",
+		TimeGrains:      []common.TimeGrain{common.Month},
+		DimensionGroups: [][]string{{"country", "channel"}},
+	}
+	snapshot, err := buildMetricSnapshot(dimensionRecords, metric, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := snapshot[common.NewPeriodAndDimensionKey("2023-05", common.DimensionValues{"country": "FR", "channel": "ads"})]
+	if bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(30)) || bucket.lines != 2 {
+		t.Fatalf("Unexpected bucket of FR, ads %+v", bucket)
+	}
+	if bucket.dimension != "country,channel" || bucket.dimensionValue != "FR, ads" {
+		t.Errorf("Unexpected dimension %s and value %s", bucket.dimension, bucket.dimensionValue)
+	}
+	if len(snapshot) != 5 {
+		t.Errorf("Expected the period and 4 combinations, got %d buckets", len(snapshot))
+	}
+}
+
+func TestBuildMetricSnapshot_MaxDimensionValues(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName:      "amount",
+		TimeGrains:         []common.TimeGrain{common.Month},
+		Dimensions:         []string{"country"},
+		MaxDimensionValues: 1,
+	}
+	tracker := newDimensionTracker(metric, nil)
+	snapshot, err := buildMetricSnapshot(dimensionRecords, metric, tracker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	france := snapshot[common.NewPeriodAndDimensionKey("2023-05", common.DimensionValues{"country": "FR"})]
+	other := snapshot[common.NewPeriodAndDimensionKey("2023-05", common.DimensionValues{"country": common.OtherDimensionValue})]
+	if france == nil || other == nil || !other.kpi().Equal(decimal.NewFromInt(8)) {
+		t.Fatalf("Expected the most frequent country apart and the others rolled up, got %+v", snapshot)
+	}
+
+	// A tracked combination keeps its metric even when it becomes rare.
+	nextRecords := [][]string{
+		{"unique_key", "date", "amount", "country", "channel"},
+		{"a", "2023-05-01", "10", "FR", "ads"},
+		{"d", "2023-05-04", "7", "US", "ads"},
+		{"f", "2023-05-06", "3", "US", "seo"},
+	}
+	nextSnapshot, err := buildMetricSnapshot(nextRecords, metric, tracker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextSnapshot[common.NewPeriodAndDimensionKey("2023-05", common.DimensionValues{"country": "FR"})] == nil {
+		t.Error("Expected FR to stay tracked")
+	}
+	if nextSnapshot[common.NewPeriodAndDimensionKey("2023-05", common.DimensionValues{"country": "US"})] != nil {
+		t.Error("Expected US to stay in the other bucket")
+	}
+}
+
+func TestNewDimensionTracker_SeededFromStoredHistory(t *testing.T) {
+	metric := common.MetricConfig{Dimensions: []string{"country"}, MaxDimensionValues: 2}
+	storedMetrics := common.Metrics{
+		"2023-05?country=US":          {Dimension: "country", DimensionValues: common.DimensionValues{"country": "US"}},
+		"2023-05?country=%28other%29": {Dimension: "country", DimensionValues: common.DimensionValues{"country": common.OtherDimensionValue}},
+		"2023-05":                     {Dimension: common.NoDimension},
+	}
+	tracker := newDimensionTracker(metric, storedMetrics)
+	group := dimensionGroup{dimension: "country", columns: []string{"country"}, indexes: []int{0}}
+	tracker.admit([][]string{{"country"}, {"DE"}, {"FR"}, {"FR"}}, []dimensionGroup{group}, func(row []string) bool { return true })
+
+	for country, expected := range map[string]string{"US": "US", "FR": "FR", "DE": common.OtherDimensionValue} {
+		if values := tracker.resolve(group, common.DimensionValues{"country": country}); values["country"] != expected {
+			t.Errorf("Expected %s to resolve to %s, got %s", country, expected, values["country"])
+		}
+	}
+}
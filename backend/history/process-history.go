@@ -2,224 +2,415 @@ package history
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"sort"
 	"time"
 
+	"github.com/data-drift/data-drift/aggregation"
+	"github.com/data-drift/data-drift/calendar"
 	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/period"
 	"github.com/data-drift/data-drift/reducers"
 	"github.com/data-drift/data-drift/urlgen"
-	"github.com/go-redis/redis/v8"
 	"github.com/google/go-github/v56/github"
-	"github.com/shopspring/decimal"
 )
 
-func ProcessHistory(client *github.Client, redisClient *redis.Client, repoOwner string, repoName string, metric common.MetricConfig, installationId int) (common.MetricStorageKey, error) {
-	kpiRepository := common.NewKpiRepository(redisClient)
+// metricHistoryVersion is part of the config hash so that a change in the way histories are computed triggers a full rebuild.
+const metricHistoryVersion = 4
+
+// ProcessedKPI is a KPI whose history has been stored.
+type ProcessedKPI struct {
+	Metric     common.MetricConfig
+	StorageKey common.MetricStorageKey
+	// Timestamp of the last commit processed by the previous run, the drifts committed after it are new.
+	// Zero when the history was built from scratch.
+	ResumedFrom int64
+}
+
+// kpiHistory is the history of a KPI being merged with the snapshots of its file.
+type kpiHistory struct {
+	metric           common.MetricConfig
+	configHash       string
+	metrics          common.Metrics
+	watermark        common.MetricWatermark
+	resumedFrom      int64
+	newCommits       map[common.CommitSha]bool
+	previousSnapshot metricSnapshot
+	dimensionTracker *dimensionTracker
+	calendar         calendar.Calendar
+	isAdditive       bool
+	attributionTopN  int
+	// Set when the snapshot of a new commit could not be fetched, the later commits being left to the next run
+	// so that the watermark never moves past the missing commit.
+	stoppedAt common.CommitSha
+}
+
+// ProcessHistory merges the snapshots of the metric file in the history of each KPI of the metric,
+// reading every snapshot once whatever the number of KPIs.
+func ProcessHistory(client *github.Client, kpiRepository common.MetricStore, repoOwner string, repoName string, metric common.MetricConfig, installationId int) ([]ProcessedKPI, error) {
 	reportBaseUrl := urlgen.BuildReportDiffBaseUrl(repoOwner, repoName)
 	fmt.Println(reportBaseUrl)
 	ctx := context.Background()
 
 	csvFilePath := metric.Filepath
-	dateColumnName := metric.DateColumnName
-	KPIColumnName := metric.KPIColumnName
-	metricName := metric.MetricName
 
 	fmt.Println(metric)
 	// Set the start and end dates to display the history for.
-	endDate := time.Now()
+	since, endDate, err := GetCommitWindow(metric)
+	if err != nil {
+		return nil, err
+	}
 
-	if dateColumnName == "" {
-		dateColumnName = "date"
+	var histories []*kpiHistory
+	for _, kpiMetric := range metric.ExpandKPIs() {
+		history, err := loadKPIHistory(kpiRepository, repoOwner, repoName, kpiMetric)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+
+	// The commits are listed once, from the oldest watermark, or from the start of the window when a KPI is rebuilt.
+	isIncremental := true
+	var resumeTimestamp int64
+	for _, history := range histories {
+		if history.watermark.CommitSha == "" {
+			isIncremental = false
+		} else if resumeTimestamp == 0 || history.watermark.CommitTimestamp < resumeTimestamp {
+			resumeTimestamp = history.watermark.CommitTimestamp
+		}
+	}
+	// The new commits are the descendants of the watermark, whatever their committer date, e.g. after a rebase.
+	aheadCommitsByWatermark := make(map[common.CommitSha]map[common.CommitSha]bool)
+	for _, history := range histories {
+		watermarkSha := history.watermark.CommitSha
+		if watermarkSha == "" {
+			continue
+		}
+		if _, ok := aheadCommitsByWatermark[watermarkSha]; ok {
+			continue
+		}
+		aheadCommits, err := ListCommitsAhead(ctx, client, repoOwner, repoName, watermarkSha)
+		if err != nil {
+			// the watermark commit is gone, e.g. after a force push, the commits after its date being the new ones
+			log.Println("[DATADRIFT_ERROR] compare commits from watermark", watermarkSha, err.Error())
+			aheadCommitsByWatermark[watermarkSha] = nil
+			continue
+		}
+		aheadCommitsByWatermark[watermarkSha] = make(map[common.CommitSha]bool)
+		for commitSha, committedAt := range aheadCommits {
+			aheadCommitsByWatermark[watermarkSha][commitSha] = true
+			if committedAt.Unix() < resumeTimestamp {
+				resumeTimestamp = committedAt.Unix()
+			}
+		}
+	}
+	if isIncremental && time.Unix(resumeTimestamp, 0).After(since) {
+		since = time.Unix(resumeTimestamp, 0)
 	}
 
-	// Get the commit history for the repository.
 	// Get the commit history for the file.
-	commits, _, err := client.Repositories.ListCommits(context.Background(), repoOwner, repoName, &github.CommitsListOptions{
-		Path:        csvFilePath,
-		SHA:         "",
-		Until:       endDate,
-		ListOptions: github.ListOptions{PerPage: 100},
+	commits, err := ListAllCommits(ctx, client, repoOwner, repoName, &github.CommitsListOptions{
+		Path:  csvFilePath,
+		SHA:   "",
+		Since: since,
+		Until: endDate,
 	})
 	if err != nil {
-		return "", fmt.Errorf("error getting commit history: %v", err.Error())
+		return nil, fmt.Errorf("error getting commit history: %v", err.Error())
 	}
+	// Commits are processed oldest first so that each snapshot is attributed against the previous one.
+	sortCommitsByDate(commits)
 
-	// Print the number of commits.
-	fmt.Printf("Number of commits: %d\n", len(commits))
-
-	// Group the lines of the CSV file by reporting date.
-	lineCountAndKPIByDateByVersion := make(common.Metrics)
-	for index, commit := range commits {
-		var commitMessages []common.CommitComments
-		fmt.Printf("\r Commit %d/%d", index, len(commits))
-
-		commitSha := common.CommitSha(*commit.SHA)
+	recordsByCommit := make(map[common.CommitSha][][]string)
+	getRecords := func(commitSha common.CommitSha) ([][]string, error) {
+		if records, ok := recordsByCommit[commitSha]; ok {
+			return records, nil
+		}
+		records, err := GetFileContentsForCommit(client, repoOwner, repoName, csvFilePath, string(commitSha))
+		if err != nil {
+			return nil, err
+		}
+		recordsByCommit[commitSha] = records
+		return records, nil
+	}
 
-		commitDate := commit.Commit.Committer.Date
-		commitComments := GetCommitComments(client, ctx, repoOwner, repoName, *commit.SHA)
+	for _, history := range histories {
+		for _, commit := range filterNewCommits(commits, history.watermark, history.metrics, aheadCommitsByWatermark[history.watermark.CommitSha]) {
+			history.newCommits[common.CommitSha(commit.GetSHA())] = true
+		}
+		if history.watermark.CommitSha != "" && len(history.newCommits) > 0 {
+			history.previousSnapshot = getPreviousSnapshot(getRecords, history.metric, history.dimensionTracker, history.watermark)
+		}
+		log.Printf("Number of commits of %s: %d", history.metric.MetricName, len(history.newCommits))
+	}
 
-		for _, comment := range commitComments {
-			commitMessages = append(commitMessages, common.CommitComments{CommentBody: *comment.Body, CommentAuthor: *comment.User.Login})
+	for _, commit := range commits {
+		commitSha := common.CommitSha(commit.GetSHA())
+		var pendingHistories []*kpiHistory
+		for _, history := range histories {
+			if history.newCommits[commitSha] && history.stoppedAt == "" {
+				pendingHistories = append(pendingHistories, history)
+			}
 		}
-		commitTimestamp := commitDate.Unix()
-		records, err := getFileContentsForCommit(client, repoOwner, repoName, csvFilePath, *commit.SHA)
-		if err != nil {
-			log.Printf("Error getting file contents for commit %s: %v", *commit.SHA, err.Error())
+		if len(pendingHistories) == 0 {
 			continue
 		}
 
+		records, err := getRecords(commitSha)
 		if err != nil {
-			log.Printf("Error parsing CSV file for commit %s: %v", *commit.SHA, err.Error())
+			log.Printf("[DATADRIFT_ERROR] getting file contents of commit %s, the next commits are left to the next sync: %v", commitSha, err.Error())
+			for _, history := range pendingHistories {
+				history.stoppedAt = commitSha
+			}
 			continue
 		}
-		var dateColumn int
-		var kpiColumn int
-		var defaultDateColumn int
-		var dimensionColumns []struct {
-			dimensionName   string
-			dimensionColumn int
+		delete(recordsByCommit, commitSha)
+		if duplicateKeys := helpers.FindDuplicateKeys(records, GetUniqueKey(metric)); len(duplicateKeys) > 0 {
+			log.Printf("[DATADRIFT_ERROR] data quality: %d duplicate keys in %s at commit %s, e.g. %q", len(duplicateKeys), csvFilePath, commitSha, duplicateKeys[0].Key)
 		}
+		commitMessages := getCommitMessages(client, ctx, repoOwner, repoName, commitSha)
 
-		for i, columnName := range records[0] {
-			if columnName == dateColumnName {
-				dateColumn = i
-			}
-			if columnName == "date" {
-				defaultDateColumn = i
-			}
-			if columnName == KPIColumnName {
-				kpiColumn = i
-			}
-			for _, metricDimension := range metric.Dimensions {
-				if columnName == metricDimension {
-					dimensionColumns = append(dimensionColumns, struct {
-						dimensionName   string
-						dimensionColumn int
-					}{
-						dimensionName:   metricDimension,
-						dimensionColumn: i,
-					})
-				}
+		for _, history := range pendingHistories {
+			err := history.processCommit(commit, records, commitMessages, reportBaseUrl)
+			if err != nil {
+				log.Printf("Error processing commit %s of %s: %v", commitSha, history.metric.MetricName, err.Error())
 			}
 		}
+	}
 
-		for _, record := range records[1:] { // Skip the header row.
-			for _, timegrain := range GetDefaultTimeGrains(metric.TimeGrains) {
-				var periodKey common.PeriodKey
-				dateValue := record[dateColumn]
-				if len(dateValue) > 10 {
-					dateValue = dateValue[:10]
-				}
-				periodTime, parsingError := time.Parse("2006-01-02", dateValue)
-
-				if parsingError != nil {
-					dateValue := record[defaultDateColumn]
-					if len(dateValue) > 10 {
-						dateValue = dateValue[:10]
-					}
-					periodTime, parsingError = time.Parse("2006-01-02", dateValue)
-					if parsingError != nil {
-						fmt.Println("Error with default date:", parsingError.Error())
-						continue
-					}
-				}
-
-				switch timegrain {
-				case common.Day:
-					periodKey = common.PeriodKey(periodTime.Format("2006-01-02"))
-				case common.Week:
-					_, week := periodTime.ISOWeek()
-					periodKey = common.PeriodKey(fmt.Sprintf("%d-W%02d", periodTime.Year(), week))
-				case common.Month:
-					periodKey = common.PeriodKey(periodTime.Format("2006-01"))
-				case common.Quarter:
-					periodKey = common.PeriodKey(fmt.Sprintf("%d-Q%d", periodTime.Year(), (periodTime.Month()-1)/3+1))
-				case common.Year:
-					periodKey = common.PeriodKey(periodTime.Format("2006"))
-				default:
-					fmt.Printf("Invalid time grain: %s", timegrain)
-				}
-
-				periodAndDimensionKey := common.PeriodAndDimensionKey(string(periodKey))
-				dimension := common.Dimension("none")
-				dimensionValue := common.DimensionValue(common.NoDimensionValue)
-
-				updateMetric(lineCountAndKPIByDateByVersion, periodAndDimensionKey, timegrain, periodKey, dimension, dimensionValue, record, kpiColumn, commitSha, commitTimestamp, commit, commitMessages, reportBaseUrl)
-
-				for _, metricDimension := range dimensionColumns {
-					dimension = common.Dimension(metricDimension.dimensionName)
-					dimensionValue = common.DimensionValue(record[metricDimension.dimensionColumn])
-					periodAndDimensionKey = common.PeriodAndDimensionKey(string(periodKey) + " " + string(dimensionValue))
-					updateMetric(lineCountAndKPIByDateByVersion, periodAndDimensionKey, timegrain, periodKey, dimension, dimensionValue, record, kpiColumn, commitSha, commitTimestamp, commit, commitMessages, reportBaseUrl)
-
-				}
-			}
+	var processedKPIs []ProcessedKPI
+	for _, history := range histories {
+		metricStoredFilePath, err := history.store(kpiRepository, repoOwner, repoName)
+		if err != nil {
+			return processedKPIs, err
 		}
+		processedKPIs = append(processedKPIs, ProcessedKPI{Metric: history.metric, StorageKey: metricStoredFilePath, ResumedFrom: history.resumedFrom})
+	}
+	return processedKPIs, nil
+}
 
+func loadKPIHistory(kpiRepository common.MetricStore, repoOwner string, repoName string, metric common.MetricConfig) (*kpiHistory, error) {
+	metricName := metric.MetricName
+	if err := aggregation.FromMetricConfig(metric).Validate(); err != nil {
+		return nil, fmt.Errorf("invalid aggregation of %s: %v", metricName, err.Error())
+	}
+	if metric.Filter != nil {
+		if err := metric.Filter.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid filter of %s: %v", metricName, err.Error())
+		}
+	}
+	metricCalendar, err := calendar.ForMetricConfig(metric)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar of %s: %v", metricName, err.Error())
 	}
 
-	// Print the line count for each reporting date.
-	for dateStr, lineCounts := range lineCountAndKPIByDateByVersion {
+	configHash, err := GetMetricConfigHash(metric)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing metric config: %v", err.Error())
+	}
 
+	metrics, watermark := loadStoredHistory(kpiRepository, common.NewGetMetricStorageKey(repoOwner, repoName, metricName), configHash)
+	if watermark.CommitSha != "" {
+		log.Printf("Resuming history of %s from commit %s", metricName, watermark.CommitSha)
+	} else {
+		log.Printf("Rebuilding history of %s", metricName)
+	}
+	return &kpiHistory{
+		metric:           metric,
+		configHash:       configHash,
+		metrics:          metrics,
+		watermark:        watermark,
+		resumedFrom:      watermark.CommitTimestamp,
+		newCommits:       make(map[common.CommitSha]bool),
+		dimensionTracker: newDimensionTracker(metric, metrics),
+		calendar:         metricCalendar,
+		isAdditive:       aggregation.FromMetricConfig(metric).IsAdditive(),
+		attributionTopN:  reducers.GetAttributionTopN(metric),
+	}, nil
+}
+
+// store writes the line counts and KPI values, then moves the watermark once they are stored.
+func (history *kpiHistory) store(kpiRepository common.MetricStore, repoOwner string, repoName string) (common.MetricStorageKey, error) {
+	// Print the line count for each reporting date.
+	for dateStr, lineCounts := range history.metrics {
 		var countsStr string
-		var kpiStr string
 		for _, count := range lineCounts.History {
 			countsStr += fmt.Sprintf("%d ", count.Lines)
 		}
 		fmt.Printf("Line Count %s: %s\n", dateStr, countsStr)
-		fmt.Printf("       KPI %s: %s\n", dateStr, kpiStr)
 	}
 
-	if _, err := os.Stat("dist"); os.IsNotExist(err) {
-		if err := os.Mkdir("dist", 0755); err != nil {
-			fmt.Printf("Error creating directory: %v", err.Error())
+	metricStoredFilePath, err := kpiRepository.WriteMetricKPI(repoOwner, repoName, history.metric.MetricName, history.metrics)
+	if err != nil {
+		return "", fmt.Errorf("error storing metric history of %s: %v", history.metric.MetricName, err.Error())
+	}
+	history.watermark.ConfigHash = history.configHash
+	err = kpiRepository.WriteWatermark(metricStoredFilePath, history.watermark)
+	if err != nil {
+		log.Printf("Error storing watermark of %s: %v", metricStoredFilePath, err.Error())
+	}
+	return metricStoredFilePath, nil
+}
+
+// GetMetricConfigHash identifies a metric config, a stored history built with another hash has to be rebuilt.
+// Alert rules do not change the history and are left out.
+func GetMetricConfigHash(metric common.MetricConfig) (string, error) {
+	metric.Alerts = nil
+	jsonData, err := json.Marshal(struct {
+		Version int
+		Metric  common.MetricConfig
+	}{metricHistoryVersion, metric})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(jsonData)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// loadStoredHistory returns the stored history and its watermark when they can be resumed,
+// and an empty history with an empty watermark otherwise.
+func loadStoredHistory(kpiRepository common.MetricStore, metricStorageKey common.MetricStorageKey, configHash string) (common.Metrics, common.MetricWatermark) {
+	watermark, err := kpiRepository.ReadWatermark(metricStorageKey)
+	if err != nil || watermark.CommitSha == "" {
+		return make(common.Metrics), common.MetricWatermark{}
+	}
+	if watermark.ConfigHash != configHash {
+		log.Println("Metric config changed, history will be rebuilt")
+		return make(common.Metrics), common.MetricWatermark{}
+	}
+	storedMetrics, err := kpiRepository.ReadMetricKPI(metricStorageKey)
+	if err != nil || storedMetrics == nil {
+		return make(common.Metrics), common.MetricWatermark{}
+	}
+	return storedMetrics, watermark
+}
+
+// filterNewCommits drops the commits already merged in the stored history, GitHub's since filter being inclusive.
+// The new commits are the descendants of the watermark when they are known, the commits from its date otherwise.
+func filterNewCommits(commits []*github.RepositoryCommit, watermark common.MetricWatermark, storedMetrics common.Metrics, aheadCommits map[common.CommitSha]bool) []*github.RepositoryCommit {
+	processedCommits := map[common.CommitSha]bool{watermark.CommitSha: true}
+	for _, metric := range storedMetrics {
+		for commitSha := range metric.History {
+			processedCommits[commitSha] = true
 		}
 	}
 
-	// Generate a timestamp to include in the JSON file name.
-	// Open a file to write the line counts by date by version in JSON format.
-	// Write the line counts and KPI values to the JSON file.
-	metricStoredFilePath := kpiRepository.WriteMetricKPI(repoOwner, repoName, metricName, lineCountAndKPIByDateByVersion)
-	return metricStoredFilePath, nil
+	newCommits := []*github.RepositoryCommit{}
+	for _, commit := range commits {
+		commitSha := common.CommitSha(commit.GetSHA())
+		if processedCommits[commitSha] {
+			continue
+		}
+		if aheadCommits != nil {
+			if !aheadCommits[commitSha] {
+				continue
+			}
+		} else if commit.GetCommit().GetCommitter().GetDate().Unix() < watermark.CommitTimestamp {
+			continue
+		}
+		newCommits = append(newCommits, commit)
+	}
+	return newCommits
 }
 
-func updateMetric(lineCountAndKPIByDateByVersion common.Metrics, periodAndDimensionKey common.PeriodAndDimensionKey, timegrain common.TimeGrain, periodKey common.PeriodKey, dimension common.Dimension, dimensionValue common.DimensionValue, record []string, kpiColumn int, commitSha common.CommitSha, commitTimestamp int64, commit *github.RepositoryCommit, commitMessages []common.CommitComments, reportBaseUrl string) {
+func sortCommitsByDate(commits []*github.RepositoryCommit) {
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].GetCommit().GetCommitter().GetDate().Before(commits[j].GetCommit().GetCommitter().GetDate().Time)
+	})
+}
+
+func getCommitMessages(client *github.Client, ctx context.Context, repoOwner string, repoName string, commitSha common.CommitSha) []common.CommitComments {
+	var commitMessages []common.CommitComments
+	for _, comment := range GetCommitComments(client, ctx, repoOwner, repoName, string(commitSha)) {
+		commitMessages = append(commitMessages, common.CommitComments{CommentBody: *comment.Body, CommentAuthor: *comment.User.Login})
+	}
+	return commitMessages
+}
+
+// processCommit merges the snapshot of a commit in the history and attributes its drifts against the previous snapshot.
+func (history *kpiHistory) processCommit(commit *github.RepositoryCommit, records [][]string, commitMessages []common.CommitComments, reportBaseUrl string) error {
+	commitSha := common.CommitSha(commit.GetSHA())
+	commitTimestamp := commit.GetCommit().GetCommitter().GetDate().Unix()
+
+	snapshot, err := buildMetricSnapshot(records, history.metric, history.dimensionTracker)
+	if err != nil {
+		return err
+	}
+	if skippedRows := snapshot.skippedRows(); skippedRows > 0 {
+		log.Printf("[DATADRIFT_ERROR] data quality: %d rows of %s at commit %s have an invalid value and are not aggregated", skippedRows, history.metric.Filepath, commitSha)
+	}
+	for periodAndDimensionKey, bucket := range snapshot {
+		updateMetric(history.metrics, periodAndDimensionKey, bucket, history.calendar, commitSha, commitTimestamp, commitMessages, reportBaseUrl)
+	}
+	if history.previousSnapshot != nil {
+		attributeDrifts(history.metrics, commitSha, history.previousSnapshot, snapshot, history.isAdditive, history.attributionTopN)
+	}
+	history.previousSnapshot = snapshot
+
+	if commitTimestamp >= history.watermark.CommitTimestamp {
+		history.watermark.CommitSha = commitSha
+		history.watermark.CommitTimestamp = commitTimestamp
+	}
+	return nil
+}
+
+// getPreviousSnapshot returns the snapshot of the watermark commit, against which the first new commit is attributed.
+func getPreviousSnapshot(getRecords func(commitSha common.CommitSha) ([][]string, error), metric common.MetricConfig, tracker *dimensionTracker, watermark common.MetricWatermark) metricSnapshot {
+	records, err := getRecords(watermark.CommitSha)
+	if err == nil {
+		var snapshot metricSnapshot
+		if snapshot, err = buildMetricSnapshot(records, metric, tracker); err == nil {
+			return snapshot
+		}
+	}
+	log.Printf("Error getting snapshot of commit %s: %v", watermark.CommitSha, err.Error())
+	return nil
+}
+
+func updateMetric(lineCountAndKPIByDateByVersion common.Metrics, periodAndDimensionKey common.PeriodAndDimensionKey, bucket *snapshotBucket, metricCalendar calendar.Calendar, commitSha common.CommitSha, commitTimestamp int64, commitMessages []common.CommitComments, reportBaseUrl string) {
+	periodKey, dimension, dimensionValue := bucket.period, bucket.dimension, bucket.dimensionValue
+	bucketPeriod, err := period.ParseInCalendar(periodKey, metricCalendar)
+	if err != nil {
+		log.Printf("[DATADRIFT_ERROR] invalid period %s: %v", periodKey, err.Error())
+		return
+	}
 	if lineCountAndKPIByDateByVersion[periodAndDimensionKey].History == nil {
 		lineCountAndKPIByDateByVersion[periodAndDimensionKey] = common.Metric{
-			TimeGrain:      timegrain,
-			Period:         periodKey,
-			Dimension:      dimension,
-			DimensionValue: dimensionValue,
-			History:        make(map[common.CommitSha]common.CommitData),
+			TimeGrain:       bucket.timegrain,
+			Period:          periodKey,
+			Dimension:       dimension,
+			DimensionValue:  dimensionValue,
+			DimensionValues: bucket.dimensionValues,
+			Timezone:        metricCalendar.Location().String(),
+			Calendar:        metricCalendar.Config(),
+			History:         make(map[common.CommitSha]common.CommitData),
 		}
 	}
 
-	kpiStr := record[kpiColumn]
-	kpi, _ := decimal.NewFromString(kpiStr)
-
-	newLineCount := lineCountAndKPIByDateByVersion[periodAndDimensionKey].History[commitSha].Lines + 1
-	newKPI := kpi.Add(lineCountAndKPIByDateByVersion[periodAndDimensionKey].History[commitSha].KPI)
-
-	firstDateOfPeriod, _ := reducers.LegacyGetFirstComputationDateOfPeriod(periodKey)
-	isAfterPeriod := time.Unix(commitTimestamp, 0).After(firstDateOfPeriod)
-	urlQueryStringForCommitUrl, _ := reducers.GetQueryStringFiltersForPeriod(periodKey, dimension, dimensionValue)
+	isAfterPeriod := time.Unix(commitTimestamp, 0).After(bucketPeriod.FirstComputationDate())
+	urlQueryStringForCommitUrl := reducers.GetQueryStringFiltersForPeriod(bucketPeriod, dimension, bucket.dimensionValues)
 
 	lineCountAndKPIByDateByVersion[periodAndDimensionKey].History[commitSha] = common.CommitData{
-		Lines:           newLineCount,
-		KPI:             newKPI,
+		Lines:           bucket.lines,
+		KPI:             bucket.kpi(),
 		CommitTimestamp: commitTimestamp,
-		CommitDate:      time.Unix(commitTimestamp, 0).Format("2006-01-02"),
+		CommitDate:      time.Unix(commitTimestamp, 0).In(metricCalendar.Location()).Format("2006-01-02"),
 		IsAfterPeriod:   isAfterPeriod,
 		CommitUrl:       urlgen.BuildReportDiffUrl(reportBaseUrl, string(commitSha), urlQueryStringForCommitUrl),
 		CommitComments:  commitMessages,
 	}
 }
 
-func getFileContentsForCommit(client *github.Client, owner, name, path, sha string) ([][]string, error) {
+// GetFileContentsForCommit returns the records of a CSV file at a commit, headers first.
+func GetFileContentsForCommit(client *github.Client, owner, name, path, sha string) ([][]string, error) {
 	opts := &github.RepositoryContentGetOptions{Ref: sha}
 	fileContents, _, ghresp, err := client.Repositories.GetContents(context.Background(), owner, name, path, opts)
 	if err != nil {
@@ -238,7 +429,15 @@ func getFileContentsForCommit(client *github.Client, owner, name, path, sha stri
 	csvReader := csv.NewReader(resp.Body)
 	records, err := csvReader.ReadAll()
 
-	return records, nil
+	return records, err
+}
+
+// GetUniqueKey returns the columns identifying a row of the metric file.
+func GetUniqueKey(metric common.MetricConfig) []string {
+	if len(metric.UniqueKey) == 0 {
+		return helpers.DefaultUniqueKey
+	}
+	return metric.UniqueKey
 }
 
 func GetDefaultTimeGrains(timeGrains []common.TimeGrain) []common.TimeGrain {
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/google/go-github/v56/github"
+)
+
+func newRepositoryCommit(sha string, date time.Time) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA: github.String(sha),
+		Commit: &github.Commit{
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: date}},
+		},
+	}
+}
+
+func TestFilterNewCommits(t *testing.T) {
+	watermarkDate := time.Date(2023, time.May, 2, 10, 0, 0, 0, time.UTC)
+	watermark := common.MetricWatermark{CommitSha: "b", CommitTimestamp: watermarkDate.Unix()}
+	storedMetrics := common.Metrics{
+		"2023-05": {History: common.MetricHistory{"a": {}, "b": {}, "c": {}}},
+	}
+	commits := []*github.RepositoryCommit{
+		newRepositoryCommit("e", watermarkDate.Add(2*time.Hour)),
+		newRepositoryCommit("d", watermarkDate),
+		newRepositoryCommit("c", watermarkDate),
+		newRepositoryCommit("b", watermarkDate),
+		newRepositoryCommit("a", watermarkDate.Add(-time.Hour)),
+	}
+
+	newCommits := filterNewCommits(commits, watermark, storedMetrics, nil)
+
+	if len(newCommits) != 2 || newCommits[0].GetSHA() != "e" || newCommits[1].GetSHA() != "d" {
+		t.Errorf("Expected commits [e d], but got %v", newCommits)
+	}
+}
+
+func TestFilterNewCommitsByAncestry(t *testing.T) {
+	watermarkDate := time.Date(2023, time.May, 2, 10, 0, 0, 0, time.UTC)
+	watermark := common.MetricWatermark{CommitSha: "b", CommitTimestamp: watermarkDate.Unix()}
+	storedMetrics := common.Metrics{
+		"2023-05": {History: common.MetricHistory{"a": {}, "b": {}}},
+	}
+	commits := []*github.RepositoryCommit{
+		newRepositoryCommit("e", watermarkDate.Add(2*time.Hour)),
+		// rebased on the watermark with an older committer date
+		newRepositoryCommit("r", watermarkDate.Add(-2*time.Hour)),
+		// on another branch, merged before the watermark
+		newRepositoryCommit("x", watermarkDate.Add(time.Hour)),
+		newRepositoryCommit("b", watermarkDate),
+		newRepositoryCommit("a", watermarkDate.Add(-time.Hour)),
+	}
+	aheadCommits := map[common.CommitSha]bool{"e": true, "r": true}
+
+	newCommits := filterNewCommits(commits, watermark, storedMetrics, aheadCommits)
+
+	if len(newCommits) != 2 || newCommits[0].GetSHA() != "e" || newCommits[1].GetSHA() != "r" {
+		t.Errorf("Expected commits [e r], but got %v", newCommits)
+	}
+}
+
+func TestGetMetricConfigHash(t *testing.T) {
+	metric := common.MetricConfig{MetricName: "revenue", Filepath: "revenue.csv", KPIColumnName: "amount"}
+	hash, err := GetMetricConfigHash(metric)
+	if err != nil {
+		t.Fatalf("GetMetricConfigHash returned an error: %v", err)
+	}
+	sameHash, _ := GetMetricConfigHash(metric)
+	if hash != sameHash {
+		t.Errorf("Expected the same hash for the same config, but got %s and %s", hash, sameHash)
+	}
+
+	metric.TimeGrains = []common.TimeGrain{common.Week}
+	otherHash, _ := GetMetricConfigHash(metric)
+	if hash == otherHash {
+		t.Errorf("Expected a different hash when the config changes")
+	}
+
+	metric.Alerts = []common.AlertRule{{AbsoluteDelta: 10, Notifiers: []string{"team"}}}
+	if alertHash, _ := GetMetricConfigHash(metric); alertHash != otherHash {
+		t.Errorf("Expected the alert rules to leave the hash unchanged")
+	}
+}
@@ -0,0 +1,292 @@
+package history
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/data-drift/data-drift/aggregation"
+	"github.com/data-drift/data-drift/calendar"
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/filters"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/period"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/shopspring/decimal"
+)
+
+// metricRow is a row of a snapshot counted in a period and dimension of the metric.
+type metricRow struct {
+	periodAndDimensionKey common.PeriodAndDimensionKey
+	timegrain             common.TimeGrain
+	periodKey             common.PeriodKey
+	dimension             common.Dimension
+	dimensionValue        common.DimensionValue
+	dimensionValues       common.DimensionValues
+	record                []string
+	rowKey                string
+	value                 decimal.Decimal
+	isValid               bool
+}
+
+// snapshotBucket aggregates the rows of a period and dimension, and keeps the value of each row by row key.
+type snapshotBucket struct {
+	timegrain       common.TimeGrain
+	period          common.PeriodKey
+	dimension       common.Dimension
+	dimensionValue  common.DimensionValue
+	dimensionValues common.DimensionValues
+	lines           int
+	accumulator     aggregation.Accumulator
+	values          map[string]decimal.Decimal
+}
+
+type metricSnapshot map[common.PeriodAndDimensionKey]*snapshotBucket
+
+// SnapshotDrift is the drift of a period and dimension between two snapshots of a metric file.
+type SnapshotDrift struct {
+	Period         common.PeriodKey
+	Dimension      common.Dimension
+	DimensionValue common.DimensionValue
+	Attribution    common.DriftAttribution
+}
+
+// walkMetricRows calls visit for every row of the records matching the filter, headers first, once per time grain and dimension group of the metric.
+// The combinations of values beyond the cardinality cap of the tracker are visited in the other bucket of their group.
+func walkMetricRows(records [][]string, metric common.MetricConfig, metricCalendar calendar.Calendar, aggregator aggregation.Aggregator, match filters.Matcher, tracker *dimensionTracker, visit func(row metricRow)) {
+	if len(records) == 0 {
+		return
+	}
+	dateColumnName := metric.DateColumnName
+	if dateColumnName == "" {
+		dateColumnName = "date"
+	}
+
+	var dateColumn int
+	var defaultDateColumn int
+	for i, columnName := range records[0] {
+		if columnName == dateColumnName {
+			dateColumn = i
+		}
+		if columnName == "date" {
+			defaultDateColumn = i
+		}
+	}
+	dimensionGroups := getDimensionGroups(records[0], metric)
+	tracker.admit(records, dimensionGroups, match)
+
+	getRowKey, ok := helpers.GetRowKeyFunction(records[0], GetUniqueKey(metric))
+	if !ok {
+		getRowKey = func(row []string) string { return strings.Join(row, ",") }
+	}
+
+	for _, record := range records[1:] { // Skip the header row.
+		if !match(record) {
+			continue
+		}
+		rowKey := getRowKey(record)
+		value, isValid := aggregator.RowValue(record)
+		dimensionValuesByGroup := make([]common.DimensionValues, len(dimensionGroups))
+		for i, group := range dimensionGroups {
+			dimensionValuesByGroup[i] = tracker.resolve(group, group.values(record))
+		}
+		for _, timegrain := range GetDefaultTimeGrains(metric.TimeGrains) {
+			periodTime, parsingError := helpers.ParseDateTimeInLocation(record[dateColumn], metricCalendar.Location())
+			if parsingError != nil {
+				periodTime, parsingError = helpers.ParseDateTimeInLocation(record[defaultDateColumn], metricCalendar.Location())
+				if parsingError != nil {
+					log.Println("Error with default date:", parsingError.Error())
+					continue
+				}
+			}
+
+			rowPeriod, err := period.Containing(periodTime, timegrain, metricCalendar)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			periodKey := rowPeriod.Key
+
+			visit(metricRow{
+				periodAndDimensionKey: common.NewPeriodAndDimensionKey(periodKey, nil),
+				timegrain:             timegrain,
+				periodKey:             periodKey,
+				dimension:             common.NoDimension,
+				dimensionValue:        common.NoDimensionValue,
+				record:                record,
+				rowKey:                rowKey,
+				value:                 value,
+				isValid:               isValid,
+			})
+
+			for i, group := range dimensionGroups {
+				dimensionValues := dimensionValuesByGroup[i]
+				visit(metricRow{
+					periodAndDimensionKey: common.NewPeriodAndDimensionKey(periodKey, dimensionValues),
+					timegrain:             timegrain,
+					periodKey:             periodKey,
+					dimension:             group.dimension,
+					dimensionValue:        dimensionValues.Display(group.columns),
+					dimensionValues:       dimensionValues,
+					record:                record,
+					rowKey:                rowKey,
+					value:                 value,
+					isValid:               isValid,
+				})
+			}
+		}
+	}
+}
+
+func (snapshot metricSnapshot) add(aggregator aggregation.Aggregator, row metricRow) {
+	bucket, ok := snapshot[row.periodAndDimensionKey]
+	if !ok {
+		bucket = &snapshotBucket{
+			timegrain:       row.timegrain,
+			period:          row.periodKey,
+			dimension:       row.dimension,
+			dimensionValue:  row.dimensionValue,
+			dimensionValues: row.dimensionValues,
+			accumulator:     aggregator.NewAccumulator(),
+			values:          make(map[string]decimal.Decimal),
+		}
+		snapshot[row.periodAndDimensionKey] = bucket
+	}
+	bucket.lines++
+	bucket.accumulator.Add(row.record)
+	if row.isValid {
+		// Rows sharing a key are reported as a single row.
+		bucket.values[row.rowKey] = bucket.values[row.rowKey].Add(row.value)
+	}
+}
+
+func (bucket *snapshotBucket) valuesOrEmpty() map[string]decimal.Decimal {
+	if bucket == nil {
+		return map[string]decimal.Decimal{}
+	}
+	return bucket.values
+}
+
+func (bucket *snapshotBucket) kpi() decimal.Decimal {
+	if bucket == nil {
+		return decimal.Zero
+	}
+	return bucket.accumulator.Value()
+}
+
+// skippedRows returns the number of rows whose value could not be aggregated, counted once whatever their periods and dimensions.
+func (snapshot metricSnapshot) skippedRows() int {
+	skippedByTimeGrain := make(map[common.TimeGrain]int)
+	for _, bucket := range snapshot {
+		if bucket.dimension == common.NoDimension {
+			skippedByTimeGrain[bucket.timegrain] += bucket.accumulator.Skipped()
+		}
+	}
+	skipped := 0
+	for _, count := range skippedByTimeGrain {
+		if count > skipped {
+			skipped = count
+		}
+	}
+	return skipped
+}
+
+func buildMetricSnapshot(records [][]string, metric common.MetricConfig, tracker *dimensionTracker) (metricSnapshot, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+	aggregator, err := aggregation.FromMetricConfig(metric).Bind(records[0])
+	if err != nil {
+		return nil, err
+	}
+	match, err := filters.Compile(metric.Filter, records[0])
+	if err != nil {
+		return nil, err
+	}
+	metricCalendar, err := calendar.ForMetricConfig(metric)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(metricSnapshot)
+	walkMetricRows(records, metric, metricCalendar, aggregator, match, tracker, func(row metricRow) {
+		snapshot.add(aggregator, row)
+	})
+	return snapshot, nil
+}
+
+// attributeDrift lists the rows explaining the drift of a period and dimension between two snapshots.
+// Row contributions only add up to the drift of additive aggregations, their share is left empty otherwise.
+func attributeDrift(previous *snapshotBucket, current *snapshotBucket, isAdditive bool, topN int) common.DriftAttribution {
+	attribution := reducers.ComputeDriftAttribution(previous.valuesOrEmpty(), current.valuesOrEmpty(), topN)
+	if !isAdditive {
+		attribution.Delta = current.kpi().Sub(previous.kpi())
+		for i := range attribution.TopContributors {
+			attribution.TopContributors[i].Share = 0
+		}
+	}
+	return attribution
+}
+
+// attributeDrifts stores on every period and dimension of the commit the rows explaining its drift since the previous snapshot.
+func attributeDrifts(metrics common.Metrics, commitSha common.CommitSha, previous metricSnapshot, current metricSnapshot, isAdditive bool, topN int) {
+	for periodAndDimensionKey, bucket := range current {
+		commitData, ok := metrics[periodAndDimensionKey].History[commitSha]
+		if !ok {
+			continue
+		}
+		attribution := attributeDrift(previous[periodAndDimensionKey], bucket, isAdditive, topN)
+		if !reducers.HasChanges(attribution) {
+			continue
+		}
+		commitData.Attribution = &attribution
+		metrics[periodAndDimensionKey].History[commitSha] = commitData
+	}
+}
+
+// ComputeSnapshotDrifts compares two snapshots of a metric file, headers first, and returns the periods and dimensions whose KPI drifted.
+func ComputeSnapshotDrifts(previousRecords [][]string, currentRecords [][]string, metric common.MetricConfig) ([]SnapshotDrift, error) {
+	// The current snapshot picks the tracked combinations first, the previous one being rolled up the same way.
+	tracker := newDimensionTracker(metric, nil)
+	current, err := buildMetricSnapshot(currentRecords, metric, tracker)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := buildMetricSnapshot(previousRecords, metric, tracker)
+	if err != nil {
+		previous = make(metricSnapshot)
+	}
+	isAdditive := aggregation.FromMetricConfig(metric).IsAdditive()
+	topN := reducers.GetAttributionTopN(metric)
+	buckets := make(metricSnapshot)
+	for periodAndDimensionKey, bucket := range previous {
+		buckets[periodAndDimensionKey] = bucket
+	}
+	for periodAndDimensionKey, bucket := range current {
+		buckets[periodAndDimensionKey] = bucket
+	}
+
+	drifts := []SnapshotDrift{}
+	for periodAndDimensionKey, bucket := range buckets {
+		attribution := attributeDrift(previous[periodAndDimensionKey], current[periodAndDimensionKey], isAdditive, topN)
+		if attribution.Delta.IsZero() {
+			continue
+		}
+		drifts = append(drifts, SnapshotDrift{
+			Period:         bucket.period,
+			Dimension:      bucket.dimension,
+			DimensionValue: bucket.dimensionValue,
+			Attribution:    attribution,
+		})
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Period != drifts[j].Period {
+			return drifts[i].Period < drifts[j].Period
+		}
+		if drifts[i].Dimension != drifts[j].Dimension {
+			return drifts[i].Dimension < drifts[j].Dimension
+		}
+		return drifts[i].DimensionValue < drifts[j].DimensionValue
+	})
+	return drifts, nil
+}
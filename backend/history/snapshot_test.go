@@ -0,0 +1,163 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeSnapshotDrifts(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName: "amount",
+		Dimensions:    []string{"country"},
+	}
+	previousRecords := [][]string{
+		{"unique_key", "date", "amount", "country"},
+		{"a", "2023-05-01", "10", "FR"},
+		{"b", "2023-05-02", "20", "US"},
+		{"c", "2023-06-01", "5", "FR"},
+	}
+	currentRecords := [][]string{
+		{"unique_key", "date", "amount", "country"},
+		{"a", "2023-05-01", "10", "FR"},
+		{"b", "2023-05-02", "25", "US"},
+		{"c", "2023-06-01", "5", "FR"},
+		{"d", "2023-05-03", "3", "FR"},
+	}
+
+	drifts, err := ComputeSnapshotDrifts(previousRecords, currentRecords, metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(drifts) != 3 {
+		t.Fatalf("Expected 3 drifts, but got %+v", drifts)
+	}
+	monthDrift := drifts[2]
+	if monthDrift.Period != "2023-05" || monthDrift.Dimension != "none" {
+		t.Fatalf("Expected the drift of 2023-05 without dimension last, but got %+v", monthDrift)
+	}
+	if !monthDrift.Attribution.Delta.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("Expected a drift of 8, but got %v", monthDrift.Attribution.Delta)
+	}
+	if monthDrift.Attribution.TopContributors[0].RowKey != "b" || monthDrift.Attribution.TopContributors[1].RowKey != "d" {
+		t.Errorf("Unexpected top contributors %+v", monthDrift.Attribution.TopContributors)
+	}
+	if drifts[0].DimensionValue != "FR" || !drifts[0].Attribution.Delta.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("Unexpected drift of FR %+v", drifts[0])
+	}
+}
+
+func TestComputeSnapshotDrifts_NonAdditiveAggregation(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName: "amount",
+		Aggregation:   "avg",
+	}
+	previousRecords := [][]string{
+		{"unique_key", "date", "amount"},
+		{"a", "2023-05-01", "10"},
+		{"b", "2023-05-02", "20"},
+	}
+	currentRecords := [][]string{
+		{"unique_key", "date", "amount"},
+		{"a", "2023-05-01", "10"},
+		{"b", "2023-05-02", "invalid"},
+	}
+
+	drifts, err := ComputeSnapshotDrifts(previousRecords, currentRecords, metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(drifts) != 1 || !drifts[0].Attribution.Delta.Equal(decimal.NewFromInt(-5)) {
+		t.Fatalf("Expected a drift of the average of -5, but got %+v", drifts)
+	}
+	contributor := drifts[0].Attribution.TopContributors[0]
+	if contributor.RowKey != "b" || contributor.ChangeType != common.RowRemoved || contributor.Share != 0 {
+		t.Errorf("Unexpected contributor %+v", contributor)
+	}
+}
+
+func TestBuildMetricSnapshot_Hour(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName:  "amount",
+		DateColumnName: "created_at",
+		TimeGrains:     []common.TimeGrain{common.Hour, common.Day},
+	}
+	records := [][]string{
+		{"unique_key", "created_at", "amount"},
+		{"a", "2023-05-01T09:15:00Z", "10"},
+		{"b", "2023-05-01 09:45:00", "20"},
+		{"c", "2023-05-01T10:05:00+02:00", "5"},
+	}
+	snapshot, err := buildMetricSnapshot(records, metric, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket := snapshot["2023-05-01T09"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(30)) {
+		t.Errorf("Unexpected bucket of 2023-05-01T09 %+v", bucket)
+	}
+	if bucket := snapshot["2023-05-01T08"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Expected the timestamp with an offset in 2023-05-01T08, got %+v", bucket)
+	}
+	if bucket := snapshot["2023-05-01"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(35)) {
+		t.Errorf("Unexpected bucket of 2023-05-01 %+v", bucket)
+	}
+}
+
+func TestBuildMetricSnapshot_Timezone(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName:  "amount",
+		DateColumnName: "created_at",
+		TimeGrains:     []common.TimeGrain{common.Day},
+		Timezone:       "Europe/Paris",
+	}
+	records := [][]string{
+		{"unique_key", "created_at", "amount"},
+		{"a", "2023-05-01T22:30:00Z", "10"},
+		{"b", "2023-05-01 23:30:00", "20"},
+	}
+	snapshot, err := buildMetricSnapshot(records, metric, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket := snapshot["2023-05-01"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(20)) {
+		t.Errorf("Expected the local timestamp in 2023-05-01, got %+v", bucket)
+	}
+	if bucket := snapshot["2023-05-02"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Expected the late evening UTC row in the next local day, got %+v", bucket)
+	}
+
+	metric.Timezone = "Mars/Olympus"
+	if _, err := buildMetricSnapshot(records, metric, nil); err == nil {
+		t.Error("Expected an error for an invalid timezone")
+	}
+}
+
+func TestBuildMetricSnapshot_FiscalCalendar(t *testing.T) {
+	metric := common.MetricConfig{
+		KPIColumnName: "amount",
+		TimeGrains:    []common.TimeGrain{common.Week, common.Quarter, common.Year},
+		Calendar:      &common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday"},
+	}
+	records := [][]string{
+		{"unique_key", "date", "amount"},
+		{"a", "2023-01-01", "10"},
+		{"b", "2023-01-31", "20"},
+		{"c", "2023-02-01", "5"},
+	}
+	snapshot, err := buildMetricSnapshot(records, metric, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket := snapshot["2023-Q4"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(30)) {
+		t.Errorf("Unexpected bucket of the last fiscal quarter %+v", bucket)
+	}
+	if bucket := snapshot["2024"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Expected February in the next fiscal year, got %+v", bucket)
+	}
+	if bucket := snapshot["2023-W01"]; bucket == nil || !bucket.kpi().Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Expected Sunday 1 January in the first week, got %+v", bucket)
+	}
+}
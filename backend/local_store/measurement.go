@@ -26,7 +26,12 @@ func MeasurementsHandler(c *gin.Context) {
 	store := c.Param("store")
 	table := c.Param("table")
 	queryDate := c.Query("date")
-	date, err := time.Parse("2006-01-02", queryDate)
+	location, err := getStoreLocation(store)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.ParseInLocation("2006-01-02", queryDate, location)
 	if err != nil {
 		c.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
 		return
@@ -39,23 +44,33 @@ func MeasurementsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"Measurements": measurements})
 }
 
+// Maximum number of rows of each kind of change returned in a structured diff
+const maxDiffRows = 10000
+
+// MeasurementHandler returns the patch of a measurement, or the structured diff with ?format=structured.
 func MeasurementHandler(c *gin.Context) {
 	store := c.Param("store")
 	table := c.Param("table")
 	measurementId := c.Param("measurementId")
 
-	commit, patch, headers, err := getMeasurement(store, table, measurementId)
+	commit, diff, headers, err := getMeasurement(store, table, measurementId)
 
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	location, err := getStoreLocation(store)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	measureDate := commit.Author.When.In(location)
 
 	measurementMetaData := common.MeasurementMetaData{
 
 		MeasurementTimestamp: commit.Author.When.Unix(),
-		MeasurementDate:      commit.Author.When.Format("2006-01-02"),
-		MeasurementDateTime:  commit.Author.When.Format("2006-01-02 15:04:05"),
+		MeasurementDate:      measureDate.Format("2006-01-02"),
+		MeasurementDateTime:  measureDate.Format("2006-01-02 15:04:05"),
 		MeasurementId:        measurementId,
 		MeasurementComments: []common.CommitComments{
 			{
@@ -65,9 +80,29 @@ func MeasurementHandler(c *gin.Context) {
 		},
 	}
 
+	if c.Query("format") == "structured" {
+		diff.Truncate(maxDiffRows)
+		c.JSON(http.StatusOK, gin.H{"MeasurementMetaData": measurementMetaData, "Diff": diff, "Headers": headers, "DataQualityError": errorMessage(diff.DuplicateKeyError())})
+		return
+	}
+
+	patch := diff.UnifiedPatch()
+	lines := strings.Split(patch, "\n")
+	if len(lines) > 10000 {
+		lines = lines[:10000]
+	}
+	patch = strings.Join(lines, "\n")
+
 	c.JSON(http.StatusOK, gin.H{"MeasurementMetaData": measurementMetaData, "Patch": patch, "Headers": headers})
 }
 
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
 func getMeasurements(store string, table string, date time.Time) ([]CommitInfo, error) {
 	repoDir, err := getStoreDir(store)
 	filePath := table + ".csv"
@@ -96,7 +131,8 @@ func getMeasurements(store string, table string, date time.Time) ([]CommitInfo,
 	commits := []CommitInfo{}
 
 	err = cIter.ForEach(func(c *object.Commit) error {
-		if c.Author.When.Format("2006-01-02") == date.Format("2006-01-02") {
+		// The commits are matched on the day of the date in its location
+		if c.Author.When.In(date.Location()).Format("2006-01-02") == date.Format("2006-01-02") {
 			commits = append(commits, CommitInfo{
 				Message: c.Message,
 				Date:    c.Author.When,
@@ -112,48 +148,34 @@ func getMeasurements(store string, table string, date time.Time) ([]CommitInfo,
 	return commits, nil
 }
 
-func getMeasurement(store string, table string, commitSha string) (*object.Commit, string, []string, error) {
+func getMeasurement(store string, table string, commitSha string) (*object.Commit, helpers.CsvDiff, []string, error) {
 	repoDir, err := getStoreDir(store)
 	log.Println("repoDir", repoDir)
 	filePath := table + ".csv"
 	if err != nil {
 		print("Error getting store directory")
-		return nil, "", nil, err
+		return nil, helpers.CsvDiff{}, nil, err
 	}
 	repo, err := git.PlainOpen(repoDir)
 	if err != nil {
 		print("Error opening repo")
-		return nil, "", nil, err
+		return nil, helpers.CsvDiff{}, nil, err
 	}
 
-	if err != nil {
-		print("Error getting HEAD reference")
-		return nil, "", nil, err
-	}
 	hash := plumbing.NewHash(commitSha)
 
 	commit, err := repo.CommitObject(hash)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, helpers.CsvDiff{}, nil, err
 	}
 	file, err := commit.File(filePath)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("file not present in measurement")
-	}
-
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	if err != nil {
-		// Handle the error. For example:
-		log.Fatalf("Failed to get file: %v", err)
+		return nil, helpers.CsvDiff{}, nil, fmt.Errorf("file not present in measurement")
 	}
 
 	currentContent, err := file.Contents()
 	if err != nil {
-		// Handle the error. For example:
-		log.Fatalf("Failed to read file contents: %v", err)
+		return nil, helpers.CsvDiff{}, nil, fmt.Errorf("failed to read file contents: %v", err)
 	}
 
 	// Convert the content to an io.Reader. Assuming content is a string:
@@ -161,26 +183,24 @@ func getMeasurement(store string, table string, commitSha string) (*object.Commi
 
 	// read the headers from the CSV file
 	currentRecord, err := reader.ReadAll()
-	headers := currentRecord[0]
 	if err != nil {
-		// Handle the error. For example:
-		log.Fatalf("Failed to read CSV headers: %v", err)
+		return nil, helpers.CsvDiff{}, nil, fmt.Errorf("failed to read CSV: %v", err)
+	}
+	if len(currentRecord) == 0 {
+		return nil, helpers.CsvDiff{}, nil, fmt.Errorf("no records in CSV file")
 	}
+	headers := currentRecord[0]
 
 	// Retrieve the commit's parents
 	previousRecords := getPreviousRecord(commit, filePath)
 
-	patch, err := helpers.GenerateCsvPatch(currentRecord, previousRecords)
+	tableConfig, err := getTableConfig(store, table)
 	if err != nil {
-		log.Fatalf("Failed to generate patch: %v", err)
+		return nil, helpers.CsvDiff{}, nil, err
 	}
-	lines := strings.Split(patch, "\n")
-	if len(lines) > 10000 {
-		lines = lines[:10000]
-	}
-	patch = strings.Join(lines, "\n")
+	diff := helpers.ComputeCsvDiff(currentRecord, previousRecords, tableConfig.UniqueKey...)
 
-	return commit, patch, headers, nil
+	return commit, diff, headers, nil
 }
 
 func getPreviousRecord(commit *object.Commit, filePath string) [][]string {
@@ -3,14 +3,17 @@ package local_store
 import (
 	"bufio"
 	"encoding/csv"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/data-drift/data-drift/aggregation"
 	"github.com/data-drift/data-drift/common"
-	"github.com/data-drift/data-drift/reducers"
-	"github.com/shopspring/decimal"
+	"github.com/data-drift/data-drift/filters"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/period"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-git/go-git/v5"
@@ -18,8 +21,26 @@ import (
 )
 
 type MetricRequest struct {
-	Period common.PeriodKey `json:"period"`
-	Metric string           `json:"metric"`
+	Period            common.PeriodKey     `json:"period"`
+	Metric            string               `json:"metric"`
+	Aggregation       aggregation.Function `json:"aggregation"`
+	NumeratorColumn   string               `json:"numeratorColumn"`
+	DenominatorColumn string               `json:"denominatorColumn"`
+	Filter            *filters.Filter      `json:"filter"`
+}
+
+// getAggregation returns the aggregation of the requested metric, a sum of its column by default.
+func (req MetricRequest) getAggregation() aggregation.Definition {
+	function := req.Aggregation
+	if function == "" {
+		function = aggregation.Sum
+	}
+	return aggregation.Definition{
+		Function:          function,
+		Column:            req.Metric,
+		NumeratorColumn:   req.NumeratorColumn,
+		DenominatorColumn: req.DenominatorColumn,
+	}
 }
 
 func MetricHandler(c *gin.Context) {
@@ -31,10 +52,23 @@ func MetricHandler(c *gin.Context) {
 		return
 	}
 
-	metricName := req.Metric
 	periodKey := req.Period
-	metricHistory, err := getMetricHistory(store, table, metricName, periodKey)
-	print(err) // TODO handle error
+	metricAggregation := req.getAggregation()
+	if err := metricAggregation.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Filter != nil {
+		if err := req.Filter.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	metricHistory, err := getMetricHistory(store, table, metricAggregation, req.Filter, periodKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"store":         store,
 		"table":         table,
@@ -43,7 +77,7 @@ func MetricHandler(c *gin.Context) {
 	})
 }
 
-func getMetricHistory(store string, table string, metricName string, periodKey common.PeriodKey) ([]common.MetricMeasurement, error) {
+func getMetricHistory(store string, table string, metricAggregation aggregation.Definition, filter *filters.Filter, periodKey common.PeriodKey) ([]common.MetricMeasurement, error) {
 	repoDir, err := getStoreDir(store)
 	filePath := table + ".csv"
 	if err != nil {
@@ -55,6 +89,15 @@ func getMetricHistory(store string, table string, metricName string, periodKey c
 		log.Println("Error opening repo")
 		return nil, err
 	}
+	storeCalendar, err := getStoreCalendar(store)
+	if err != nil {
+		log.Println("Error getting store calendar")
+		return nil, err
+	}
+	measuredPeriod, err := period.ParseInCalendar(periodKey, storeCalendar)
+	if err != nil {
+		return nil, err
+	}
 
 	if err != nil {
 		log.Println("Error getting HEAD reference")
@@ -68,6 +111,8 @@ func getMetricHistory(store string, table string, metricName string, periodKey c
 	}
 
 	var history []common.MetricMeasurement
+	// The error of the last skipped snapshot, returned when none of the snapshots can be measured
+	var snapshotErr error
 
 	err = commitIter.ForEach(func(commit *object.Commit) error {
 		file, _ := commit.File(filePath)
@@ -88,7 +133,13 @@ func getMetricHistory(store string, table string, metricName string, periodKey c
 				CommentBody:   commit.Message,
 			},
 		}
-		metricEvent := computeMetricHistoryEvent(records, metricName, periodKey, time.Unix(commit.Author.When.Unix(), 0), commitComments, commit.Hash.String())
+		metricEvent, err := computeMetricHistoryEvent(records, metricAggregation, filter, measuredPeriod, time.Unix(commit.Author.When.Unix(), 0), commitComments, commit.Hash.String())
+		if err != nil {
+			// e.g. an older snapshot without a column of the aggregation or of the filter
+			log.Println("[DATADRIFT_ERROR] skipping snapshot", commit.Hash.String(), err.Error())
+			snapshotErr = err
+			return nil
+		}
 
 		if len(history) == 0 {
 			history = append(history, metricEvent)
@@ -101,7 +152,10 @@ func getMetricHistory(store string, table string, metricName string, periodKey c
 		return nil
 	})
 	if err != nil {
-		print(err)
+		return nil, err
+	}
+	if len(history) == 0 && snapshotErr != nil {
+		return nil, snapshotErr
 	}
 	return history, nil
 }
@@ -115,14 +169,25 @@ func findMetricIndex(headers []string, metricName string) int {
 	return -1
 }
 
-func computeMetricHistoryEvent(records [][]string, metricName string, periodKey common.PeriodKey, measureDate time.Time, commitComments []common.CommitComments, measurementId string) common.MetricMeasurement {
+func computeMetricHistoryEvent(records [][]string, metricAggregation aggregation.Definition, filter *filters.Filter, measuredPeriod period.Period, measureDate time.Time, commitComments []common.CommitComments, measurementId string) (common.MetricMeasurement, error) {
+	if len(records) == 0 {
+		return common.MetricMeasurement{}, fmt.Errorf("empty snapshot %s", measurementId)
+	}
 	headers := records[0]
-	metricIndex := findMetricIndex(headers, metricName)
+	aggregator, err := metricAggregation.Bind(headers)
+	if err != nil {
+		return common.MetricMeasurement{}, err
+	}
+	match, err := filters.Compile(filter, headers)
+	if err != nil {
+		return common.MetricMeasurement{}, err
+	}
 	dateIndex := findMetricIndex(headers, "date")
 
-	firstDateOfPeriod, firstDateOfNextPeriod, _, _ := reducers.GetStartDateEndDateAndNextPeriod(periodKey)
+	location := measuredPeriod.Calendar().Location()
+	measureDate = measureDate.In(location)
 
-	isAfterPeriod := measureDate.After(firstDateOfNextPeriod) || measureDate.Equal(firstDateOfNextPeriod)
+	isAfterPeriod := !measureDate.Before(measuredPeriod.End)
 
 	measurementMetaData := common.MeasurementMetaData{
 
@@ -138,28 +203,27 @@ func computeMetricHistoryEvent(records [][]string, metricName string, periodKey
 		MeasurementMetaData:  measurementMetaData,
 	}
 
+	accumulator := aggregator.NewAccumulator()
 	for i := 1; i < len(records); i++ {
 		record := records[i]
+		if !match(record) {
+			continue
+		}
 		dateStr := record[dateIndex]
-		recordDate, err := time.Parse("2006-01-02", dateStr)
+		recordDate, err := helpers.ParseDateTimeInLocation(dateStr, location)
 		if err != nil {
 			print(err)
 		}
-		if recordDate.After(firstDateOfNextPeriod) || recordDate.Equal(firstDateOfNextPeriod) {
-			continue
-		}
-		if recordDate.Before(firstDateOfPeriod) {
+		if !measuredPeriod.Contains(recordDate) {
 			continue
 		}
-		kpiStr := record[metricIndex]
-		kpi, _ := decimal.NewFromString(kpiStr)
-
-		newLineCount := historyEvent.LineCount + 1
-		newKPI := kpi.Add(historyEvent.Metric)
-		historyEvent.Metric = newKPI
-		historyEvent.LineCount = newLineCount
-
+		accumulator.Add(record)
+		historyEvent.LineCount = historyEvent.LineCount + 1
+	}
+	historyEvent.Metric = accumulator.Value()
+	if accumulator.Skipped() > 0 {
+		log.Printf("[DATADRIFT_ERROR] data quality: %d rows of measurement %s have an invalid value and are not aggregated", accumulator.Skipped(), measurementId)
 	}
 
-	return historyEvent
+	return historyEvent, nil
 }
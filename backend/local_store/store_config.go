@@ -0,0 +1,157 @@
+package local_store
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/data-drift/data-drift/calendar"
+	"github.com/data-drift/data-drift/common"
+	"github.com/gin-gonic/gin"
+)
+
+// StoreConfig holds the settings of a store and of its tables.
+// It is kept next to the store directory so that it is not part of the measurements.
+type StoreConfig struct {
+	// IANA timezone in which the measurements are bucketed into periods, UTC by default
+	Timezone string                 `json:"timezone,omitempty"`
+	Calendar *common.CalendarConfig `json:"calendar,omitempty"`
+	Tables   map[string]TableConfig `json:"tables"`
+}
+
+type TableConfig struct {
+	// Columns identifying a row of the table, unique_key by default
+	UniqueKey []string `json:"uniqueKey,omitempty"`
+}
+
+func getStoreConfigPath(store string) (string, error) {
+	repoDir, err := getStoreDir(store)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(repoDir) + ".config.json", nil
+}
+
+func getStoreConfig(store string) (StoreConfig, error) {
+	config := StoreConfig{Tables: map[string]TableConfig{}}
+	configPath, err := getStoreConfigPath(store)
+	if err != nil {
+		return config, err
+	}
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, err
+	}
+	if config.Tables == nil {
+		config.Tables = map[string]TableConfig{}
+	}
+	return config, nil
+}
+
+func saveStoreConfig(store string, config StoreConfig) error {
+	configPath, err := getStoreConfigPath(store)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, content, 0644)
+}
+
+func getTableConfig(store string, table string) (TableConfig, error) {
+	config, err := getStoreConfig(store)
+	if err != nil {
+		return TableConfig{}, err
+	}
+	return config.Tables[table], nil
+}
+
+func getStoreLocation(store string) (*time.Location, error) {
+	config, err := getStoreConfig(store)
+	if err != nil {
+		return nil, err
+	}
+	return common.LoadLocation(config.Timezone)
+}
+
+// getStoreCalendar returns the calendar in which the measurements of a store are bucketed into periods.
+func getStoreCalendar(store string) (calendar.Calendar, error) {
+	config, err := getStoreConfig(store)
+	if err != nil {
+		return calendar.Standard(), err
+	}
+	location, err := common.LoadLocation(config.Timezone)
+	if err != nil {
+		return calendar.Standard(), err
+	}
+	return calendar.New(config.Calendar, location)
+}
+
+func StoreConfigHandler(c *gin.Context) {
+	store := c.Param("store")
+	var storeSettings struct {
+		Timezone string                 `json:"timezone"`
+		Calendar *common.CalendarConfig `json:"calendar"`
+	}
+	if err := c.BindJSON(&storeSettings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	location, err := common.LoadLocation(storeSettings.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := calendar.New(storeSettings.Calendar, location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := getStoreConfig(store)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	config.Timezone = storeSettings.Timezone
+	config.Calendar = storeSettings.Calendar
+	if err := saveStoreConfig(store, config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"store": store, "timezone": config.Timezone, "calendar": config.Calendar})
+}
+
+func TableConfigHandler(c *gin.Context) {
+	store := c.Param("store")
+	table := c.Param("table")
+	var tableConfig TableConfig
+	if err := c.BindJSON(&tableConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := getStoreConfig(store)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	config.Tables[table] = tableConfig
+	if err := saveStoreConfig(store, config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"store": store, "table": table, "tableConfig": tableConfig})
+}
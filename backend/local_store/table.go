@@ -128,7 +128,14 @@ func StoreTableHandler(c *gin.Context) {
 func TableHandler(c *gin.Context) {
 	store := c.Param("store")
 	table := c.Param("table")
-	tableColumns, err := getListOfColumnsFromTable(store, table)
+	tableConfig, err := getTableConfig(store, table)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	tableColumns, err := getListOfColumnsFromTable(store, table, tableConfig.UniqueKey)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err,
@@ -147,12 +154,13 @@ func TableHandler(c *gin.Context) {
 		"store":        store,
 		"table":        table,
 		"tableColumns": tableColumns,
+		"tableConfig":  tableConfig,
 		"commits":      commits,
 	})
 
 }
 
-func getListOfColumnsFromTable(store string, table string) ([]string, error) {
+func getListOfColumnsFromTable(store string, table string, uniqueKey []string) ([]string, error) {
 	repoDir, err := getStoreDir(store)
 	if err != nil {
 		fmt.Println("Error getting store directory:", err)
@@ -175,8 +183,12 @@ func getListOfColumnsFromTable(store string, table string) ([]string, error) {
 
 	var columns []string
 
+	keyColumns := map[string]bool{"unique_key": true}
+	for _, keyColumn := range uniqueKey {
+		keyColumns[keyColumn] = true
+	}
 	for _, header := range headers {
-		if header != "date" && header != "unique_key" {
+		if header != "date" && !keyColumns[header] {
 			columns = append(columns, header)
 		}
 	}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"flag"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -12,6 +13,8 @@ import (
 	"github.com/data-drift/data-drift/github"
 	"github.com/data-drift/data-drift/local_store"
 	"github.com/data-drift/data-drift/metrics"
+	"github.com/data-drift/data-drift/reports"
+	"github.com/data-drift/data-drift/urlgen"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -35,6 +38,13 @@ func main() {
 		return
 	}
 
+	if err := urlgen.ValidateApiUrl(); err != nil {
+		panic(err.Error())
+	}
+	if urlgen.ApiUrl() == "" {
+		log.Println("[DATADRIFT_WARNING] DATADRIFT_API_URL is not set, the reports will not link to the charts of this server")
+	}
+
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
 		panic("DATABASE_URL is not set")
@@ -45,22 +55,20 @@ func main() {
 		panic("failed to connect database")
 	}
 
-	db.AutoMigrate(&github.GithubConnection{})
+	db.AutoMigrate(&github.GithubConnection{}, &github.WebhookJob{}, &github.RejectedWebhookDelivery{}, &reports.SlackThread{})
 
 	GithubService := github.NewGithubService(db)
 
-	redisClient, err := common.GetRedisClient()
+	KpiRepository, err := common.NewMetricStoreFromEnv(db)
 	if err != nil {
-		panic("failed to connect redis")
+		panic("failed to create metric store: " + err.Error())
 	}
 
-	KpiRepository := common.NewKpiRepository(redisClient)
-
 	metricsService := metrics.NewMetricService(KpiRepository)
 
 	port := defaultIfEmpty(os.Getenv("PORT"), "8080")
 
-	go github.ProcessWebhooks(redisClient)
+	go GithubService.ProcessWebhookJobs(KpiRepository)
 
 	router := gin.New()
 
@@ -70,6 +78,7 @@ func main() {
 	// config.AllowOrigins = []string{"http://localhost:5173"}
 	config.AllowHeaders = append(config.AllowHeaders, "Installation-Id")
 	config.AllowHeaders = append(config.AllowHeaders, "Authorization")
+	config.ExposeHeaders = append(config.ExposeHeaders, "X-Next-Page", "X-Duplicate-Keys")
 	router.Use(cors.New(config))
 
 	router.Use(gin.Logger())
@@ -78,19 +87,38 @@ func main() {
 	router.GET("/ghhealth/:installation-id", github.HealthCheckInstallation)
 
 	router.POST("webhooks/github", GithubService.HandleWebhook)
+	router.GET("jobs", github.AdminGuard, GithubService.JobsHandler)
+	router.GET("admin/rejected-deliveries", github.AdminGuard, GithubService.RejectedDeliveriesHandler)
+	router.PUT("admin/connections/:installation-id/webhook-secret", github.AdminGuard, GithubService.UpdateWebhookSecretHandler)
 	router.GET("gh/:owner/:repo/commit/:commit-sha", GithubService.GithubClientGuard, github.GetCommitDiff)
 	router.GET("gh/:owner/:repo/compare/:base-commit-sha/:head-commit-sha", GithubService.GithubClientGuard, github.CompareCommit)
 	router.GET("gh/:owner/:repo/compare-between-date", GithubService.GithubClientGuard, github.CompareCommitBetweenDates)
 	router.GET("gh/:owner/:repo/commits", GithubService.GithubClientGuard, github.GetCommitList)
 	router.GET("gh/:owner/:repo/metrics/:metric-name/cohorts/:timegrain", GithubService.GithubClientGuard, metricsService.GetMetricCohort)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/cohorts/:timegrain/maturity", GithubService.GithubClientGuard, metricsService.GetMetricCohortMaturity)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/cohorts/:timegrain/chart.svg", GithubService.GithubClientGuard, metricsService.GetMetricCohortChart)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/cohorts/:timegrain/chart.png", GithubService.GithubClientGuard, metricsService.GetMetricCohortChart)
 	router.GET("gh/:owner/:repo/metrics/:metric-name/reports", GithubService.GithubClientGuard, metricsService.GetMetricReport)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/reports/:period", GithubService.GithubClientGuard, metricsService.GetMetricPeriodReport)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/reports/:period/chart.svg", GithubService.GithubClientGuard, metricsService.GetMetricReportChart)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/reports/:period/chart.png", GithubService.GithubClientGuard, metricsService.GetMetricReportChart)
+	router.GET("gh/:owner/:repo/metrics/:metric-name/periods/:period/decomposition", GithubService.GithubClientGuard, metricsService.GetMetricPeriodDecomposition)
 	router.GET("config/:owner/:repo", GithubService.GithubClientGuard, github.GetConfigHandler)
 
 	router.GET("metrics/:metric-name/cohorts/:timegrain", metricsService.GetMetricCohort)
+	router.GET("metrics/:metric-name/cohorts/:timegrain/maturity", metricsService.GetMetricCohortMaturity)
+	router.GET("metrics/:metric-name/cohorts/:timegrain/chart.svg", metricsService.GetMetricCohortChart)
+	router.GET("metrics/:metric-name/cohorts/:timegrain/chart.png", metricsService.GetMetricCohortChart)
 	router.GET("metrics/:metric-name/reports", metricsService.GetMetricReport)
+	router.GET("metrics/:metric-name/reports/:period", metricsService.GetMetricPeriodReport)
+	router.GET("metrics/:metric-name/reports/:period/chart.svg", metricsService.GetMetricReportChart)
+	router.GET("metrics/:metric-name/reports/:period/chart.png", metricsService.GetMetricReportChart)
+	router.GET("metrics/:metric-name/periods/:period/decomposition", metricsService.GetMetricPeriodDecomposition)
+	router.PUT("stores/:store/config", local_store.StoreConfigHandler)
 	router.GET("stores/:store/tables", local_store.TablesHandler)
 	router.GET("stores/:store/tables/:table", local_store.TableHandler)
 	router.POST("stores/:store/tables/:table", local_store.StoreTableHandler)
+	router.PUT("stores/:store/tables/:table/config", local_store.TableConfigHandler)
 	router.POST("stores/:store/tables/:table/metrics", local_store.MetricHandler)
 	router.GET("stores/:store/tables/:table/measurements", local_store.MeasurementsHandler)
 	router.GET("stores/:store/tables/:table/measurements/:measurementId", local_store.MeasurementHandler)
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/data-drift/data-drift/charts"
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetricCohortChart renders the cohorts of a time grain as the chart.svg or chart.png of the request path,
+// the cohorts being selected like for GetMetricCohort, every cohort of the range by default.
+func (h *MetricService) GetMetricCohortChart(c *gin.Context) {
+	timeGrain := c.Param("timegrain")
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseCohortsQuery(c, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.DimensionFilter, err = query.DimensionFilter.ResolveLegacyValue(metricHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cohorts := reducers.QueryCohorts(metricHistory, common.TimeGrain(timeGrain), query)
+	if isPNGChart(c) {
+		content, err := charts.CohortChartPNG(cohorts)
+		writeChart(c, content, err)
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", []byte(charts.CohortChart(cohorts)))
+}
+
+// GetMetricReportChart renders the waterfall of the drifts of a period as the chart.svg or chart.png of the request path,
+// the dimension values of the KPI being given like for the reports.
+func (h *MetricService) GetMetricReportChart(c *gin.Context) {
+	report, metric, ok := h.getKPIReport(c)
+	if !ok {
+		return
+	}
+
+	steps := charts.WaterfallSteps(report, metric.GetLocation())
+	if isPNGChart(c) {
+		content, err := charts.WaterfallPNG(report.KPIName, steps)
+		writeChart(c, content, err)
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", []byte(charts.Waterfall(report.KPIName, steps)))
+}
+
+func isPNGChart(c *gin.Context) bool {
+	return strings.HasSuffix(c.Request.URL.Path, ".png")
+}
+
+func writeChart(c *gin.Context, content []byte, err error) {
+	if err != nil {
+		log.Println("[DATADRIFT_ERROR] render chart", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", content)
+}
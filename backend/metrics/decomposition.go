@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetricPeriodDecomposition splits the delta of the total of a period between the ?from= and ?to= commits,
+// its first and latest measurements by default, into the contributions of the values of each dimension group.
+func (h *MetricService) GetMetricPeriodDecomposition(c *gin.Context) {
+	periodKey := common.PeriodKey(c.Param("period"))
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return
+	}
+
+	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	decomposition, err := reducers.DecomposePeriod(metricHistory, periodKey, common.CommitSha(c.Query("from")), common.CommitSha(c.Query("to")))
+	if errors.Is(err, reducers.ErrPeriodNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, decomposition)
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMaturityHorizonDays = 90
+	maxMaturityHorizonDays     = 730
+	defaultMaturityTolerance   = 1.0
+)
+
+// GetMetricCohortMaturity aggregates the cohorts of a time grain: their drift by day after their first computation,
+// the days they take to stay within ?tolerance=% (1 by default) of their value after ?horizon= days (90 by default),
+// and the expected final value of the younger cohorts, the cohorts being selected like for GetMetricCohort.
+func (h *MetricService) GetMetricCohortMaturity(c *gin.Context) {
+	timeGrain := c.Param("timegrain")
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return
+	}
+
+	options, err := parseMaturityOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := parseCohortsQuery(c, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.DimensionFilter, err = query.DimensionFilter.ResolveLegacyValue(metricHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// every cohort of the range is compared, whatever the page
+	query.Offset, query.Limit = 0, 0
+	cohorts := reducers.QueryCohorts(metricHistory, common.TimeGrain(timeGrain), query)
+	c.JSON(http.StatusOK, reducers.GetCohortMaturity(cohorts, options))
+}
+
+func parseMaturityOptions(c *gin.Context) (reducers.MaturityOptions, error) {
+	options := reducers.MaturityOptions{HorizonDays: defaultMaturityHorizonDays, Tolerance: defaultMaturityTolerance, Now: time.Now()}
+	if horizon := c.Query("horizon"); horizon != "" {
+		horizonDays, err := strconv.Atoi(horizon)
+		if err != nil || horizonDays < 1 || horizonDays > maxMaturityHorizonDays {
+			return options, fmt.Errorf("horizon must be a number of days between 1 and %d", maxMaturityHorizonDays)
+		}
+		options.HorizonDays = horizonDays
+	}
+	if tolerance := c.Query("tolerance"); tolerance != "" {
+		value, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil || value <= 0 {
+			return options, fmt.Errorf("tolerance must be a positive percentage")
+		}
+		options.Tolerance = value
+	}
+	return options, nil
+}
@@ -3,6 +3,8 @@ package metrics
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/data-drift/data-drift/common"
 	"github.com/data-drift/data-drift/github"
@@ -11,33 +13,28 @@ import (
 )
 
 type MetricService struct {
-	KpiRepository *common.KpiRepository
+	KpiRepository common.MetricStore
 }
 
-func NewMetricService(kpiRepository *common.KpiRepository) *MetricService {
+func NewMetricService(kpiRepository common.MetricStore) *MetricService {
 	return &MetricService{KpiRepository: kpiRepository}
 }
 
+// GetMetricCohort returns the cohorts of a time grain, of a dimension value with ?dimension=&dimensionValue=,
+// of the periods starting between ?from= and ?to= dates, and a page of them with ?limit= and ?offset=,
+// the first defaultCohortsLimit cohorts by default.
 func (h *MetricService) GetMetricCohort(c *gin.Context) {
 
-	InstallationId := c.Request.Header.Get("Installation-Id")
-	metricName := c.Param("metric-name")
 	timeGrain := c.Param("timegrain")
-	var filepath common.MetricStorageKey
-	if InstallationId == "" {
-		githubConnectionValue, exists := c.Get("github_connection")
-		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "GitHub client not found"})
-			return
-		}
-		githubConnection, ok := githubConnectionValue.(github.GithubConnection)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid GitHub client"})
-			return
-		}
-		filepath = common.NewGetMetricStorageKey(githubConnection.Owner, githubConnection.Repository, metricName)
-	} else {
-		filepath = common.LegacyGetMetricStorageKey(InstallationId, metricName)
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseCohortsQuery(c, defaultCohortsLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
@@ -45,32 +42,28 @@ func (h *MetricService) GetMetricCohort(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	query.DimensionFilter, err = query.DimensionFilter.ResolveLegacyValue(metricHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	response := GetReportData(metricHistory, common.TimeGrain(timeGrain))
+	response := reducers.QueryCohorts(metricHistory, common.TimeGrain(timeGrain), query)
 
 	c.JSON(http.StatusOK, response)
 }
 
 func (h *MetricService) GetMetricReport(c *gin.Context) {
 
-	InstallationId := c.Request.Header.Get("Installation-Id")
-	metricName := c.Param("metric-name")
-	var filepath common.MetricStorageKey
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return
+	}
 
-	if InstallationId == "" {
-		githubConnectionValue, exists := c.Get("github_connection")
-		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "GitHub client not found"})
-			return
-		}
-		githubConnection, ok := githubConnectionValue.(github.GithubConnection)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid GitHub client"})
-			return
-		}
-		filepath = common.NewGetMetricStorageKey(githubConnection.Owner, githubConnection.Repository, metricName)
-	} else {
-		filepath = common.LegacyGetMetricStorageKey(InstallationId, metricName)
+	dimensionFilter, err := common.ParseDimensionFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
@@ -78,41 +71,84 @@ func (h *MetricService) GetMetricReport(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	dimensionFilter, err = dimensionFilter.ResolveLegacyValue(metricHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !dimensionFilter.IsEmpty() {
+		filteredHistory := make(common.Metrics)
+		for periodAndDimensionKey, metric := range metricHistory {
+			if dimensionFilter.Matches(metric) {
+				filteredHistory[periodAndDimensionKey] = metric
+			}
+		}
+		metricHistory = filteredHistory
+	}
 
 	c.JSON(http.StatusOK, metricHistory)
 }
 
-func GetReportData(metrics common.Metrics, timeGrain common.TimeGrain) map[string]interface{} {
-	cohortDates := []string{}
-	reportData := make(map[int64]map[string]interface{})
-	cohortsMetricsMetadata := make(map[string]reducers.MetricMetadata)
-
-	for cohortName, cohort := range metrics {
-		if cohort.TimeGrain == timeGrain && cohort.Dimension == "none" {
-			cohortDates = append(cohortDates, string(cohortName))
-			metricMetadata, err := reducers.GetMetadataOfMetric(cohort)
-			if err != nil {
-				fmt.Println(err.Error())
-				continue
-			}
-			cohortsMetricsMetadata[string(cohortName)] = metricMetadata
-			for _, commit := range metricMetadata.RelativeHistory {
-				timestampStr := commit.ComputationTimetamp
-				if _, ok := reportData[timestampStr]; !ok {
-					reportData[timestampStr] = make(map[string]interface{})
-				}
-				reportData[timestampStr][string(cohortName)] = commit.RelativeValue
-			}
+// getMetricStorageKey returns the storage key of the metric of the request, from its legacy Installation-Id header
+// or from the GitHub connection of its repository, responding with an error when there is none.
+func getMetricStorageKey(c *gin.Context) (common.MetricStorageKey, bool) {
+	metricName := c.Param("metric-name")
+	InstallationId := c.Request.Header.Get("Installation-Id")
+	if InstallationId != "" {
+		return common.LegacyGetMetricStorageKey(InstallationId, metricName), true
+	}
+	githubConnectionValue, exists := c.Get("github_connection")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "GitHub client not found"})
+		return "", false
+	}
+	githubConnection, ok := githubConnectionValue.(github.GithubConnection)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid GitHub client"})
+		return "", false
+	}
+	return common.NewGetMetricStorageKey(githubConnection.Owner, githubConnection.Repository, metricName), true
+}
 
-		}
+const (
+	// defaultCohortsLimit is the page of cohorts returned without a ?limit=.
+	defaultCohortsLimit = 100
+	// maxCohortsLimit is the largest page of cohorts.
+	maxCohortsLimit = 500
+)
 
+// parseCohortsQuery reads the dimension filter, the date range and the page of the cohorts of a request.
+// Without a ?limit=, the page has defaultLimit cohorts, every cohort being selected when it is zero.
+func parseCohortsQuery(c *gin.Context, defaultLimit int) (reducers.CohortsQuery, error) {
+	dimensionFilter, err := common.ParseDimensionFilter(c.Request.URL.Query())
+	if err != nil {
+		return reducers.CohortsQuery{}, err
 	}
-
-	// Return the response map.
-	return map[string]interface{}{
-		"timegrain":              timeGrain,
-		"cohortDates":            cohortDates,
-		"dataIndexedByTimestamp": reportData,
-		"cohortsMetricsMetadata": cohortsMetricsMetadata,
+	if !dimensionFilter.IsEmpty() && dimensionFilter.Values == nil {
+		return reducers.CohortsQuery{}, fmt.Errorf("the cohorts of a dimension need a dimensionValue per dimension")
+	}
+	query := reducers.CohortsQuery{DimensionFilter: dimensionFilter, From: c.Query("from"), To: c.Query("to")}
+	for _, date := range []string{query.From, query.To} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return query, fmt.Errorf("invalid date %s, expected 2006-01-02", date)
+		}
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		return query, fmt.Errorf("from %s is after to %s", query.From, query.To)
+	}
+	query.Limit = defaultLimit
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxCohortsLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxCohortsLimit)
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		query.Offset, err = strconv.Atoi(offset)
+		if err != nil || query.Offset < 0 || query.Limit == 0 {
+			return query, fmt.Errorf("offset must be zero or more and come with a limit")
+		}
 	}
+	return query, nil
 }
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/github"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+func newCohortsTestContext(query string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/gh/acme/data/metrics/revenue/cohorts/month?"+query, nil)
+	c.Params = gin.Params{{Key: "metric-name", Value: "revenue"}, {Key: "timegrain", Value: "month"}}
+	c.Set("github_connection", github.GithubConnection{Owner: "acme", Repository: "data"})
+	return c, recorder
+}
+
+func TestParseCohortsQuery(t *testing.T) {
+	testCases := []struct {
+		query        string
+		defaultLimit int
+		want         reducers.CohortsQuery
+		wantErr      bool
+	}{
+		{query: "", defaultLimit: defaultCohortsLimit, want: reducers.CohortsQuery{Limit: defaultCohortsLimit}},
+		{query: "", defaultLimit: 0, want: reducers.CohortsQuery{}},
+		{query: "from=2023-01-01&to=2023-06-30&limit=10&offset=20", defaultLimit: defaultCohortsLimit, want: reducers.CohortsQuery{From: "2023-01-01", To: "2023-06-30", Limit: 10, Offset: 20}},
+		{query: "offset=100", defaultLimit: defaultCohortsLimit, want: reducers.CohortsQuery{Limit: defaultCohortsLimit, Offset: 100}},
+		{query: "offset=100", defaultLimit: 0, wantErr: true},
+		{query: "from=2023-13-01", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "from=2023-06-01&to=2023-01-01", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "limit=0", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "limit=501", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "limit=ten", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "offset=-1", defaultLimit: defaultCohortsLimit, wantErr: true},
+		{query: "dimension=country", defaultLimit: defaultCohortsLimit, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		c, _ := newCohortsTestContext(tc.query)
+		got, err := parseCohortsQuery(c, tc.defaultLimit)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseCohortsQuery(%q): expected an error, got %+v", tc.query, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCohortsQuery(%q): unexpected error %v", tc.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseCohortsQuery(%q) = %+v; want %+v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestGetMetricCohort(t *testing.T) {
+	store, err := common.NewFileMetricStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := make(common.Metrics)
+	for _, periodKey := range []common.PeriodKey{"2023-01", "2023-02", "2023-03"} {
+		computedAt := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC).Unix()
+		metrics[common.PeriodAndDimensionKey(periodKey)] = common.Metric{TimeGrain: common.Month, Period: periodKey, Dimension: common.NoDimension, DimensionValue: common.NoDimensionValue, History: common.MetricHistory{
+			common.CommitSha("a" + periodKey): {KPI: decimal.NewFromInt(1000), CommitTimestamp: computedAt},
+		}}
+	}
+	if _, err := store.WriteMetricKPI("acme", "data", "revenue", metrics); err != nil {
+		t.Fatal(err)
+	}
+	service := NewMetricService(store)
+
+	testCases := []struct {
+		query          string
+		wantStatus     int
+		wantDates      []string
+		wantNextOffset *int
+	}{
+		{query: "", wantStatus: http.StatusOK, wantDates: []string{"2023-01", "2023-02", "2023-03"}},
+		{query: "limit=2", wantStatus: http.StatusOK, wantDates: []string{"2023-01", "2023-02"}, wantNextOffset: func() *int { next := 2; return &next }()},
+		{query: "limit=2&offset=2", wantStatus: http.StatusOK, wantDates: []string{"2023-03"}},
+		{query: "from=2023-02-01&to=2023-02-28", wantStatus: http.StatusOK, wantDates: []string{"2023-02"}},
+		{query: "limit=1000", wantStatus: http.StatusBadRequest},
+		{query: "to=yesterday", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		c, recorder := newCohortsTestContext(tc.query)
+		service.GetMetricCohort(c)
+		if recorder.Code != tc.wantStatus {
+			t.Errorf("GetMetricCohort(%q): expected status %d, got %d %s", tc.query, tc.wantStatus, recorder.Code, recorder.Body.String())
+			continue
+		}
+		if tc.wantStatus != http.StatusOK {
+			continue
+		}
+		var response reducers.CohortsData
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(response.CohortDates, tc.wantDates) {
+			t.Errorf("GetMetricCohort(%q): expected cohorts %v, got %v", tc.query, tc.wantDates, response.CohortDates)
+		}
+		if response.Page == nil || !reflect.DeepEqual(response.Page.NextOffset, tc.wantNextOffset) {
+			t.Errorf("GetMetricCohort(%q): unexpected page %+v", tc.query, response.Page)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/github"
+	"github.com/data-drift/data-drift/period"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetricPeriodReport returns the report of a period, its drifts being scored against the drifts of the previous periods,
+// the dimension values of the KPI being given like for the reports.
+func (h *MetricService) GetMetricPeriodReport(c *gin.Context) {
+	report, _, ok := h.getKPIReport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// getKPIReport builds the report of the period of the request path, responding with an error when there is none.
+func (h *MetricService) getKPIReport(c *gin.Context) (common.KPIReport, common.Metric, bool) {
+	metricName := c.Param("metric-name")
+	periodKey := common.PeriodKey(c.Param("period"))
+	filepath, ok := getMetricStorageKey(c)
+	if !ok {
+		return common.KPIReport{}, common.Metric{}, false
+	}
+
+	dimensionFilter, err := common.ParseDimensionFilter(c.Request.URL.Query())
+	if err == nil && !dimensionFilter.IsEmpty() && dimensionFilter.Values == nil {
+		err = fmt.Errorf("the report of a dimension needs a dimensionValue per dimension")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return common.KPIReport{}, common.Metric{}, false
+	}
+
+	metricHistory, err := h.KpiRepository.ReadMetricKPI(filepath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return common.KPIReport{}, common.Metric{}, false
+	}
+	dimensionFilter, err = dimensionFilter.ResolveLegacyValue(metricHistory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return common.KPIReport{}, common.Metric{}, false
+	}
+
+	for _, metric := range metricHistory {
+		if metric.Period != periodKey || !dimensionFilter.Matches(metric) {
+			continue
+		}
+		metricPeriod, err := period.ForMetric(metric)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return common.KPIReport{}, common.Metric{}, false
+		}
+		kpiName := metricName + " " + string(metric.Period)
+		if metric.Dimension != common.NoDimension {
+			kpiName += " " + string(metric.DimensionValue)
+		}
+		// the report links to the repository of the GitHub connection, unknown for the legacy Installation-Id header
+		var ownerName, repoName string
+		githubConnectionValue, _ := c.Get("github_connection")
+		if githubConnection, ok := githubConnectionValue.(github.GithubConnection); ok {
+			ownerName, repoName = githubConnection.Owner, githubConnection.Repository
+		}
+		report := reducers.OrderDataAndCreateChart(kpiName, metric.Period, metricPeriod, metric.History, metric.DimensionValue, metric.DimensionQuery(), ownerName, repoName, metricName)
+		if report.KPIName == "" {
+			break
+		}
+		reducers.NewDriftScorer(metricHistory).ScoreEvents(metric, report.Events)
+		return report, metric, true
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("no report of %s for period %s", metricName, periodKey)})
+	return common.KPIReport{}, common.Metric{}, false
+}
@@ -0,0 +1,89 @@
+package period
+
+import (
+	"time"
+
+	"github.com/data-drift/data-drift/calendar"
+	"github.com/data-drift/data-drift/common"
+)
+
+// Period is a period of a time grain of a calendar, from its start included to its end excluded.
+type Period struct {
+	Grain    common.TimeGrain
+	Key      common.PeriodKey
+	Start    time.Time
+	End      time.Time
+	calendar calendar.Calendar
+}
+
+// GrainOf returns the time grain of a period key, whatever its calendar.
+func GrainOf(key common.PeriodKey) (common.TimeGrain, error) {
+	return calendar.TimeGrain(key)
+}
+
+// Parse returns the period of a key of the standard calendar.
+func Parse(key common.PeriodKey) (Period, error) {
+	return ParseInCalendar(key, calendar.Standard())
+}
+
+// ParseInCalendar returns the period of a key of a calendar.
+// Keys beyond the last week of a year are normalized, e.g. 2024-W53 is the first week of 2025.
+func ParseInCalendar(key common.PeriodKey, periodCalendar calendar.Calendar) (Period, error) {
+	grain, err := calendar.TimeGrain(key)
+	if err != nil {
+		return Period{}, err
+	}
+	start, _, err := periodCalendar.Bounds(key)
+	if err != nil {
+		return Period{}, err
+	}
+	return Containing(start, grain, periodCalendar)
+}
+
+// ForMetric returns the period of a stored metric, in its calendar.
+func ForMetric(metric common.Metric) (Period, error) {
+	return ParseInCalendar(metric.Period, calendar.ForMetric(metric))
+}
+
+// Containing returns the period of a time grain of a calendar containing a time.
+func Containing(t time.Time, grain common.TimeGrain, periodCalendar calendar.Calendar) (Period, error) {
+	key, err := periodCalendar.PeriodKey(t, grain)
+	if err != nil {
+		return Period{}, err
+	}
+	start, end, err := periodCalendar.Bounds(key)
+	if err != nil {
+		return Period{}, err
+	}
+	return Period{Grain: grain, Key: key, Start: start, End: end, calendar: periodCalendar}, nil
+}
+
+// Calendar returns the calendar of the period.
+func (period Period) Calendar() calendar.Calendar {
+	return period.calendar
+}
+
+func (period Period) Next() Period {
+	next, _ := Containing(period.End, period.Grain, period.calendar)
+	return next
+}
+
+func (period Period) Previous() Period {
+	previous, _ := Containing(period.Start.Add(-time.Nanosecond), period.Grain, period.calendar)
+	return previous
+}
+
+func (period Period) Contains(t time.Time) bool {
+	return !t.Before(period.Start) && t.Before(period.End)
+}
+
+// FirstComputationDate is the date from which the measurements of a period are part of its history:
+// the start of hours and days, the last second of longer periods.
+func (period Period) FirstComputationDate() time.Time {
+	switch period.Grain {
+	case common.Hour, common.Day:
+		return period.Start
+	default:
+		return period.End.Add(-time.Second)
+	}
+}
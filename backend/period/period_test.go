@@ -0,0 +1,156 @@
+package period
+
+import (
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/calendar"
+	"github.com/data-drift/data-drift/common"
+)
+
+func date(year int, month time.Month, day, hour, minute, second int) time.Time {
+	return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		key                  common.PeriodKey
+		grain                common.TimeGrain
+		canonicalKey         common.PeriodKey
+		start                time.Time
+		end                  time.Time
+		next                 common.PeriodKey
+		previous             common.PeriodKey
+		firstComputationDate time.Time
+	}{
+		{"2023-05-01T14", common.Hour, "2023-05-01T14", date(2023, 5, 1, 14, 0, 0), date(2023, 5, 1, 15, 0, 0), "2023-05-01T15", "2023-05-01T13", date(2023, 5, 1, 14, 0, 0)},
+		{"2023-06-01T09", common.Hour, "2023-06-01T09", date(2023, 6, 1, 9, 0, 0), date(2023, 6, 1, 10, 0, 0), "2023-06-01T10", "2023-06-01T08", date(2023, 6, 1, 9, 0, 0)},
+		{"2023-12-31T23", common.Hour, "2023-12-31T23", date(2023, 12, 31, 23, 0, 0), date(2024, 1, 1, 0, 0, 0), "2024-01-01T00", "2023-12-31T22", date(2023, 12, 31, 23, 0, 0)},
+		{"2024-01-01T00", common.Hour, "2024-01-01T00", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 1, 1, 0, 0), "2024-01-01T01", "2023-12-31T23", date(2024, 1, 1, 0, 0, 0)},
+		{"2023-05-01", common.Day, "2023-05-01", date(2023, 5, 1, 0, 0, 0), date(2023, 5, 2, 0, 0, 0), "2023-05-02", "2023-04-30", date(2023, 5, 1, 0, 0, 0)},
+		{"2023-06-30", common.Day, "2023-06-30", date(2023, 6, 30, 0, 0, 0), date(2023, 7, 1, 0, 0, 0), "2023-07-01", "2023-06-29", date(2023, 6, 30, 0, 0, 0)},
+		{"2023-12-31", common.Day, "2023-12-31", date(2023, 12, 31, 0, 0, 0), date(2024, 1, 1, 0, 0, 0), "2024-01-01", "2023-12-30", date(2023, 12, 31, 0, 0, 0)},
+		{"2024-02-29", common.Day, "2024-02-29", date(2024, 2, 29, 0, 0, 0), date(2024, 3, 1, 0, 0, 0), "2024-03-01", "2024-02-28", date(2024, 2, 29, 0, 0, 0)},
+		{"2023-W01", common.Week, "2023-W01", date(2023, 1, 2, 0, 0, 0), date(2023, 1, 9, 0, 0, 0), "2023-W02", "2022-W52", date(2023, 1, 8, 23, 59, 59)},
+		{"2023-W09", common.Week, "2023-W09", date(2023, 2, 27, 0, 0, 0), date(2023, 3, 6, 0, 0, 0), "2023-W10", "2023-W08", date(2023, 3, 5, 23, 59, 59)},
+		{"2023-W17", common.Week, "2023-W17", date(2023, 4, 24, 0, 0, 0), date(2023, 5, 1, 0, 0, 0), "2023-W18", "2023-W16", date(2023, 4, 30, 23, 59, 59)},
+		{"2023-W52", common.Week, "2023-W52", date(2023, 12, 25, 0, 0, 0), date(2024, 1, 1, 0, 0, 0), "2024-W01", "2023-W51", date(2023, 12, 31, 23, 59, 59)},
+		// 2020 has 53 ISO weeks, and 2021-W01 starts on 4 January 2021
+		{"2020-W53", common.Week, "2020-W53", date(2020, 12, 28, 0, 0, 0), date(2021, 1, 4, 0, 0, 0), "2021-W01", "2020-W52", date(2021, 1, 3, 23, 59, 59)},
+		{"2021-W01", common.Week, "2021-W01", date(2021, 1, 4, 0, 0, 0), date(2021, 1, 11, 0, 0, 0), "2021-W02", "2020-W53", date(2021, 1, 10, 23, 59, 59)},
+		// 2024 has 52 ISO weeks, its 53rd week is the first week of 2025
+		{"2024-W53", common.Week, "2025-W01", date(2024, 12, 30, 0, 0, 0), date(2025, 1, 6, 0, 0, 0), "2025-W02", "2024-W52", date(2025, 1, 5, 23, 59, 59)},
+		{"2023-05", common.Month, "2023-05", date(2023, 5, 1, 0, 0, 0), date(2023, 6, 1, 0, 0, 0), "2023-06", "2023-04", date(2023, 5, 31, 23, 59, 59)},
+		{"2023-12", common.Month, "2023-12", date(2023, 12, 1, 0, 0, 0), date(2024, 1, 1, 0, 0, 0), "2024-01", "2023-11", date(2023, 12, 31, 23, 59, 59)},
+		{"2025-01", common.Month, "2025-01", date(2025, 1, 1, 0, 0, 0), date(2025, 2, 1, 0, 0, 0), "2025-02", "2024-12", date(2025, 1, 31, 23, 59, 59)},
+		{"2024-02", common.Month, "2024-02", date(2024, 2, 1, 0, 0, 0), date(2024, 3, 1, 0, 0, 0), "2024-03", "2024-01", date(2024, 2, 29, 23, 59, 59)},
+		{"2023-Q1", common.Quarter, "2023-Q1", date(2023, 1, 1, 0, 0, 0), date(2023, 4, 1, 0, 0, 0), "2023-Q2", "2022-Q4", date(2023, 3, 31, 23, 59, 59)},
+		{"2025-Q3", common.Quarter, "2025-Q3", date(2025, 7, 1, 0, 0, 0), date(2025, 10, 1, 0, 0, 0), "2025-Q4", "2025-Q2", date(2025, 9, 30, 23, 59, 59)},
+		{"2025-Q4", common.Quarter, "2025-Q4", date(2025, 10, 1, 0, 0, 0), date(2026, 1, 1, 0, 0, 0), "2026-Q1", "2025-Q3", date(2025, 12, 31, 23, 59, 59)},
+		{"2023", common.Year, "2023", date(2023, 1, 1, 0, 0, 0), date(2024, 1, 1, 0, 0, 0), "2024", "2022", date(2023, 12, 31, 23, 59, 59)},
+		{"2025", common.Year, "2025", date(2025, 1, 1, 0, 0, 0), date(2026, 1, 1, 0, 0, 0), "2026", "2024", date(2025, 12, 31, 23, 59, 59)},
+	}
+
+	for _, tc := range testCases {
+		period, err := Parse(tc.key)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error %v", tc.key, err)
+			continue
+		}
+		if period.Grain != tc.grain {
+			t.Errorf("Parse(%s).Grain = %s, want %s", tc.key, period.Grain, tc.grain)
+		}
+		if period.Key != tc.canonicalKey {
+			t.Errorf("Parse(%s).Key = %s, want %s", tc.key, period.Key, tc.canonicalKey)
+		}
+		if !period.Start.Equal(tc.start) || !period.End.Equal(tc.end) {
+			t.Errorf("Parse(%s) = [%v, %v), want [%v, %v)", tc.key, period.Start, period.End, tc.start, tc.end)
+		}
+		if next := period.Next(); next.Key != tc.next || !next.Start.Equal(period.End) {
+			t.Errorf("Parse(%s).Next() = %s starting %v, want %s starting %v", tc.key, next.Key, next.Start, tc.next, period.End)
+		}
+		if previous := period.Previous(); previous.Key != tc.previous || !previous.End.Equal(period.Start) {
+			t.Errorf("Parse(%s).Previous() = %s ending %v, want %s ending %v", tc.key, previous.Key, previous.End, tc.previous, period.Start)
+		}
+		if firstComputationDate := period.FirstComputationDate(); !firstComputationDate.Equal(tc.firstComputationDate) {
+			t.Errorf("Parse(%s).FirstComputationDate() = %v, want %v", tc.key, firstComputationDate, tc.firstComputationDate)
+		}
+	}
+}
+
+func TestParse_InvalidKeys(t *testing.T) {
+	for _, key := range []common.PeriodKey{"", "2023-W00", "2023-W54", "2023-Q0", "2023-Q5", "2023-13", "2023-02-30", "2023-05-01T24", "May 2023", "23-Q1"} {
+		if _, err := Parse(key); err == nil {
+			t.Errorf("Parse(%s): expected an error", key)
+		}
+	}
+}
+
+func TestContaining(t *testing.T) {
+	testCases := []struct {
+		time  time.Time
+		grain common.TimeGrain
+		key   common.PeriodKey
+	}{
+		{date(2023, 5, 1, 14, 59, 59), common.Hour, "2023-05-01T14"},
+		{date(2023, 5, 1, 23, 59, 59), common.Day, "2023-05-01"},
+		// The ISO year of a week is not always the year of its days
+		{date(2023, 1, 1, 0, 0, 0), common.Week, "2022-W52"},
+		{date(2021, 1, 3, 0, 0, 0), common.Week, "2020-W53"},
+		{date(2024, 12, 31, 0, 0, 0), common.Week, "2025-W01"},
+		{date(2023, 5, 31, 23, 59, 59), common.Month, "2023-05"},
+		{date(2023, 4, 1, 0, 0, 0), common.Quarter, "2023-Q2"},
+		{date(2023, 12, 31, 23, 59, 59), common.Year, "2023"},
+	}
+
+	for _, tc := range testCases {
+		period, err := Containing(tc.time, tc.grain, calendar.Standard())
+		if err != nil || period.Key != tc.key {
+			t.Errorf("Containing(%v, %s) = %s, %v; want %s", tc.time, tc.grain, period.Key, err, tc.key)
+		}
+		if !period.Contains(tc.time) || period.Contains(period.End) || !period.Contains(period.Start) {
+			t.Errorf("Expected %s to contain %v and its start only", period.Key, tc.time)
+		}
+	}
+}
+
+// Consecutive periods of every grain tile the time line and their keys round trip.
+func TestPeriodsAreContiguous(t *testing.T) {
+	fiscalCalendar, _ := calendar.New(&common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday"}, time.UTC)
+	retailCalendar, _ := calendar.New(&common.CalendarConfig{FiscalYearStartMonth: 2, WeekStart: "sunday", RetailPattern: "4-4-5"}, time.UTC)
+	paris, _ := time.LoadLocation("Europe/Paris")
+	parisCalendar, _ := calendar.New(nil, paris)
+
+	for _, periodCalendar := range []calendar.Calendar{calendar.Standard(), fiscalCalendar, retailCalendar, parisCalendar} {
+		for _, grain := range []common.TimeGrain{common.Day, common.Week, common.Month, common.Quarter, common.Year} {
+			period, err := Containing(date(2019, 6, 1, 0, 0, 0), grain, periodCalendar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for period.Start.Before(date(2027, 1, 1, 0, 0, 0)) {
+				next := period.Next()
+				if !next.Start.Equal(period.End) || !next.End.After(next.Start) {
+					t.Fatalf("%s is not followed by %s", period.Key, next.Key)
+				}
+				if previous := next.Previous(); previous.Key != period.Key {
+					t.Fatalf("Expected %s before %s, got %s", period.Key, next.Key, previous.Key)
+				}
+				if parsed, err := ParseInCalendar(period.Key, periodCalendar); err != nil || parsed.Key != period.Key || !parsed.Start.Equal(period.Start) {
+					t.Fatalf("Expected %s to round trip, got %s", period.Key, parsed.Key)
+				}
+				period = next
+			}
+		}
+	}
+}
+
+func TestForMetric(t *testing.T) {
+	metric := common.Metric{Period: "2024-Q1", Timezone: "Europe/Paris", Calendar: &common.CalendarConfig{FiscalYearStartMonth: 2}}
+	period, err := ForMetric(metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !period.Start.Equal(date(2023, 1, 31, 23, 0, 0)) || !period.End.Equal(date(2023, 4, 30, 22, 0, 0)) {
+		t.Errorf("Expected the first fiscal quarter of 2024 in Paris, got [%v, %v)", period.Start, period.End)
+	}
+}
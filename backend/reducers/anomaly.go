@@ -0,0 +1,124 @@
+package reducers
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+)
+
+const (
+	// minAnomalyReferenceCohorts is the number of previous periods needed to score a drift
+	minAnomalyReferenceCohorts = 5
+	unusualAnomalyScore        = 2
+	anomalousAnomalyScore      = 3.5
+	// maxAnomalyScore is the score of a drift of periods that never drifted at that age
+	maxAnomalyScore = 100
+)
+
+type cohortGroupKey struct {
+	timeGrain      common.TimeGrain
+	dimension      common.Dimension
+	dimensionValue common.DimensionValue
+}
+
+// DriftScorer scores the drifts of a period against the drifts the previous periods of the same time grain and
+// dimension value had between the same days after their first computation.
+type DriftScorer struct {
+	cohorts map[cohortGroupKey][]maturingCohort
+}
+
+func NewDriftScorer(metrics common.Metrics) DriftScorer {
+	scorer := DriftScorer{cohorts: make(map[cohortGroupKey][]maturingCohort)}
+	for _, metric := range metrics {
+		metadata, err := GetMetadataOfMetric(metric)
+		if err != nil {
+			continue
+		}
+		key := cohortGroupKey{metric.TimeGrain, metric.Dimension, metric.DimensionValue}
+		scorer.cohorts[key] = append(scorer.cohorts[key], newMaturingCohort(string(metric.Period), metadata, time.Time{}))
+	}
+	return scorer
+}
+
+// ScoreEvents sets the anomaly score of the update events of a period, leaving it empty when there are not enough previous periods.
+func (scorer DriftScorer) ScoreEvents(metric common.Metric, events []common.EventObject) {
+	var current *maturingCohort
+	group := scorer.cohorts[cohortGroupKey{metric.TimeGrain, metric.Dimension, metric.DimensionValue}]
+	for i := range group {
+		if group[i].date == string(metric.Period) {
+			current = &group[i]
+		}
+	}
+	if current == nil {
+		return
+	}
+	base := helpers.GetFloat(current.metadata.InitialValue)
+	if base == 0 {
+		base = 1
+	}
+
+	for i := 1; i < len(events); i++ {
+		event := &events[i]
+		if event.EventType != common.EventTypeUpdate {
+			continue
+		}
+		eventTime := time.Unix(event.CommitTimestamp, 0)
+		fromDay := time.Unix(events[i-1].CommitTimestamp, 0).Sub(current.metadata.FirstDate).Hours() / 24
+		toDay := eventTime.Sub(current.metadata.FirstDate).Hours() / 24
+
+		// the drifts of the previous periods over the same days, as known when the event happened
+		var referenceDrifts []float64
+		for _, cohort := range group {
+			if !cohort.metadata.FirstDate.Before(current.metadata.FirstDate) {
+				continue
+			}
+			cohort = cohort.asOf(eventTime)
+			fromValue, fromOk := cohort.valueAt(fromDay)
+			toValue, toOk := cohort.valueAt(toDay)
+			if fromOk && toOk {
+				referenceDrifts = append(referenceDrifts, toValue-fromValue)
+			}
+		}
+		if len(referenceDrifts) < minAnomalyReferenceCohorts {
+			continue
+		}
+		event.Anomaly = scoreDrift(event.Diff/math.Abs(base)*100, referenceDrifts)
+	}
+}
+
+// scoreDrift computes the robust z-score of a drift, scaling the median absolute deviation of the reference drifts
+// like a standard deviation, or their mean absolute deviation when most of them are equal.
+func scoreDrift(drift float64, referenceDrifts []float64) *common.AnomalyScore {
+	sort.Float64s(referenceDrifts)
+	median := percentile(referenceDrifts, 50)
+	deviations := make([]float64, len(referenceDrifts))
+	meanDeviation := 0.0
+	for i, referenceDrift := range referenceDrifts {
+		deviations[i] = math.Abs(referenceDrift - median)
+		meanDeviation += deviations[i] / float64(len(referenceDrifts))
+	}
+	sort.Float64s(deviations)
+	scale := 1.4826 * percentile(deviations, 50)
+	if scale == 0 {
+		scale = 1.2533 * meanDeviation
+	}
+
+	score := 0.0
+	if scale > 0 {
+		score = (drift - median) / scale
+	} else if drift != median {
+		score = math.Copysign(maxAnomalyScore, drift-median)
+	}
+	score = math.Max(-maxAnomalyScore, math.Min(maxAnomalyScore, score))
+
+	level := common.AnomalyNormal
+	if math.Abs(score) >= anomalousAnomalyScore {
+		level = common.AnomalyAnomalous
+	} else if math.Abs(score) >= unusualAnomalyScore {
+		level = common.AnomalyUnusual
+	}
+	return &common.AnomalyScore{Score: math.Round(score*100) / 100, Level: level, ReferenceCohorts: len(referenceDrifts)}
+}
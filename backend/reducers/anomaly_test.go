@@ -0,0 +1,96 @@
+package reducers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/period"
+	"github.com/shopspring/decimal"
+)
+
+// getDriftingMonth is a monthly cohort of 100 drifting by drift % 3 days after its first computation.
+func getDriftingMonth(month time.Month, drift float64) common.Metric {
+	firstComputationDate := time.Date(2023, month+1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+	return common.Metric{
+		TimeGrain:      common.Month,
+		Period:         common.PeriodKey(fmt.Sprintf("2023-%02d", month)),
+		Dimension:      common.NoDimension,
+		DimensionValue: common.NoDimensionValue,
+		History: common.MetricHistory{
+			"initial": {KPI: decimal.NewFromInt(100), CommitTimestamp: firstComputationDate.AddDate(0, 0, 1).Unix()},
+			"drift":   {KPI: decimal.NewFromFloat(100 + drift), CommitTimestamp: firstComputationDate.AddDate(0, 0, 3).Unix()},
+		},
+	}
+}
+
+func TestDriftScorer(t *testing.T) {
+	metrics := common.Metrics{}
+	drifts := []float64{1.0, 1.2, 0.8, 1.1, 0.9, 1.4, 20, 1.05}
+	for i, drift := range drifts {
+		metric := getDriftingMonth(time.Month(i+1), drift)
+		metrics[common.PeriodAndDimensionKey(metric.Period)] = metric
+	}
+	scorer := NewDriftScorer(metrics)
+
+	testCases := []struct {
+		period        common.PeriodKey
+		expectedLevel common.AnomalyLevel
+	}{
+		// not enough previous periods
+		{"2023-03", ""},
+		{"2023-05", ""},
+		// 1.4% after 1, 1.2, 0.8, 1.1 and 0.9%
+		{"2023-06", common.AnomalyUnusual},
+		{"2023-07", common.AnomalyAnomalous},
+		{"2023-08", common.AnomalyNormal},
+	}
+	for _, tc := range testCases {
+		metric := metrics[common.PeriodAndDimensionKey(tc.period)]
+		metricPeriod, err := period.ForMetric(metric)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		report := OrderDataAndCreateChart("revenue", metric.Period, metricPeriod, metric.History, metric.DimensionValue, nil, "", "", "revenue")
+		scorer.ScoreEvents(metric, report.Events)
+
+		if len(report.Events) != 2 || report.Events[0].Anomaly != nil {
+			t.Fatalf("%s: expected an unscored creation and a drift, got %+v", tc.period, report.Events)
+		}
+		anomaly := report.Events[1].Anomaly
+		if tc.expectedLevel == "" {
+			if anomaly != nil {
+				t.Errorf("%s: expected no score, got %+v", tc.period, anomaly)
+			}
+			continue
+		}
+		if anomaly == nil || anomaly.Level != tc.expectedLevel {
+			t.Errorf("%s: expected a %s drift, got %+v", tc.period, tc.expectedLevel, anomaly)
+		}
+	}
+}
+
+func TestScoreDrift(t *testing.T) {
+	testCases := []struct {
+		drift           float64
+		referenceDrifts []float64
+		expectedScore   float64
+		expectedLevel   common.AnomalyLevel
+	}{
+		// median 1, median absolute deviation 0.1
+		{1.1, []float64{1.0, 1.2, 0.8, 1.1, 0.9}, 0.67, common.AnomalyNormal},
+		{1.4, []float64{1.0, 1.2, 0.8, 1.1, 0.9}, 2.7, common.AnomalyUnusual},
+		{0.4, []float64{1.0, 1.2, 0.8, 1.1, 0.9}, -4.05, common.AnomalyAnomalous},
+		// most periods never drifted, the mean absolute deviation being 0.2
+		{1, []float64{0, 0, 0, 0, 1}, 3.99, common.AnomalyAnomalous},
+		{0, []float64{0, 0, 0, 0, 0}, 0, common.AnomalyNormal},
+		{-0.5, []float64{0, 0, 0, 0, 0}, -maxAnomalyScore, common.AnomalyAnomalous},
+	}
+	for _, tc := range testCases {
+		score := scoreDrift(tc.drift, tc.referenceDrifts)
+		if score.Score != tc.expectedScore || score.Level != tc.expectedLevel || score.ReferenceCohorts != len(tc.referenceDrifts) {
+			t.Errorf("scoreDrift(%v, %v) = %+v, want a %s score of %v", tc.drift, tc.referenceDrifts, score, tc.expectedLevel, tc.expectedScore)
+		}
+	}
+}
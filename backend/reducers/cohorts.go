@@ -0,0 +1,112 @@
+package reducers
+
+import (
+	"log"
+	"sort"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/period"
+	"github.com/shopspring/decimal"
+)
+
+// CohortsData are the cohorts of a time grain: the relative value of each cohort at each commit, and the metadata of each cohort.
+type CohortsData struct {
+	TimeGrain              common.TimeGrain                     `json:"timegrain"`
+	CohortDates            []string                             `json:"cohortDates"`
+	DataIndexedByTimestamp map[int64]map[string]decimal.Decimal `json:"dataIndexedByTimestamp"`
+	CohortsMetricsMetadata map[string]MetricMetadata            `json:"cohortsMetricsMetadata"`
+	Page                   *CohortsPage                         `json:"page,omitempty"`
+}
+
+// CohortsQuery selects the cohorts of a time grain.
+type CohortsQuery struct {
+	DimensionFilter common.DimensionFilter
+	// First and last days of the periods, as 2006-01-02 in the timezone of the metric, unbounded when empty
+	From string
+	To   string
+	// Page of the cohorts sorted by period, every cohort being returned when Limit is zero
+	Offset int
+	Limit  int
+}
+
+// CohortsPage locates a page among the cohorts selected by a query, NextOffset being nil on the last page.
+type CohortsPage struct {
+	Offset     int  `json:"offset"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	NextOffset *int `json:"nextOffset"`
+}
+
+// GetReportData returns the cohorts of a time grain for the metrics selected by the dimension filter, indexed by period.
+func GetReportData(metrics common.Metrics, timeGrain common.TimeGrain, dimensionFilter common.DimensionFilter) CohortsData {
+	return QueryCohorts(metrics, timeGrain, CohortsQuery{DimensionFilter: dimensionFilter})
+}
+
+// QueryCohorts returns the cohorts of a time grain selected by the query, indexed by period.
+func QueryCohorts(metrics common.Metrics, timeGrain common.TimeGrain, query CohortsQuery) CohortsData {
+	var selectedCohorts []common.Metric
+	for _, cohort := range metrics {
+		if cohort.TimeGrain != timeGrain || !query.DimensionFilter.Matches(cohort) {
+			continue
+		}
+		if query.From != "" || query.To != "" {
+			cohortPeriod, err := period.ForMetric(cohort)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			startDate := cohortPeriod.Start.Format("2006-01-02")
+			if (query.From != "" && startDate < query.From) || (query.To != "" && startDate > query.To) {
+				continue
+			}
+		}
+		selectedCohorts = append(selectedCohorts, cohort)
+	}
+	sort.Slice(selectedCohorts, func(i, j int) bool { return selectedCohorts[i].Period < selectedCohorts[j].Period })
+
+	var page *CohortsPage
+	if query.Limit > 0 {
+		page = &CohortsPage{Offset: query.Offset, Limit: query.Limit, Total: len(selectedCohorts)}
+		start := query.Offset
+		if start > len(selectedCohorts) {
+			start = len(selectedCohorts)
+		}
+		end := start + query.Limit
+		if end < len(selectedCohorts) {
+			page.NextOffset = &end
+		} else {
+			end = len(selectedCohorts)
+		}
+		selectedCohorts = selectedCohorts[start:end]
+	}
+
+	cohortDates := []string{}
+	reportData := make(map[int64]map[string]decimal.Decimal)
+	cohortsMetricsMetadata := make(map[string]MetricMetadata)
+
+	for _, cohort := range selectedCohorts {
+		cohortName := cohort.Period
+		cohortDates = append(cohortDates, string(cohortName))
+		metricMetadata, err := GetMetadataOfMetric(cohort)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		cohortsMetricsMetadata[string(cohortName)] = metricMetadata
+		for _, commit := range metricMetadata.RelativeHistory {
+			timestampStr := commit.ComputationTimetamp
+			if _, ok := reportData[timestampStr]; !ok {
+				reportData[timestampStr] = make(map[string]decimal.Decimal)
+			}
+			reportData[timestampStr][string(cohortName)] = commit.RelativeValue
+		}
+	}
+
+	return CohortsData{
+		TimeGrain:              timeGrain,
+		CohortDates:            cohortDates,
+		DataIndexedByTimestamp: reportData,
+		CohortsMetricsMetadata: cohortsMetricsMetadata,
+		Page:                   page,
+	}
+}
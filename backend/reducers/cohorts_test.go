@@ -0,0 +1,72 @@
+package reducers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+func getDailyCohorts(days int) common.Metrics {
+	metrics := common.Metrics{}
+	for day := 1; day <= days; day++ {
+		start := time.Date(2023, 5, day, 0, 0, 0, 0, time.UTC)
+		for _, dimensionValue := range []string{"", "FR"} {
+			metric := common.Metric{
+				TimeGrain:      common.Day,
+				Period:         common.PeriodKey(start.Format("2006-01-02")),
+				Dimension:      common.NoDimension,
+				DimensionValue: common.NoDimensionValue,
+				History: common.MetricHistory{
+					common.CommitSha(fmt.Sprintf("sha-%d", day)): {KPI: decimal.NewFromInt(100), CommitTimestamp: start.Add(time.Hour).Unix()},
+				},
+			}
+			key := common.NewPeriodAndDimensionKey(metric.Period, nil)
+			if dimensionValue != "" {
+				metric.Dimension = "country"
+				metric.DimensionValues = common.DimensionValues{"country": dimensionValue}
+				metric.DimensionValue = common.DimensionValue(dimensionValue)
+				key = common.NewPeriodAndDimensionKey(metric.Period, metric.DimensionValues)
+			}
+			metrics[key] = metric
+		}
+	}
+	return metrics
+}
+
+func TestQueryCohorts(t *testing.T) {
+	metrics := getDailyCohorts(10)
+	france := common.DimensionFilter{Columns: []string{"country"}, Values: common.DimensionValues{"country": "FR"}}
+	nextOffset := func(offset int) *int { return &offset }
+
+	testCases := []struct {
+		name          string
+		query         CohortsQuery
+		expectedDates []string
+		expectedPage  *CohortsPage
+	}{
+		{"every cohort", CohortsQuery{}, []string{"2023-05-01", "2023-05-02", "2023-05-03", "2023-05-04", "2023-05-05", "2023-05-06", "2023-05-07", "2023-05-08", "2023-05-09", "2023-05-10"}, nil},
+		{"date range", CohortsQuery{From: "2023-05-03", To: "2023-05-05"}, []string{"2023-05-03", "2023-05-04", "2023-05-05"}, nil},
+		{"dimension value from a date", CohortsQuery{DimensionFilter: france, From: "2023-05-09"}, []string{"2023-05-09", "2023-05-10"}, nil},
+		{"first page", CohortsQuery{Limit: 4}, []string{"2023-05-01", "2023-05-02", "2023-05-03", "2023-05-04"}, &CohortsPage{Offset: 0, Limit: 4, Total: 10, NextOffset: nextOffset(4)}},
+		{"last page", CohortsQuery{Offset: 8, Limit: 4}, []string{"2023-05-09", "2023-05-10"}, &CohortsPage{Offset: 8, Limit: 4, Total: 10}},
+		{"page of a range", CohortsQuery{To: "2023-05-06", Offset: 3, Limit: 3}, []string{"2023-05-04", "2023-05-05", "2023-05-06"}, &CohortsPage{Offset: 3, Limit: 3, Total: 6}},
+		{"beyond the last page", CohortsQuery{Offset: 20, Limit: 4}, []string{}, &CohortsPage{Offset: 20, Limit: 4, Total: 10}},
+	}
+
+	for _, tc := range testCases {
+		cohorts := QueryCohorts(metrics, common.Day, tc.query)
+		if !reflect.DeepEqual(cohorts.CohortDates, tc.expectedDates) {
+			t.Errorf("%s: expected the cohorts %v, got %v", tc.name, tc.expectedDates, cohorts.CohortDates)
+		}
+		if !reflect.DeepEqual(cohorts.Page, tc.expectedPage) {
+			t.Errorf("%s: expected the page %+v, got %+v", tc.name, tc.expectedPage, cohorts.Page)
+		}
+		if len(cohorts.CohortsMetricsMetadata) != len(tc.expectedDates) || len(cohorts.DataIndexedByTimestamp) != len(tc.expectedDates) {
+			t.Errorf("%s: expected the data of the selected cohorts only, got %+v", tc.name, cohorts)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package reducers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+// ErrPeriodNotFound is returned when the metric has no total for the period.
+var ErrPeriodNotFound = errors.New("period not found")
+
+// decompositionTolerance is the residual under which the contributions are considered to add up to the total delta.
+var decompositionTolerance = decimal.New(1, -6)
+
+// Measurement is the value of the total of a period at a commit.
+type Measurement struct {
+	CommitSha       common.CommitSha `json:"commitSha"`
+	CommitTimestamp int64            `json:"commitTimestamp"`
+	Value           decimal.Decimal  `json:"value"`
+}
+
+// PeriodDecomposition splits the delta of the total of a period between two commits into the deltas of the values of each dimension group.
+type PeriodDecomposition struct {
+	Period     common.PeriodKey         `json:"period"`
+	From       Measurement              `json:"from"`
+	To         Measurement              `json:"to"`
+	Delta      decimal.Decimal          `json:"delta"`
+	Dimensions []DimensionDecomposition `json:"dimensions"`
+}
+
+// DimensionDecomposition is the contribution of each value of a dimension group to the total delta, the largest first.
+// The contributions add up to the total delta for sums and counts, but not for averages, ratios or medians.
+type DimensionDecomposition struct {
+	Dimension     common.Dimension        `json:"dimension"`
+	Contributions []DimensionContribution `json:"contributions"`
+	Sum           decimal.Decimal         `json:"sum"`
+	// Total delta not explained by the contributions
+	Residual     decimal.Decimal `json:"residual"`
+	IsConsistent bool            `json:"isConsistent"`
+}
+
+// DimensionContribution is the delta of a value of a dimension group, a value missing at a commit counting as zero.
+type DimensionContribution struct {
+	DimensionValue  common.DimensionValue  `json:"dimensionValue"`
+	DimensionValues common.DimensionValues `json:"dimensionValues"`
+	From            decimal.Decimal        `json:"from"`
+	To              decimal.Decimal        `json:"to"`
+	Delta           decimal.Decimal        `json:"delta"`
+	// Part of the total delta, zero when the total did not change
+	Share float64 `json:"share"`
+}
+
+// DecomposePeriod splits the delta of the total of a period between the commits from and to,
+// the first and the latest measurements of the total being used when they are empty.
+func DecomposePeriod(metrics common.Metrics, periodKey common.PeriodKey, from common.CommitSha, to common.CommitSha) (PeriodDecomposition, error) {
+	var total common.Metric
+	found := false
+	for _, metric := range metrics {
+		if metric.Period == periodKey && metric.Dimension == common.NoDimension {
+			total, found = metric, true
+		}
+	}
+	if !found {
+		return PeriodDecomposition{}, ErrPeriodNotFound
+	}
+	fromMeasurement, err := getMeasurement(total, from, true)
+	if err != nil {
+		return PeriodDecomposition{}, err
+	}
+	toMeasurement, err := getMeasurement(total, to, false)
+	if err != nil {
+		return PeriodDecomposition{}, err
+	}
+
+	decomposition := PeriodDecomposition{
+		Period:     periodKey,
+		From:       fromMeasurement,
+		To:         toMeasurement,
+		Delta:      toMeasurement.Value.Sub(fromMeasurement.Value),
+		Dimensions: []DimensionDecomposition{},
+	}
+	contributionsByDimension := make(map[common.Dimension][]DimensionContribution)
+	for _, metric := range metrics {
+		if metric.Period != periodKey || metric.Dimension == common.NoDimension {
+			continue
+		}
+		fromValue := metric.History[fromMeasurement.CommitSha].KPI
+		toValue := metric.History[toMeasurement.CommitSha].KPI
+		contribution := DimensionContribution{
+			DimensionValue:  metric.DimensionValue,
+			DimensionValues: metric.DimensionValues,
+			From:            fromValue,
+			To:              toValue,
+			Delta:           toValue.Sub(fromValue),
+		}
+		if !decomposition.Delta.IsZero() {
+			contribution.Share, _ = contribution.Delta.Div(decomposition.Delta).Float64()
+		}
+		contributionsByDimension[metric.Dimension] = append(contributionsByDimension[metric.Dimension], contribution)
+	}
+
+	for dimension, contributions := range contributionsByDimension {
+		sort.Slice(contributions, func(i, j int) bool {
+			if cmp := contributions[i].Delta.Abs().Cmp(contributions[j].Delta.Abs()); cmp != 0 {
+				return cmp > 0
+			}
+			return contributions[i].DimensionValue < contributions[j].DimensionValue
+		})
+		sum := decimal.Zero
+		for _, contribution := range contributions {
+			sum = sum.Add(contribution.Delta)
+		}
+		residual := decomposition.Delta.Sub(sum)
+		decomposition.Dimensions = append(decomposition.Dimensions, DimensionDecomposition{
+			Dimension:     dimension,
+			Contributions: contributions,
+			Sum:           sum,
+			Residual:      residual,
+			IsConsistent:  residual.Abs().LessThanOrEqual(decompositionTolerance),
+		})
+	}
+	sort.Slice(decomposition.Dimensions, func(i, j int) bool {
+		return decomposition.Dimensions[i].Dimension < decomposition.Dimensions[j].Dimension
+	})
+	return decomposition, nil
+}
+
+// getMeasurement returns the measurement of the total at a commit, its first or latest one when the commit is empty.
+func getMeasurement(total common.Metric, commitSha common.CommitSha, first bool) (Measurement, error) {
+	if commitSha != "" {
+		commitData, ok := total.History[commitSha]
+		if !ok {
+			return Measurement{}, fmt.Errorf("no measurement of period %s at commit %s", total.Period, commitSha)
+		}
+		return Measurement{CommitSha: commitSha, CommitTimestamp: commitData.CommitTimestamp, Value: commitData.KPI}, nil
+	}
+
+	if len(total.History) == 0 {
+		return Measurement{}, fmt.Errorf("no measurement of period %s", total.Period)
+	}
+	shas := make([]common.CommitSha, 0, len(total.History))
+	for sha := range total.History {
+		shas = append(shas, sha)
+	}
+	sort.Slice(shas, func(i, j int) bool {
+		if total.History[shas[i]].CommitTimestamp != total.History[shas[j]].CommitTimestamp {
+			return total.History[shas[i]].CommitTimestamp < total.History[shas[j]].CommitTimestamp
+		}
+		return shas[i] < shas[j]
+	})
+	sha := shas[len(shas)-1]
+	if first {
+		sha = shas[0]
+	}
+	return Measurement{CommitSha: sha, CommitTimestamp: total.History[sha].CommitTimestamp, Value: total.History[sha].KPI}, nil
+}
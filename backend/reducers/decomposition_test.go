@@ -0,0 +1,81 @@
+package reducers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+func getDecompositionMetric(period common.PeriodKey, dimension common.Dimension, values common.DimensionValues, kpiByCommit map[common.CommitSha]int64) common.Metric {
+	history := common.MetricHistory{}
+	timestamps := map[common.CommitSha]int64{"a": 1685664000, "b": 1685750400, "c": 1685836800}
+	for sha, kpi := range kpiByCommit {
+		history[sha] = common.CommitData{KPI: decimal.NewFromInt(kpi), CommitTimestamp: timestamps[sha]}
+	}
+	return common.Metric{
+		TimeGrain:       common.Month,
+		Period:          period,
+		Dimension:       dimension,
+		DimensionValue:  values.Display(dimension.Columns()),
+		DimensionValues: values,
+		History:         history,
+	}
+}
+
+func TestDecomposePeriod(t *testing.T) {
+	metrics := common.Metrics{
+		"2023-05":                getDecompositionMetric("2023-05", common.NoDimension, nil, map[common.CommitSha]int64{"a": 1000, "b": 1100, "c": 1080}),
+		"2023-05?country=FR":     getDecompositionMetric("2023-05", "country", common.DimensionValues{"country": "FR"}, map[common.CommitSha]int64{"a": 600, "b": 590, "c": 570}),
+		"2023-05?country=DE":     getDecompositionMetric("2023-05", "country", common.DimensionValues{"country": "DE"}, map[common.CommitSha]int64{"a": 400, "b": 400, "c": 400}),
+		"2023-05?country=ES":     getDecompositionMetric("2023-05", "country", common.DimensionValues{"country": "ES"}, map[common.CommitSha]int64{"b": 110, "c": 110}),
+		"2023-05?channel=ads":    getDecompositionMetric("2023-05", "channel", common.DimensionValues{"channel": "ads"}, map[common.CommitSha]int64{"a": 500, "b": 520, "c": 520}),
+		"2023-05?channel=direct": getDecompositionMetric("2023-05", "channel", common.DimensionValues{"channel": "direct"}, map[common.CommitSha]int64{"a": 500, "b": 500, "c": 500}),
+		"2023-04":                getDecompositionMetric("2023-04", common.NoDimension, nil, map[common.CommitSha]int64{"a": 10}),
+	}
+
+	decomposition, err := DecomposePeriod(metrics, "2023-05", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if decomposition.From.CommitSha != "a" || decomposition.To.CommitSha != "c" || !decomposition.Delta.Equal(decimal.NewFromInt(80)) {
+		t.Fatalf("Expected a delta of 80 between the first and latest commits, got %+v", decomposition)
+	}
+	if len(decomposition.Dimensions) != 2 || decomposition.Dimensions[0].Dimension != "channel" || decomposition.Dimensions[1].Dimension != "country" {
+		t.Fatalf("Expected the channel and country decompositions, got %+v", decomposition.Dimensions)
+	}
+
+	// ES appeared with 110 and FR lost 30
+	country := decomposition.Dimensions[1]
+	expected := []struct {
+		value common.DimensionValue
+		delta int64
+	}{{"ES", 110}, {"FR", -30}, {"DE", 0}}
+	for i, contribution := range country.Contributions {
+		if contribution.DimensionValue != expected[i].value || !contribution.Delta.Equal(decimal.NewFromInt(expected[i].delta)) {
+			t.Errorf("Expected contribution %d to be %s %d, got %+v", i, expected[i].value, expected[i].delta, contribution)
+		}
+	}
+	if country.Contributions[0].Share != 1.375 || !country.IsConsistent || !country.Residual.IsZero() {
+		t.Errorf("Expected consistent contributions, got %+v", country)
+	}
+
+	// the channels miss the rows of ES
+	channel := decomposition.Dimensions[0]
+	if channel.IsConsistent || !channel.Sum.Equal(decimal.NewFromInt(20)) || !channel.Residual.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("Expected a residual of 60, got %+v", channel)
+	}
+
+	decomposition, err = DecomposePeriod(metrics, "2023-05", "b", "c")
+	if err != nil || !decomposition.Delta.Equal(decimal.NewFromInt(-20)) || !decomposition.Dimensions[1].Contributions[0].Delta.Equal(decimal.NewFromInt(-20)) {
+		t.Errorf("Expected FR to explain the delta of -20 between b and c, got %+v, %v", decomposition, err)
+	}
+
+	if _, err := DecomposePeriod(metrics, "2023-05", "unknown", ""); err == nil || errors.Is(err, ErrPeriodNotFound) {
+		t.Errorf("Expected an error for an unknown commit, got %v", err)
+	}
+	if _, err := DecomposePeriod(metrics, "2023-06", "", ""); !errors.Is(err, ErrPeriodNotFound) {
+		t.Errorf("Expected the period not to be found, got %v", err)
+	}
+}
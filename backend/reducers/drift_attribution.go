@@ -0,0 +1,69 @@
+package reducers
+
+import (
+	"sort"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+const DefaultAttributionTopN = 5
+
+// GetAttributionTopN returns the number of top contributors kept for each drift of the metric.
+func GetAttributionTopN(metric common.MetricConfig) int {
+	if metric.AttributionTopN <= 0 {
+		return DefaultAttributionTopN
+	}
+	return metric.AttributionTopN
+}
+
+// ComputeDriftAttribution compares the KPI values by row key of a period and dimension in two snapshots,
+// and returns the rows that explain the drift, sorted by the absolute value of their contribution.
+func ComputeDriftAttribution(previousValues map[string]decimal.Decimal, currentValues map[string]decimal.Decimal, topN int) common.DriftAttribution {
+	attribution := common.DriftAttribution{TopContributors: []common.RowContribution{}}
+	var contributors []common.RowContribution
+
+	for rowKey, currentValue := range currentValues {
+		attribution.Delta = attribution.Delta.Add(currentValue)
+		previousValue, existed := previousValues[rowKey]
+		if !existed {
+			attribution.AddedRows++
+			contributors = append(contributors, common.RowContribution{RowKey: rowKey, ChangeType: common.RowAdded, CurrentValue: currentValue, Contribution: currentValue})
+			continue
+		}
+		if !previousValue.Equal(currentValue) {
+			attribution.ModifiedRows++
+			contributors = append(contributors, common.RowContribution{RowKey: rowKey, ChangeType: common.RowModified, PreviousValue: previousValue, CurrentValue: currentValue, Contribution: currentValue.Sub(previousValue)})
+		}
+	}
+	for rowKey, previousValue := range previousValues {
+		attribution.Delta = attribution.Delta.Sub(previousValue)
+		if _, exists := currentValues[rowKey]; !exists {
+			attribution.RemovedRows++
+			contributors = append(contributors, common.RowContribution{RowKey: rowKey, ChangeType: common.RowRemoved, PreviousValue: previousValue, Contribution: previousValue.Neg()})
+		}
+	}
+
+	sort.Slice(contributors, func(i, j int) bool {
+		comparison := contributors[i].Contribution.Abs().Cmp(contributors[j].Contribution.Abs())
+		if comparison != 0 {
+			return comparison > 0
+		}
+		return contributors[i].RowKey < contributors[j].RowKey
+	})
+	if len(contributors) > topN {
+		contributors = contributors[:topN]
+	}
+	for i := range contributors {
+		if !attribution.Delta.IsZero() {
+			contributors[i].Share, _ = contributors[i].Contribution.Div(attribution.Delta).Float64()
+		}
+	}
+	attribution.TopContributors = append(attribution.TopContributors, contributors...)
+	return attribution
+}
+
+// HasChanges tells whether any row was added, removed or modified.
+func HasChanges(attribution common.DriftAttribution) bool {
+	return attribution.AddedRows+attribution.RemovedRows+attribution.ModifiedRows > 0
+}
@@ -0,0 +1,63 @@
+package reducers
+
+import (
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeDriftAttribution(t *testing.T) {
+	previousValues := map[string]decimal.Decimal{
+		"a": decimal.NewFromInt(10),
+		"b": decimal.NewFromInt(20),
+		"c": decimal.NewFromInt(5),
+	}
+	currentValues := map[string]decimal.Decimal{
+		"a": decimal.NewFromInt(10),
+		"b": decimal.NewFromInt(50),
+		"d": decimal.NewFromInt(15),
+	}
+
+	attribution := ComputeDriftAttribution(previousValues, currentValues, 2)
+
+	if !attribution.Delta.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("Expected delta 40, but got %v", attribution.Delta)
+	}
+	if attribution.AddedRows != 1 || attribution.RemovedRows != 1 || attribution.ModifiedRows != 1 {
+		t.Errorf("Expected 1 added, 1 removed and 1 modified row, but got %+v", attribution)
+	}
+	if len(attribution.TopContributors) != 2 {
+		t.Fatalf("Expected 2 top contributors, but got %d", len(attribution.TopContributors))
+	}
+
+	first := attribution.TopContributors[0]
+	if first.RowKey != "b" || first.ChangeType != common.RowModified || !first.Contribution.Equal(decimal.NewFromInt(30)) || first.Share != 0.75 {
+		t.Errorf("Unexpected first contributor %+v", first)
+	}
+	second := attribution.TopContributors[1]
+	if second.RowKey != "d" || second.ChangeType != common.RowAdded || second.Share != 0.375 {
+		t.Errorf("Unexpected second contributor %+v", second)
+	}
+}
+
+func TestComputeDriftAttribution_RemovedRow(t *testing.T) {
+	attribution := ComputeDriftAttribution(map[string]decimal.Decimal{"a": decimal.NewFromInt(8)}, map[string]decimal.Decimal{}, DefaultAttributionTopN)
+
+	if len(attribution.TopContributors) != 1 {
+		t.Fatalf("Expected 1 top contributor, but got %d", len(attribution.TopContributors))
+	}
+	contributor := attribution.TopContributors[0]
+	if contributor.ChangeType != common.RowRemoved || !contributor.Contribution.Equal(decimal.NewFromInt(-8)) || contributor.Share != 1 {
+		t.Errorf("Unexpected contributor %+v", contributor)
+	}
+}
+
+func TestComputeDriftAttribution_NoChange(t *testing.T) {
+	values := map[string]decimal.Decimal{"a": decimal.NewFromInt(8)}
+	attribution := ComputeDriftAttribution(values, values, DefaultAttributionTopN)
+
+	if HasChanges(attribution) || len(attribution.TopContributors) != 0 {
+		t.Errorf("Expected no change, but got %+v", attribution)
+	}
+}
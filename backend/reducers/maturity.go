@@ -0,0 +1,213 @@
+package reducers
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+)
+
+// MaturityOptions set how the cohorts are compared: over HorizonDays days after their first computation,
+// a cohort being stable once it stays within Tolerance % of its final value.
+type MaturityOptions struct {
+	HorizonDays int
+	Tolerance   float64
+	Now         time.Time
+}
+
+// CohortMaturity describes how the cohorts of a time grain drift after their first computation, their final value
+// being their value HorizonDays days after it.
+type CohortMaturity struct {
+	TimeGrain   common.TimeGrain `json:"timegrain"`
+	HorizonDays int              `json:"horizonDays"`
+	Tolerance   float64          `json:"tolerance"`
+	// Number of cohorts measured at the horizon, from which the stabilization and the predictions are computed
+	MatureCohorts int                 `json:"matureCohorts"`
+	Curve         []MaturityPoint     `json:"curve"`
+	Stabilization *StabilizationStats `json:"stabilization"`
+	Predictions   []CohortPrediction  `json:"predictions"`
+}
+
+// MaturityPoint is the distribution of the relative drift of the cohorts, in % of their initial value, a number of days after their first computation.
+type MaturityPoint struct {
+	Day     int     `json:"day"`
+	Cohorts int     `json:"cohorts"`
+	Median  float64 `json:"median"`
+	P10     float64 `json:"p10"`
+	P90     float64 `json:"p90"`
+}
+
+// StabilizationStats is the number of days after which the mature cohorts stay within the tolerance of their final value.
+type StabilizationStats struct {
+	MedianDays float64 `json:"medianDays"`
+	P90Days    float64 `json:"p90Days"`
+}
+
+// CohortPrediction is the expected final value of a cohort younger than the horizon, with the p10 and p90 of the drifts
+// the mature cohorts had after the same age.
+type CohortPrediction struct {
+	CohortDate     string  `json:"cohortDate"`
+	AgeDays        float64 `json:"ageDays"`
+	CurrentValue   float64 `json:"currentValue"`
+	PredictedValue float64 `json:"predictedValue"`
+	Low            float64 `json:"low"`
+	High           float64 `json:"high"`
+	// Date after which 90% of the mature cohorts were stable, nil when no cohort is mature
+	ExpectedStableAt *time.Time `json:"expectedStableAt"`
+}
+
+type cohortStep struct {
+	day   float64
+	value float64
+}
+
+// maturingCohort is the relative drift of a cohort as a step function of the days after its first computation.
+type maturingCohort struct {
+	date     string
+	metadata MetricMetadata
+	steps    []cohortStep
+	ageDays  float64
+}
+
+func newMaturingCohort(date string, metadata MetricMetadata, now time.Time) maturingCohort {
+	cohort := maturingCohort{date: date, metadata: metadata}
+	for _, event := range metadata.RelativeHistory {
+		cohort.steps = append(cohort.steps, cohortStep{day: helpers.GetFloat(event.DaysFromHistorization), value: helpers.GetFloat(event.RelativeValue)})
+	}
+	sort.Slice(cohort.steps, func(i, j int) bool { return cohort.steps[i].day < cohort.steps[j].day })
+	return cohort.asOf(now)
+}
+
+// asOf returns the cohort as it was measured at a date.
+func (cohort maturingCohort) asOf(date time.Time) maturingCohort {
+	cohort.ageDays = date.Sub(cohort.metadata.FirstDate).Hours() / 24
+	return cohort
+}
+
+// valueAt returns the relative drift of the cohort a number of days after its first computation, if it was measured by then.
+func (cohort maturingCohort) valueAt(day float64) (float64, bool) {
+	if day > cohort.ageDays || len(cohort.steps) == 0 || day < cohort.steps[0].day {
+		return 0, false
+	}
+	i := sort.Search(len(cohort.steps), func(i int) bool { return cohort.steps[i].day > day })
+	return cohort.steps[i-1].value, true
+}
+
+// kpi converts a relative drift back to a value of the KPI, like GetMetadataOfMetric computed it.
+func (cohort maturingCohort) kpi(relativeValue float64) float64 {
+	initialValue := helpers.GetFloat(cohort.metadata.InitialValue)
+	base := initialValue
+	if base == 0 {
+		base = 1
+	}
+	return initialValue + relativeValue*base/100
+}
+
+// GetCohortMaturity aggregates the relative history of the cohorts into the distribution of their drift by day,
+// the time they take to stabilize and a prediction of the final value of the cohorts younger than the horizon.
+func GetCohortMaturity(data CohortsData, options MaturityOptions) CohortMaturity {
+	var cohorts []maturingCohort
+	for _, cohortDate := range data.CohortDates {
+		metadata, ok := data.CohortsMetricsMetadata[cohortDate]
+		if !ok || len(metadata.RelativeHistory) == 0 {
+			continue
+		}
+		cohorts = append(cohorts, newMaturingCohort(cohortDate, metadata, options.Now))
+	}
+
+	horizon := float64(options.HorizonDays)
+	maturity := CohortMaturity{TimeGrain: data.TimeGrain, HorizonDays: options.HorizonDays, Tolerance: options.Tolerance, Curve: []MaturityPoint{}, Predictions: []CohortPrediction{}}
+	for day := 0; day <= options.HorizonDays; day++ {
+		var values []float64
+		for _, cohort := range cohorts {
+			if value, ok := cohort.valueAt(float64(day)); ok {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+		maturity.Curve = append(maturity.Curve, MaturityPoint{Day: day, Cohorts: len(values), Median: percentile(values, 50), P10: percentile(values, 10), P90: percentile(values, 90)})
+	}
+
+	var matureCohorts []maturingCohort
+	var stabilizationDays []float64
+	for _, cohort := range cohorts {
+		if _, ok := cohort.valueAt(horizon); !ok {
+			continue
+		}
+		matureCohorts = append(matureCohorts, cohort)
+		stabilizationDays = append(stabilizationDays, cohort.stabilizationDay(options))
+	}
+	maturity.MatureCohorts = len(matureCohorts)
+	if len(matureCohorts) == 0 {
+		return maturity
+	}
+	sort.Float64s(stabilizationDays)
+	maturity.Stabilization = &StabilizationStats{MedianDays: percentile(stabilizationDays, 50), P90Days: percentile(stabilizationDays, 90)}
+
+	for _, cohort := range cohorts {
+		if cohort.ageDays >= horizon {
+			continue
+		}
+		currentValue, ok := cohort.valueAt(cohort.ageDays)
+		if !ok {
+			continue
+		}
+		// the final value of each mature cohort relative to its value at the age of the cohort
+		var growths []float64
+		for _, matureCohort := range matureCohorts {
+			valueAtAge, ok := matureCohort.valueAt(cohort.ageDays)
+			finalValue, _ := matureCohort.valueAt(horizon)
+			if !ok || matureCohort.kpi(valueAtAge) == 0 {
+				continue
+			}
+			growths = append(growths, matureCohort.kpi(finalValue)/matureCohort.kpi(valueAtAge))
+		}
+		if len(growths) == 0 {
+			continue
+		}
+		sort.Float64s(growths)
+		currentKPI := cohort.kpi(currentValue)
+		low, high := currentKPI*percentile(growths, 10), currentKPI*percentile(growths, 90)
+		expectedStableAt := cohort.metadata.FirstDate.Add(time.Duration(math.Round(maturity.Stabilization.P90Days*24*3600)) * time.Second)
+		maturity.Predictions = append(maturity.Predictions, CohortPrediction{
+			CohortDate:       cohort.date,
+			AgeDays:          cohort.ageDays,
+			CurrentValue:     currentKPI,
+			PredictedValue:   currentKPI * percentile(growths, 50),
+			Low:              math.Min(low, high),
+			High:             math.Max(low, high),
+			ExpectedStableAt: &expectedStableAt,
+		})
+	}
+	return maturity
+}
+
+// stabilizationDay is the first day from which a mature cohort stays within the tolerance of its value at the horizon.
+func (cohort maturingCohort) stabilizationDay(options MaturityOptions) float64 {
+	finalValue, _ := cohort.valueAt(float64(options.HorizonDays))
+	finalKPI := cohort.kpi(finalValue)
+	stableDay := options.HorizonDays
+	for day := options.HorizonDays; day >= 0; day-- {
+		value, ok := cohort.valueAt(float64(day))
+		if !ok || math.Abs(cohort.kpi(value)-finalKPI) > options.Tolerance/100*math.Abs(finalKPI) {
+			break
+		}
+		stableDay = day
+	}
+	return float64(stableDay)
+}
+
+// percentile interpolates the p-th percentile of sorted values.
+func percentile(sortedValues []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sortedValues)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(sortedValues) {
+		return sortedValues[len(sortedValues)-1]
+	}
+	return sortedValues[lower] + (rank-float64(lower))*(sortedValues[lower+1]-sortedValues[lower])
+}
@@ -0,0 +1,97 @@
+package reducers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func getMaturingMetadata(firstDate time.Time, initialValue int64, relativeValueByDay map[int64]float64) MetricMetadata {
+	relativeHistory := make(map[time.Duration]RelativeHistoricalEvent)
+	for day, relativeValue := range relativeValueByDay {
+		duration := time.Duration(day) * 24 * time.Hour
+		relativeHistory[duration] = RelativeHistoricalEvent{
+			RelativeValue:         decimal.NewFromFloat(relativeValue),
+			DaysFromHistorization: decimal.NewFromInt(day),
+			ComputationTimetamp:   firstDate.Add(duration).Unix(),
+		}
+	}
+	return MetricMetadata{InitialValue: decimal.NewFromInt(initialValue), FirstDate: firstDate, RelativeHistory: relativeHistory}
+}
+
+func TestGetCohortMaturity(t *testing.T) {
+	now := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	data := CohortsData{
+		TimeGrain:   "month",
+		CohortDates: []string{"2023-08", "2023-09", "2023-11"},
+		CohortsMetricsMetadata: map[string]MetricMetadata{
+			// 100 -> 105 -> 110, stable from day 5
+			"2023-08": getMaturingMetadata(time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC), 100, map[int64]float64{0: 0, 2: 5, 5: 10}),
+			// 200 -> 220 -> 220.5, within 1% from day 3
+			"2023-09": getMaturingMetadata(time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), 200, map[int64]float64{0: 0, 3: 10, 8: 10.25}),
+			// 1000 -> 1020, 4 days old
+			"2023-11": getMaturingMetadata(now.Add(-4*24*time.Hour), 1000, map[int64]float64{0: 0, 1: 2}),
+		},
+	}
+
+	maturity := GetCohortMaturity(data, MaturityOptions{HorizonDays: 10, Tolerance: 1, Now: now})
+
+	if maturity.MatureCohorts != 2 || len(maturity.Curve) != 11 {
+		t.Fatalf("Expected 2 mature cohorts and a point per day, got %+v", maturity)
+	}
+	if point := maturity.Curve[0]; point.Cohorts != 3 || point.Median != 0 {
+		t.Errorf("Expected the 3 cohorts at day 0, got %+v", point)
+	}
+	if point := maturity.Curve[5]; point.Cohorts != 2 || point.Median != 10 || point.P10 != 10 || point.P90 != 10 {
+		t.Errorf("Expected the 2 mature cohorts at +10%% at day 5, got %+v", point)
+	}
+	if maturity.Stabilization == nil || maturity.Stabilization.MedianDays != 4 || !almostEqual(maturity.Stabilization.P90Days, 4.8) {
+		t.Errorf("Expected a median stabilization of 4 days and a p90 of 4.8 days, got %+v", maturity.Stabilization)
+	}
+
+	if len(maturity.Predictions) != 1 {
+		t.Fatalf("Expected a prediction for the open cohort, got %+v", maturity.Predictions)
+	}
+	prediction := maturity.Predictions[0]
+	// the mature cohorts grew by 110/105 and 220.5/220 after day 4
+	if prediction.CohortDate != "2023-11" || prediction.CurrentValue != 1020 || !almostEqual(prediction.PredictedValue, 1045.44) {
+		t.Errorf("Expected 2023-11 to go from 1020 to 1045.44, got %+v", prediction)
+	}
+	if !almostEqual(prediction.Low, 1026.94) || !almostEqual(prediction.High, 1063.95) {
+		t.Errorf("Expected a band of 1026.94 to 1063.95, got %+v", prediction)
+	}
+	expectedStableAt := time.Date(2023, 12, 1, 19, 12, 0, 0, time.UTC)
+	if prediction.ExpectedStableAt == nil || !prediction.ExpectedStableAt.Equal(expectedStableAt) {
+		t.Errorf("Expected the cohort to be stable at %v, got %v", expectedStableAt, prediction.ExpectedStableAt)
+	}
+}
+
+func TestGetCohortMaturityWithoutMatureCohort(t *testing.T) {
+	now := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	data := CohortsData{
+		TimeGrain:   "month",
+		CohortDates: []string{"2023-11"},
+		CohortsMetricsMetadata: map[string]MetricMetadata{
+			"2023-11": getMaturingMetadata(now.Add(-4*24*time.Hour), 1000, map[int64]float64{0: 0, 1: 2}),
+		},
+	}
+
+	maturity := GetCohortMaturity(data, MaturityOptions{HorizonDays: 10, Tolerance: 1, Now: now})
+
+	if maturity.Stabilization != nil || len(maturity.Predictions) != 0 || len(maturity.Curve) != 5 {
+		t.Errorf("Expected only the curve of the first 5 days, got %+v", maturity)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+	if percentile(values, 50) != 2.5 || percentile(values, 0) != 1 || percentile(values, 100) != 4 || !almostEqual(percentile(values, 90), 3.7) {
+		t.Errorf("Unexpected percentiles of %v", values)
+	}
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
@@ -2,42 +2,43 @@ package reducers
 
 import (
 	"fmt"
-	"strings"
+	"log"
+	"net/url"
 
 	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/period"
 	"github.com/data-drift/data-drift/urlgen"
-	"github.com/go-redis/redis/v8"
 	"github.com/shopspring/decimal"
 )
 
-type ChartResponse struct {
-	Success bool   `json:"success"`
-	URL     string `json:"url"`
-}
-
-func ProcessMetricHistory(historyFilepath common.MetricStorageKey, redisClient *redis.Client, metric common.MetricConfig, ownerName string, repoName string) []common.KPIReport {
-	kpiRepository := common.NewKpiRepository(redisClient)
-
+func ProcessMetricHistory(historyFilepath common.MetricStorageKey, kpiRepository common.MetricStore, metric common.MetricConfig, ownerName string, repoName string) []common.KPIReport {
 	data, err := kpiRepository.ReadMetricKPI(historyFilepath)
 	if err != nil {
 		fmt.Println("Error:", err.Error())
 	}
 
 	var kpiInfos []common.KPIReport
+	scorer := NewDriftScorer(data)
 
-	for periodIdAndDimensionKey, datum := range data {
-		key := string(periodIdAndDimensionKey)
-		// Access the value associated with the key: data[key]
-		// Additional logic for processing the value
-		// ...
-		kpi := OrderDataAndCreateChart(metric.MetricName+" "+key, datum.Period, datum.History, datum.DimensionValue, ownerName, repoName, metric.MetricName)
+	for _, datum := range data {
+		kpiName := metric.MetricName + " " + string(datum.Period)
+		if datum.Dimension != common.NoDimension {
+			kpiName += " " + string(datum.DimensionValue)
+		}
+		metricPeriod, err := period.ForMetric(datum)
+		if err != nil {
+			log.Println("Error:", err.Error())
+			continue
+		}
+		kpi := OrderDataAndCreateChart(kpiName, datum.Period, metricPeriod, datum.History, datum.DimensionValue, datum.DimensionQuery(), ownerName, repoName, metric.MetricName)
+		scorer.ScoreEvents(datum, kpi.Events)
 		kpiInfos = append(kpiInfos, kpi)
 	}
 
 	return kpiInfos
 }
 
-func OrderDataAndCreateChart(KPIName string, periodId common.PeriodKey, unsortedResults common.MetricHistory, dimensionValue common.DimensionValue, ownerName, repoName, metricName string) common.KPIReport {
+func OrderDataAndCreateChart(KPIName string, periodId common.PeriodKey, metricPeriod period.Period, unsortedResults common.MetricHistory, dimensionValue common.DimensionValue, dimensionQuery url.Values, ownerName, repoName, metricName string) common.KPIReport {
 	// Extract the values from the map into a slice of struct objects
 	var dataSortableArray []common.CommitData
 
@@ -49,14 +50,10 @@ func OrderDataAndCreateChart(KPIName string, periodId common.PeriodKey, unsorted
 			CommitTimestamp: stats.CommitTimestamp,
 			CommitUrl:       stats.CommitUrl,
 			CommitComments:  stats.CommitComments,
+			Attribution:     stats.Attribution,
 		})
 	}
-	firstDateOfPeriod, firstDateOfPeriodErr := LegacyGetFirstComputationDateOfPeriod(periodId)
-	if firstDateOfPeriodErr != nil {
-		fmt.Println("Error:", firstDateOfPeriodErr.Error())
-		return common.KPIReport{}
-	}
-	sortedAndFilteredArray := FilterAndSortByCommitTimestamp(dataSortableArray, firstDateOfPeriod)
+	sortedAndFilteredArray := FilterAndSortByCommitTimestamp(dataSortableArray, metricPeriod.FirstComputationDate())
 
 	if len(sortedAndFilteredArray) == 0 {
 		return common.KPIReport{}
@@ -95,17 +92,14 @@ func OrderDataAndCreateChart(KPIName string, periodId common.PeriodKey, unsorted
 					EventType:       common.EventTypeUpdate,
 					CommitUrl:       v.CommitUrl,
 					CommitComments:  v.CommitComments,
+					Attribution:     v.Attribution,
 				}
 				events = append(events, event)
 			}
 			prevKPI = roundedKPI
 		}
 	}
-	dimensionValueForUrl := ""
-	if dimensionValue != common.NoDimensionValue {
-		dimensionValueForUrl = string(dimensionValue)
-	}
-	waterfallChartUrl := urlgen.MetricReportUrl(ownerName, repoName, metricName, periodId, dimensionValueForUrl)
+	waterfallChartUrl := urlgen.MetricReportUrl(ownerName, repoName, metricName, periodId, dimensionQuery)
 	kpi1 := common.KPIReport{
 		KPIName:           KPIName,
 		PeriodId:          periodId,
@@ -117,8 +111,3 @@ func OrderDataAndCreateChart(KPIName string, periodId common.PeriodKey, unsorted
 	}
 	return kpi1
 }
-
-func convertToChartMakerURL(url string) string {
-	chartMakerURL := strings.Replace(url, "chart/render", "chart-maker/view", 1)
-	return chartMakerURL
-}
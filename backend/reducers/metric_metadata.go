@@ -1,16 +1,12 @@
 package reducers
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"sort"
 	"time"
 
 	"github.com/data-drift/data-drift/common"
-	"github.com/data-drift/data-drift/helpers"
-	"github.com/go-redis/redis/v8"
+	"github.com/data-drift/data-drift/period"
+	"github.com/data-drift/data-drift/urlgen"
 	"github.com/shopspring/decimal"
 )
 
@@ -28,9 +24,9 @@ type MetricMetadata struct {
 	RelativeHistory map[time.Duration]RelativeHistoricalEvent
 }
 
-func ProcessMetricMetadataCharts(filepath common.MetricStorageKey, metricConfig common.MetricConfig, redisClient *redis.Client) (map[common.TimeGrain]string, error) {
-	kpiRepository := common.NewKpiRepository(redisClient)
-
+// ProcessMetricMetadataCharts returns the URL of the cohort chart of each time grain of the metric having computed periods,
+// the URLs being empty when DATADRIFT_API_URL is not set.
+func ProcessMetricMetadataCharts(filepath common.MetricStorageKey, metricConfig common.MetricConfig, kpiRepository common.MetricStore, ownerName string, repoName string) (map[common.TimeGrain]string, error) {
 	metrics, marshelingError := kpiRepository.ReadMetricKPI(filepath)
 	if marshelingError != nil {
 		fmt.Println("[DATADRIFT ERROR]: marshaling data", marshelingError.Error())
@@ -40,8 +36,10 @@ func ProcessMetricMetadataCharts(filepath common.MetricStorageKey, metricConfig
 	metadata := ProcessMetricMetadata(metricConfig, metrics)
 	metadataChartUrls := make(map[common.TimeGrain]string)
 	for _, timeGrain := range metricConfig.TimeGrains {
-		chartUrl := CreateMetadataChart(metadata[timeGrain])
-		metadataChartUrls[timeGrain] = chartUrl
+		if len(metadata[timeGrain]) == 0 {
+			continue
+		}
+		metadataChartUrls[timeGrain] = urlgen.MetricCohortChartUrl(ownerName, repoName, metricConfig.MetricName, timeGrain, "png")
 	}
 	return metadataChartUrls, nil
 }
@@ -50,7 +48,7 @@ func ProcessMetricMetadata(metricConfig common.MetricConfig, metrics common.Metr
 
 	metricMetadatas := make(map[common.TimeGrain]map[common.PeriodKey]MetricMetadata)
 	for _, metric := range metrics {
-		if metric.Dimension != "none" {
+		if metric.Dimension != common.NoDimension {
 			continue
 		}
 		metricMetadata, metricMetadataErr := GetMetadataOfMetric(metric)
@@ -66,10 +64,11 @@ func ProcessMetricMetadata(metricConfig common.MetricConfig, metrics common.Metr
 }
 
 func GetMetadataOfMetric(metric common.Metric) (MetricMetadata, error) {
-	firstDateOfPeriod, firstDateOfPeriodErr := LegacyGetFirstComputationDateOfPeriod(metric.Period)
-	if firstDateOfPeriodErr != nil {
-		return MetricMetadata{}, firstDateOfPeriodErr
+	metricPeriod, metricPeriodErr := period.ForMetric(metric)
+	if metricPeriodErr != nil {
+		return MetricMetadata{}, metricPeriodErr
 	}
+	firstDateOfPeriod := metricPeriod.FirstComputationDate()
 	var dataSortableArray []common.CommitData
 
 	for _, stats := range metric.History {
@@ -121,101 +120,3 @@ func getDuration(commitTimestamp int64, firstDateOfPeriod time.Time) time.Durati
 	commitTime := time.Unix(commitTimestamp, 0)
 	return commitTime.Sub(firstDateOfPeriod)
 }
-
-func mapChartDataToDatasets(chartData map[time.Duration]RelativeHistoricalEvent) []map[string]interface{} {
-
-	var data []map[string]interface{}
-	for _, point := range chartData {
-
-		data = append(data, map[string]interface{}{
-			"x": helpers.GetFloat(point.DaysFromHistorization),
-			"y": helpers.GetFloat(point.RelativeValue),
-		})
-	}
-
-	sort.Slice(data, func(i, j int) bool {
-		return data[i]["x"].(float64) < data[j]["x"].(float64)
-	})
-
-	return removeDuplicatesByY(data)
-}
-
-func CreateMetadataChart(metricMetadatas map[common.PeriodKey]MetricMetadata) string {
-	datasets := []map[string]interface{}{}
-	for _, metricMetadata := range metricMetadatas {
-
-		datasets = append(datasets, map[string]interface{}{
-			"label":           metricMetadata.PeriodKey,
-			"showLine":        true,
-			"lineTension":     0,
-			"borderColor":     helpers.GetColorFromString(string(metricMetadata.PeriodKey)),
-			"backgroundColor": "transparent",
-			"data":            mapChartDataToDatasets(metricMetadata.RelativeHistory),
-		})
-	}
-	jsonBody := map[string]interface{}{
-		"version":          "4",
-		"backgroundColor":  "transparent",
-		"width":            500,
-		"height":           300,
-		"devicePixelRatio": 2.0,
-		"format":           "svg",
-		"chart": map[string]interface{}{
-			"type": "scatter",
-			"data": map[string]interface{}{
-
-				"datasets": datasets,
-			},
-		},
-	}
-
-	helpers.WriteMetadataToFile(jsonBody, "dist/payload.json")
-
-	newData, _ := json.Marshal(jsonBody)
-	url := "https://quickchart.io/chart/create"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(newData))
-	req.Header.Set("Content-Type", "application/json")
-
-	if err != nil {
-		panic(err)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		panic(err)
-	}
-	defer resp.Body.Close()
-
-	fmt.Println("Response Status:", resp.Status)
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-	fmt.Println(buf.String())
-
-	var chartResponse ChartResponse
-	jsonUnmarshalError := json.Unmarshal(buf.Bytes(), &chartResponse)
-	if jsonUnmarshalError != nil {
-		fmt.Println("Error parsing JSON:", err.Error())
-		return "" // Return an empty string or handle the error as needed
-	}
-
-	interactiveUrl := convertToChartMakerURL(chartResponse.URL)
-	fmt.Println("Interactive URL:", interactiveUrl)
-
-	// Return only the URL
-	return interactiveUrl
-}
-
-func removeDuplicatesByY(data []map[string]interface{}) []map[string]interface{} {
-
-	var result []map[string]interface{}
-	var lastKPI float64
-	for _, point := range data {
-		if lastKPI == 0 || point["y"] != lastKPI {
-			result = append(result, point)
-			lastKPI = point["y"].(float64)
-		}
-	}
-
-	return result
-}
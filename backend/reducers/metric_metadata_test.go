@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/period"
 	"github.com/shopspring/decimal"
 )
 
@@ -122,7 +123,8 @@ func TestGetMetadataOfMetric(t *testing.T) {
 		1493*time.Hour + 49*time.Minute + 14*time.Second: {getDecimalFromString("100.44869295561106").Sub(decimal.NewFromInt(100)), getDecimalFromString("62.242523148148145"), 1683006553},
 		1541*time.Hour + 21*time.Minute + 32*time.Second: {getDecimalFromString("100.44869295561106").Sub(decimal.NewFromInt(100)), getDecimalFromString("64.22328703703704"), 1683177691},
 	}
-	firstDate, _ := LegacyGetFirstComputationDateOfPeriod("2023-02")
+	february, _ := period.Parse("2023-02")
+	firstDate := february.FirstComputationDate()
 	expected := MetricMetadata{
 		TimeGrain:       "month",
 		PeriodKey:       "2023-02",
@@ -0,0 +1,65 @@
+package reports
+
+import (
+	"log"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/database/notion_database"
+	"github.com/google/go-github/v56/github"
+)
+
+// Reporter publishes the reports of the KPIs of a repository to a destination.
+type Reporter interface {
+	// Init prepares the destination before a sync.
+	Init() error
+	// UpsertKPIReport creates or updates the report of a KPI period, the events committed after since being new.
+	UpsertKPIReport(metric common.MetricConfig, report common.KPIReport, since int64) error
+	// UpsertSummaryReport creates or updates the summary of a metric, with the url of its cohorts chart by time grain.
+	UpsertSummaryReport(metric common.MetricConfig, chartUrls map[common.TimeGrain]string) error
+}
+
+// NewReporters returns the reporters of the destinations set in the config, none when the config sets none.
+func NewReporters(config common.Config, client *github.Client, kpiRepository common.MetricStore, ownerName string, repoName string, slackThreads SlackThreadStore) []Reporter {
+	var reporters []Reporter
+	if config.NotionAPIToken != "" && config.NotionDatabaseID != "" {
+		reporters = append(reporters, NotionReporter{syncConfig: common.SyncConfig{
+			GithubRepoOwner:  ownerName,
+			GithubRepoName:   repoName,
+			NotionAPIKey:     config.NotionAPIToken,
+			NotionDatabaseID: config.NotionDatabaseID,
+		}})
+	}
+	if config.Slack != nil {
+		reporters = append(reporters, NewSlackReporter(*config.Slack, slackThreads))
+	}
+	if config.StaticReport != nil {
+		writer, err := newStaticSiteWriter(*config.StaticReport, client, ownerName, repoName)
+		if err != nil {
+			log.Println("[DATADRIFT_ERROR] static report", err.Error())
+		} else {
+			var metricNames []string
+			for _, metric := range config.ExpandedMetrics() {
+				metricNames = append(metricNames, metric.MetricName)
+			}
+			reporters = append(reporters, newStaticReporter(*config.StaticReport, metricNames, kpiRepository, ownerName, repoName, writer))
+		}
+	}
+	return reporters
+}
+
+// NotionReporter keeps a page per KPI period and a summary page per metric in a Notion database.
+type NotionReporter struct {
+	syncConfig common.SyncConfig
+}
+
+func (reporter NotionReporter) Init() error {
+	return notion_database.AssertDatabaseHasDatadriftProperties(reporter.syncConfig.NotionDatabaseID, reporter.syncConfig.NotionAPIKey)
+}
+
+func (reporter NotionReporter) UpsertKPIReport(metric common.MetricConfig, report common.KPIReport, since int64) error {
+	return CreateReport(reporter.syncConfig, report)
+}
+
+func (reporter NotionReporter) UpsertSummaryReport(metric common.MetricConfig, chartUrls map[common.TimeGrain]string) error {
+	return CreateSummaryReport(reporter.syncConfig, metric, chartUrls)
+}
@@ -0,0 +1,49 @@
+package reports
+
+import (
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+func TestNewReporters(t *testing.T) {
+	slack := &common.SlackConfig{Channel: "C123"}
+	testCases := []struct {
+		name     string
+		config   common.Config
+		expected []string
+	}{
+		{"no destination", common.Config{}, nil},
+		{"notion", common.Config{NotionAPIToken: "token", NotionDatabaseID: "id"}, []string{"notion"}},
+		{"incomplete notion", common.Config{NotionAPIToken: "token"}, nil},
+		{"slack", common.Config{Slack: slack}, []string{"slack"}},
+		{"notion and slack", common.Config{NotionAPIToken: "token", NotionDatabaseID: "id", Slack: slack}, []string{"notion", "slack"}},
+		{"static site", common.Config{StaticReport: &common.StaticReportConfig{Directory: "site"}}, []string{"static"}},
+		{"static site outside its directory", common.Config{StaticReport: &common.StaticReportConfig{Directory: "../site"}}, nil},
+		{"static site in a branch without github client", common.Config{StaticReport: &common.StaticReportConfig{Branch: "drift-reports"}}, nil},
+	}
+
+	for _, tc := range testCases {
+		reporters := NewReporters(tc.config, nil, nil, "acme", "data", memorySlackThreadStore{})
+		var kinds []string
+		for _, reporter := range reporters {
+			switch reporter.(type) {
+			case NotionReporter:
+				kinds = append(kinds, "notion")
+			case *SlackReporter:
+				kinds = append(kinds, "slack")
+			case *StaticReporter:
+				kinds = append(kinds, "static")
+			}
+		}
+		if len(kinds) != len(tc.expected) {
+			t.Errorf("%s: expected %v reporters, got %v", tc.name, tc.expected, kinds)
+			continue
+		}
+		for i := range kinds {
+			if kinds[i] != tc.expected[i] {
+				t.Errorf("%s: expected %v reporters, got %v", tc.name, tc.expected, kinds)
+			}
+		}
+	}
+}
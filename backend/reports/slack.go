@@ -0,0 +1,231 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultSlackApiUrl = "https://slack.com/api"
+
+// SlackThreadStore remembers the thread of the drifts of each KPI period of a channel.
+type SlackThreadStore interface {
+	// GetThreadTs returns the timestamp of the first message of the thread, empty when there is none yet.
+	GetThreadTs(channel string, threadKey string) (string, error)
+	SaveThreadTs(channel string, threadKey string, ts string) error
+}
+
+// SlackThread is the thread of the drifts of a KPI period in a channel.
+type SlackThread struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Channel   string `gorm:"uniqueIndex:idx_slack_thread;not null"`
+	ThreadKey string `gorm:"uniqueIndex:idx_slack_thread;not null"`
+	Ts        string `gorm:"not null"`
+}
+
+// GormSlackThreadStore keeps the threads in the database of the app.
+type GormSlackThreadStore struct {
+	DB *gorm.DB
+}
+
+func (store GormSlackThreadStore) GetThreadTs(channel string, threadKey string) (string, error) {
+	var thread SlackThread
+	err := store.DB.Where("channel = ? AND thread_key = ?", channel, threadKey).First(&thread).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return thread.Ts, err
+}
+
+func (store GormSlackThreadStore) SaveThreadTs(channel string, threadKey string, ts string) error {
+	thread := SlackThread{Channel: channel, ThreadKey: threadKey, Ts: ts}
+	return store.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&thread).Error
+}
+
+// SlackReporter posts a Block Kit message for each drift of a KPI, the drifts of a KPI period sharing a thread.
+type SlackReporter struct {
+	config  common.SlackConfig
+	apiUrl  string
+	token   string
+	threads SlackThreadStore
+	client  *http.Client
+}
+
+// NewSlackReporter posts with the bot token of SLACK_BOT_TOKEN to the Web API of SLACK_API_URL, https://slack.com/api by default.
+// Neither is read from the config of the repository, so that a pushed config can not receive the token.
+func NewSlackReporter(config common.SlackConfig, threads SlackThreadStore) *SlackReporter {
+	apiUrl := os.Getenv("SLACK_API_URL")
+	if apiUrl == "" {
+		apiUrl = defaultSlackApiUrl
+	}
+	return &SlackReporter{config: config, apiUrl: apiUrl, token: os.Getenv("SLACK_BOT_TOKEN"), threads: threads, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Init has nothing to prepare, the channel being set up in Slack.
+func (reporter *SlackReporter) Init() error {
+	return nil
+}
+
+// UpsertKPIReport posts the drifts of the report committed after since, none when since is zero i.e. when the history has just been built.
+func (reporter *SlackReporter) UpsertKPIReport(metric common.MetricConfig, report common.KPIReport, since int64) error {
+	if since == 0 {
+		return nil
+	}
+	metricName := metric.MetricName
+	threadKey := getSlackThreadKey(metricName, report)
+	for _, event := range report.Events {
+		if event.EventType != common.EventTypeUpdate || event.CommitTimestamp <= since {
+			continue
+		}
+		threadTs, err := reporter.threads.GetThreadTs(reporter.config.Channel, threadKey)
+		if err != nil {
+			return fmt.Errorf("failed to get slack thread of %s: %v", report.KPIName, err.Error())
+		}
+		ts, err := reporter.postMessage(BuildSlackDriftMessage(metricName, report, event), threadTs)
+		if err != nil {
+			return fmt.Errorf("failed to post drift of %s: %v", report.KPIName, err.Error())
+		}
+		if threadTs == "" {
+			if err := reporter.threads.SaveThreadTs(reporter.config.Channel, threadKey, ts); err != nil {
+				return fmt.Errorf("failed to save slack thread of %s: %v", report.KPIName, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// UpsertSummaryReport posts nothing, the drifts being reported as they happen.
+func (reporter *SlackReporter) UpsertSummaryReport(metric common.MetricConfig, chartUrls map[common.TimeGrain]string) error {
+	return nil
+}
+
+// getSlackThreadKey identifies a KPI period, e.g. revenue/2023-05/FR.
+func getSlackThreadKey(metricName string, report common.KPIReport) string {
+	return metricName + "/" + string(report.PeriodId) + "/" + string(report.DimensionValue)
+}
+
+// SlackMessage is a Block Kit message, its text being shown in the notifications.
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+type SlackBlock struct {
+	Type     string        `json:"type"`
+	Text     *SlackText    `json:"text,omitempty"`
+	Fields   []SlackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackButton struct {
+	Type string    `json:"type"`
+	Text SlackText `json:"text"`
+	Url  string    `json:"url"`
+}
+
+// BuildSlackDriftMessage describes a drift of a KPI: its period and dimension, its previous and new values, the commit comments and a link to its report.
+func BuildSlackDriftMessage(metricName string, report common.KPIReport, event common.EventObject) SlackMessage {
+	diff := decimal.NewFromFloat(event.Diff)
+	previousValue := event.Current.Sub(diff)
+	signedDiff := diff.String()
+	if diff.IsPositive() {
+		signedDiff = "+" + signedDiff
+	}
+
+	fields := []SlackText{
+		{Type: "mrkdwn", Text: "*Metric*\n" + escapeSlackText(metricName)},
+		{Type: "mrkdwn", Text: "*Period*\n" + string(report.PeriodId)},
+	}
+	if report.DimensionValue != "" && report.DimensionValue != common.NoDimensionValue {
+		fields = append(fields, SlackText{Type: "mrkdwn", Text: "*Dimension*\n" + escapeSlackText(string(report.DimensionValue))})
+	}
+	fields = append(fields,
+		SlackText{Type: "mrkdwn", Text: "*Value*\n" + previousValue.String() + " → " + event.Current.String()},
+		SlackText{Type: "mrkdwn", Text: "*Diff*\n" + signedDiff},
+	)
+
+	blocks := []SlackBlock{
+		{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: "*" + escapeSlackText(report.KPIName) + "* drifted by *" + signedDiff + "*"}},
+		{Type: "section", Fields: fields},
+	}
+	if len(event.CommitComments) > 0 {
+		var comments []string
+		for _, comment := range event.CommitComments {
+			comments = append(comments, "> *"+escapeSlackText(comment.CommentAuthor)+"*: "+escapeSlackText(strings.ReplaceAll(comment.CommentBody, "\n", " ")))
+		}
+		blocks = append(blocks, SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: "*Commit comments*\n" + strings.Join(comments, "\n")}})
+	}
+	if event.CommitUrl != "" {
+		committedAt := time.Unix(event.CommitTimestamp, 0).UTC().Format("2006-01-02 15:04 MST")
+		blocks = append(blocks, SlackBlock{Type: "context", Elements: []interface{}{
+			SlackText{Type: "mrkdwn", Text: "<" + event.CommitUrl + "|Commit> of " + committedAt},
+		}})
+	}
+	blocks = append(blocks, SlackBlock{Type: "actions", Elements: []interface{}{
+		SlackButton{Type: "button", Text: SlackText{Type: "plain_text", Text: "View report"}, Url: report.WaterfallChartUrl},
+	}})
+
+	return SlackMessage{
+		Text:   fmt.Sprintf("%s drifted by %s", report.KPIName, signedDiff),
+		Blocks: blocks,
+	}
+}
+
+// escapeSlackText escapes the control characters of the mrkdwn format.
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+type slackPostMessageResponse struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+	Ts    string `json:"ts"`
+}
+
+// postMessage posts the message in the channel, as a reply when threadTs is set, and returns the timestamp of the message.
+func (reporter *SlackReporter) postMessage(message SlackMessage, threadTs string) (string, error) {
+	body, err := json.Marshal(struct {
+		Channel  string `json:"channel"`
+		ThreadTs string `json:"thread_ts,omitempty"`
+		SlackMessage
+	}{reporter.config.Channel, threadTs, message})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(reporter.apiUrl, "/")+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+reporter.token)
+	resp, err := reporter.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response slackPostMessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("unexpected response with status %d: %v", resp.StatusCode, err.Error())
+	}
+	if !response.Ok {
+		return "", fmt.Errorf("slack error: %s", response.Error)
+	}
+	return response.Ts, nil
+}
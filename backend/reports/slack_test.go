@@ -0,0 +1,183 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/shopspring/decimal"
+)
+
+type memorySlackThreadStore map[string]string
+
+func (store memorySlackThreadStore) GetThreadTs(channel string, threadKey string) (string, error) {
+	return store[channel+" "+threadKey], nil
+}
+
+func (store memorySlackThreadStore) SaveThreadTs(channel string, threadKey string, ts string) error {
+	store[channel+" "+threadKey] = ts
+	return nil
+}
+
+var revenue = common.MetricConfig{MetricName: "revenue"}
+
+type postedMessage struct {
+	Channel  string       `json:"channel"`
+	ThreadTs string       `json:"thread_ts"`
+	Text     string       `json:"text"`
+	Blocks   []SlackBlock `json:"blocks"`
+}
+
+func newSlackServer(t *testing.T, messages *[]postedMessage) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			t.Errorf("Unexpected request %s with %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var message postedMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("Unexpected body: %v", err)
+		}
+		*messages = append(*messages, message)
+		fmt.Fprintf(w, `{"ok": true, "ts": "1700000000.%06d"}`, len(*messages))
+	}))
+}
+
+func TestSlackReporterThreadsDriftsPerKPIPeriod(t *testing.T) {
+	var messages []postedMessage
+	server := newSlackServer(t, &messages)
+	defer server.Close()
+
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_API_URL", server.URL)
+	reporter := NewSlackReporter(common.SlackConfig{Channel: "C123"}, memorySlackThreadStore{})
+	report := common.KPIReport{
+		KPIName:        "revenue 2023-05 FR",
+		PeriodId:       "2023-05",
+		DimensionValue: "FR",
+		Events: []common.EventObject{
+			{CommitTimestamp: 100, Current: decimal.NewFromInt(1000), EventType: common.EventTypeCreate},
+			{CommitTimestamp: 200, Diff: 30, Current: decimal.NewFromInt(1030), EventType: common.EventTypeUpdate},
+		},
+	}
+	if err := reporter.UpsertKPIReport(revenue, report, 150); err != nil {
+		t.Fatal(err)
+	}
+	report.Events = append(report.Events, common.EventObject{CommitTimestamp: 300, Diff: -10, Current: decimal.NewFromInt(1020), EventType: common.EventTypeUpdate})
+	if err := reporter.UpsertKPIReport(revenue, report, 200); err != nil {
+		t.Fatal(err)
+	}
+	otherPeriod := common.KPIReport{KPIName: "revenue 2023-06 FR", PeriodId: "2023-06", DimensionValue: "FR", Events: []common.EventObject{
+		{CommitTimestamp: 300, Diff: 5, Current: decimal.NewFromInt(505), EventType: common.EventTypeUpdate},
+	}}
+	if err := reporter.UpsertKPIReport(revenue, otherPeriod, 200); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	if messages[0].ThreadTs != "" || messages[0].Channel != "C123" {
+		t.Errorf("Expected the first drift to start a thread, got %+v", messages[0])
+	}
+	if messages[1].ThreadTs != "1700000000.000001" {
+		t.Errorf("Expected the second drift of the period to reply in its thread, got %q", messages[1].ThreadTs)
+	}
+	if messages[2].ThreadTs != "" {
+		t.Errorf("Expected another period to start its own thread, got %q", messages[2].ThreadTs)
+	}
+}
+
+func TestSlackReporterSkipsNewHistories(t *testing.T) {
+	var messages []postedMessage
+	server := newSlackServer(t, &messages)
+	defer server.Close()
+
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_API_URL", server.URL)
+	reporter := NewSlackReporter(common.SlackConfig{Channel: "C123"}, memorySlackThreadStore{})
+	report := common.KPIReport{KPIName: "revenue 2023-05", PeriodId: "2023-05", Events: []common.EventObject{
+		{CommitTimestamp: 200, Diff: 30, Current: decimal.NewFromInt(1030), EventType: common.EventTypeUpdate},
+	}}
+	if err := reporter.UpsertKPIReport(revenue, report, 0); err != nil || len(messages) != 0 {
+		t.Errorf("Expected no message for a history built from scratch, got %d messages and %v", len(messages), err)
+	}
+}
+
+func TestSlackReporterIgnoresConfiguredApiUrl(t *testing.T) {
+	var messages []postedMessage
+	server := newSlackServer(t, &messages)
+	defer server.Close()
+	configuredServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected the api url of the repository config not to be called, got %s with %s", r.URL.Path, r.Header.Get("Authorization"))
+	}))
+	defer configuredServer.Close()
+
+	var config common.Config
+	if err := json.Unmarshal([]byte(`{"slack": {"channel": "C123", "apiUrl": "`+configuredServer.URL+`"}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_API_URL", server.URL)
+	reporter := NewSlackReporter(*config.Slack, memorySlackThreadStore{})
+	report := common.KPIReport{KPIName: "revenue 2023-05", PeriodId: "2023-05", Events: []common.EventObject{
+		{CommitTimestamp: 200, Diff: 30, Current: decimal.NewFromInt(1030), EventType: common.EventTypeUpdate},
+	}}
+	if err := reporter.UpsertKPIReport(revenue, report, 100); err != nil || len(messages) != 1 {
+		t.Errorf("Expected the drift to be posted to SLACK_API_URL, got %d messages and %v", len(messages), err)
+	}
+}
+
+func TestSlackReporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": false, "error": "channel_not_found"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("SLACK_API_URL", server.URL)
+	reporter := NewSlackReporter(common.SlackConfig{Channel: "C123"}, memorySlackThreadStore{})
+	report := common.KPIReport{KPIName: "revenue 2023-05", PeriodId: "2023-05", Events: []common.EventObject{
+		{CommitTimestamp: 200, Diff: 30, Current: decimal.NewFromInt(1030), EventType: common.EventTypeUpdate},
+	}}
+	if err := reporter.UpsertKPIReport(revenue, report, 100); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected the slack error, got %v", err)
+	}
+}
+
+func TestBuildSlackDriftMessage(t *testing.T) {
+	report := common.KPIReport{KPIName: "revenue 2023-05 FR, ads", PeriodId: "2023-05", DimensionValue: "FR, ads", WaterfallChartUrl: "https://app.data-drift.io/report/acme/data/metrics/revenue/report/2023-05"}
+	event := common.EventObject{
+		CommitTimestamp: 1685620800,
+		CommitUrl:       "https://github.com/acme/data/commit/abc",
+		Diff:            -12.5,
+		Current:         decimal.NewFromFloat(987.5),
+		EventType:       common.EventTypeUpdate,
+		CommitComments:  []common.CommitComments{{CommentAuthor: "jane", CommentBody: "Refunds <late> & duplicated"}},
+	}
+	message := BuildSlackDriftMessage("revenue", report, event)
+
+	jsonMessage, _ := json.Marshal(message)
+	for _, expected := range []string{
+		"*Metric*\\nrevenue",
+		"*Period*\\n2023-05",
+		"*Dimension*\\nFR, ads",
+		"*Value*\\n1000 → 987.5",
+		"*Diff*\\n-12.5",
+		"Refunds \\u0026lt;late\\u0026gt; \\u0026amp; duplicated",
+		"\\u003chttps://github.com/acme/data/commit/abc|Commit\\u003e",
+		report.WaterfallChartUrl,
+	} {
+		if !strings.Contains(string(jsonMessage), expected) {
+			t.Errorf("Expected the message to contain %s, got %s", expected, jsonMessage)
+		}
+	}
+	if message.Text != "revenue 2023-05 FR, ads drifted by -12.5" {
+		t.Errorf("Unexpected text %s", message.Text)
+	}
+	if increase := BuildSlackDriftMessage("revenue", report, common.EventObject{Diff: 3, Current: decimal.NewFromInt(3)}); !strings.HasSuffix(increase.Text, "+3") {
+		t.Errorf("Expected the sign of an increase, got %s", increase.Text)
+	}
+}
@@ -0,0 +1,422 @@
+package reports
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/data-drift/data-drift/charts"
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/helpers"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/shopspring/decimal"
+)
+
+// StaticReporter publishes a static site with a page per metric: the waterfall of the drifts of each KPI period and the cohorts of each time grain.
+type StaticReporter struct {
+	format        string
+	metricNames   []string
+	kpiRepository common.MetricStore
+	ownerName     string
+	repoName      string
+	writer        staticSiteWriter
+	kpiReports    map[string][]common.KPIReport
+}
+
+func newStaticReporter(config common.StaticReportConfig, metricNames []string, kpiRepository common.MetricStore, ownerName string, repoName string, writer staticSiteWriter) *StaticReporter {
+	format := config.Format
+	if format == "" {
+		format = common.StaticReportHTML
+	}
+	return &StaticReporter{
+		format:        format,
+		metricNames:   metricNames,
+		kpiRepository: kpiRepository,
+		ownerName:     ownerName,
+		repoName:      repoName,
+		writer:        writer,
+		kpiReports:    make(map[string][]common.KPIReport),
+	}
+}
+
+// Init writes the index of the metrics.
+func (reporter *StaticReporter) Init() error {
+	files, err := RenderStaticIndex(reporter.format, reporter.ownerName+"/"+reporter.repoName, reporter.metricNames)
+	if err != nil {
+		return err
+	}
+	return reporter.writer.WriteFiles(files, "Update the index of the drift reports")
+}
+
+// UpsertKPIReport keeps the report until the page of its metric is written with the summary.
+func (reporter *StaticReporter) UpsertKPIReport(metric common.MetricConfig, report common.KPIReport, since int64) error {
+	if report.KPIName == "" {
+		return nil
+	}
+	reporter.kpiReports[metric.MetricName] = append(reporter.kpiReports[metric.MetricName], report)
+	return nil
+}
+
+// UpsertSummaryReport writes the page of the metric, with the reports of its KPIs and its cohorts.
+func (reporter *StaticReporter) UpsertSummaryReport(metric common.MetricConfig, chartUrls map[common.TimeGrain]string) error {
+	kpiReports := reporter.kpiReports[metric.MetricName]
+	delete(reporter.kpiReports, metric.MetricName)
+
+	metrics, err := reporter.kpiRepository.ReadMetricKPI(common.NewGetMetricStorageKey(reporter.ownerName, reporter.repoName, metric.MetricName))
+	if err != nil {
+		return fmt.Errorf("failed to read history of %s: %v", metric.MetricName, err.Error())
+	}
+	var cohorts []reducers.CohortsData
+	for _, timeGrain := range metric.TimeGrains {
+		cohorts = append(cohorts, reducers.GetReportData(metrics, timeGrain, common.DimensionFilter{}))
+	}
+
+	files, err := RenderStaticMetricPage(reporter.format, metric, kpiReports, cohorts)
+	if err != nil {
+		return err
+	}
+	return reporter.writer.WriteFiles(files, "Update the drift report of "+metric.MetricName)
+}
+
+type staticLink struct {
+	Name string
+	File string
+}
+
+type staticIndex struct {
+	Title   string
+	Metrics []staticLink
+}
+
+type staticMetricPage struct {
+	MetricName string
+	IndexFile  string
+	KPIs       []staticKPI
+	Cohorts    []staticCohorts
+}
+
+type staticKPI struct {
+	Name           string
+	DimensionValue string
+	InitialValue   string
+	LatestValue    string
+	Drift          string
+	// Link to the waterfall of the KPI within the site: its anchor in html, its chart file in markdown
+	Link      string
+	Anchor    string
+	Chart     htmltemplate.HTML
+	ChartFile string
+	Events    []staticEvent
+}
+
+type staticEvent struct {
+	Date      string
+	Diff      string
+	Value     string
+	CommitUrl string
+	Comments  string
+}
+
+type staticCohorts struct {
+	TimeGrain   string
+	Chart       htmltemplate.HTML
+	ChartFile   string
+	CohortDates []string
+	Rows        []staticCohortRow
+}
+
+type staticCohortRow struct {
+	Date   string
+	Values []string
+}
+
+// RenderStaticIndex returns the index page of the site, linking the page of each metric.
+func RenderStaticIndex(format string, title string, metricNames []string) (map[string][]byte, error) {
+	index := staticIndex{Title: title}
+	for _, metricName := range metricNames {
+		index.Metrics = append(index.Metrics, staticLink{Name: metricName, File: getStaticPageFile(format, metricName)})
+	}
+	content, err := renderStaticTemplate(format, "index", index)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{getStaticPageFile(format, "index"): content}, nil
+}
+
+// RenderStaticMetricPage returns the page of a metric, and the files of its charts in markdown.
+func RenderStaticMetricPage(format string, metric common.MetricConfig, kpiReports []common.KPIReport, cohorts []reducers.CohortsData) (map[string][]byte, error) {
+	location, err := metric.GetLocation()
+	if err != nil {
+		location = time.UTC
+	}
+	files := make(map[string][]byte)
+	chartDirectory := getStaticFileName(metric.MetricName)
+	addChart := func(name string, svg string) (htmltemplate.HTML, string) {
+		if format == common.StaticReportMarkdown {
+			chartFile := path.Join(chartDirectory, getUniqueStaticFileName(name)+".svg")
+			files[chartFile] = []byte(svg)
+			return "", chartFile
+		}
+		return htmltemplate.HTML(svg), ""
+	}
+
+	page := staticMetricPage{MetricName: metric.MetricName, IndexFile: getStaticPageFile(format, "index")}
+
+	sort.Slice(kpiReports, func(i, j int) bool { return kpiReports[i].KPIName < kpiReports[j].KPIName })
+	for _, report := range kpiReports {
+		kpi := staticKPI{
+			Name:         report.KPIName,
+			InitialValue: helpers.FormatWithSeparator(report.InitialValue),
+			LatestValue:  helpers.FormatWithSeparator(report.LatestValue),
+			Drift:        formatSignedDecimal(report.LatestValue.Sub(report.InitialValue)),
+			Anchor:       getUniqueStaticFileName(string(report.PeriodId) + "-" + string(report.DimensionValue)),
+		}
+		if report.DimensionValue != common.NoDimensionValue {
+			kpi.DimensionValue = string(report.DimensionValue)
+		}
+		for _, event := range report.Events {
+			date := time.Unix(event.CommitTimestamp, 0).In(location)
+			kpi.Events = append(kpi.Events, staticEvent{
+				Date:      date.Format("2006-01-02 15:04"),
+				Diff:      formatSignedDecimal(decimal.NewFromFloat(event.Diff)),
+				Value:     helpers.FormatWithSeparator(event.Current),
+				CommitUrl: event.CommitUrl,
+				Comments:  formatCommitComments(event.CommitComments),
+			})
+		}
+		kpi.Chart, kpi.ChartFile = addChart(string(report.PeriodId)+"-"+string(report.DimensionValue), charts.Waterfall(report.KPIName, charts.WaterfallSteps(report, location)))
+		kpi.Link = "#" + kpi.Anchor
+		if kpi.ChartFile != "" {
+			kpi.Link = kpi.ChartFile
+		}
+		page.KPIs = append(page.KPIs, kpi)
+	}
+
+	for _, cohortsData := range cohorts {
+		if len(cohortsData.CohortDates) == 0 {
+			continue
+		}
+		pageCohorts := staticCohorts{TimeGrain: string(cohortsData.TimeGrain), CohortDates: cohortsData.CohortDates}
+		pageCohorts.Chart, pageCohorts.ChartFile = addChart("cohorts-"+string(cohortsData.TimeGrain), charts.CohortChart(cohortsData))
+
+		timestamps := make([]int64, 0, len(cohortsData.DataIndexedByTimestamp))
+		for timestamp := range cohortsData.DataIndexedByTimestamp {
+			timestamps = append(timestamps, timestamp)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+		for _, timestamp := range timestamps {
+			row := staticCohortRow{Date: time.Unix(timestamp, 0).In(location).Format("2006-01-02 15:04")}
+			for _, cohortDate := range cohortsData.CohortDates {
+				value, ok := cohortsData.DataIndexedByTimestamp[timestamp][cohortDate]
+				if ok {
+					row.Values = append(row.Values, value.StringFixed(2)+"%")
+				} else {
+					row.Values = append(row.Values, "")
+				}
+			}
+			pageCohorts.Rows = append(pageCohorts.Rows, row)
+		}
+		page.Cohorts = append(page.Cohorts, pageCohorts)
+	}
+
+	content, err := renderStaticTemplate(format, "metric", page)
+	if err != nil {
+		return nil, err
+	}
+	files[getStaticPageFile(format, metric.MetricName)] = content
+	return files, nil
+}
+
+func formatSignedDecimal(value decimal.Decimal) string {
+	if value.IsPositive() {
+		return "+" + helpers.FormatWithSeparator(value)
+	}
+	return helpers.FormatWithSeparator(value)
+}
+
+func formatCommitComments(comments []common.CommitComments) string {
+	var formattedComments []string
+	for _, comment := range comments {
+		formattedComments = append(formattedComments, comment.CommentAuthor+": "+strings.Join(strings.Fields(comment.CommentBody), " "))
+	}
+	return strings.Join(formattedComments, " / ")
+}
+
+// getStaticFileName turns a name into a file name, keeping letters, digits, dots, dashes and underscores.
+func getStaticFileName(name string) string {
+	fileName := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+	return strings.Trim(fileName, ".")
+}
+
+// getUniqueStaticFileName suffixes the file name with a hash of the name, as distinct names like FR/x and FR-x have the same file name.
+func getUniqueStaticFileName(name string) string {
+	hash := sha1.Sum([]byte(name))
+	return getStaticFileName(name) + "-" + hex.EncodeToString(hash[:4])
+}
+
+func getStaticPageFile(format string, name string) string {
+	if format == common.StaticReportMarkdown {
+		return getStaticFileName(name) + ".md"
+	}
+	return getStaticFileName(name) + ".html"
+}
+
+func renderStaticTemplate(format string, name string, data interface{}) ([]byte, error) {
+	var content bytes.Buffer
+	var err error
+	switch format {
+	case common.StaticReportHTML:
+		err = staticHTMLTemplates.ExecuteTemplate(&content, name, data)
+	case common.StaticReportMarkdown:
+		err = staticMarkdownTemplates.ExecuteTemplate(&content, name, data)
+	default:
+		return nil, fmt.Errorf("unknown static report format %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s page: %v", name, err.Error())
+	}
+	return content.Bytes(), nil
+}
+
+// markdownCell escapes the characters breaking a markdown table cell.
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
+
+var staticMarkdownTemplates = template.Must(template.New("").Funcs(template.FuncMap{"cell": markdownCell}).Parse(`
+{{- define "index" -}}
+# Drift reports of {{ .Title }}
+{{ range .Metrics }}
+- [{{ .Name }}]({{ .File }})
+{{- end }}
+{{ end -}}
+
+{{- define "metric" -}}
+# {{ .MetricName }}
+
+[All metrics]({{ .IndexFile }})
+
+## Drifts
+{{ if not .KPIs }}
+No drift reported.
+{{ else }}
+| KPI | Initial value | Latest value | Drift |
+| --- | ---: | ---: | ---: |
+{{- range .KPIs }}
+| [{{ cell .Name }}]({{ .Link }}) | {{ .InitialValue }} | {{ .LatestValue }} | {{ .Drift }} |
+{{- end }}
+{{ range .KPIs }}
+### {{ .Name }}
+{{ if .DimensionValue }}
+Dimension: {{ .DimensionValue }}
+{{ end }}
+![Waterfall of {{ .Name }}]({{ .ChartFile }})
+
+| Date | Diff | Value | Commit | Comments |
+| --- | ---: | ---: | --- | --- |
+{{- range .Events }}
+| {{ .Date }} | {{ .Diff }} | {{ .Value }} | {{ if .CommitUrl }}[commit]({{ .CommitUrl }}){{ end }} | {{ cell .Comments }} |
+{{- end }}
+{{ end }}
+{{- end }}
+{{- range .Cohorts }}
+## Cohorts {{ .TimeGrain }}
+
+![Cohorts {{ .TimeGrain }}]({{ .ChartFile }})
+
+| Date |{{ range .CohortDates }} {{ . }} |{{ end }}
+| --- |{{ range .CohortDates }} ---: |{{ end }}
+{{- range .Rows }}
+| {{ .Date }} |{{ range .Values }} {{ . }} |{{ end }}
+{{- end }}
+{{ end }}
+{{- end -}}
+`))
+
+var staticHTMLTemplates = htmltemplate.Must(htmltemplate.New("").Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ . }}</title>
+<style>
+body { font-family: sans-serif; color: #36404d; max-width: 1080px; margin: 2rem auto; padding: 0 1rem; }
+table { border-collapse: collapse; margin: 1rem 0; font-size: 0.9rem; }
+th, td { border: 1px solid #e3e7ed; padding: 0.3rem 0.6rem; text-align: right; }
+th:first-child, td:first-child, td.text { text-align: left; }
+.cohorts { overflow-x: auto; }
+</style>
+</head>
+<body>
+{{- end -}}
+
+{{- define "index" -}}
+{{ template "head" (printf "Drift reports of %s" .Title) }}
+<h1>Drift reports of {{ .Title }}</h1>
+<ul>
+{{- range .Metrics }}
+<li><a href="{{ .File }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+</body>
+</html>
+{{ end -}}
+
+{{- define "metric" -}}
+{{ template "head" .MetricName }}
+<h1>{{ .MetricName }}</h1>
+<p><a href="{{ .IndexFile }}">All metrics</a></p>
+<h2>Drifts</h2>
+{{- if not .KPIs }}
+<p>No drift reported.</p>
+{{- else }}
+<table>
+<tr><th>KPI</th><th>Initial value</th><th>Latest value</th><th>Drift</th></tr>
+{{- range .KPIs }}
+<tr><td><a href="{{ .Link }}">{{ .Name }}</a></td><td>{{ .InitialValue }}</td><td>{{ .LatestValue }}</td><td>{{ .Drift }}</td></tr>
+{{- end }}
+</table>
+{{- range .KPIs }}
+<h3 id="{{ .Anchor }}">{{ .Name }}</h3>
+{{- if .DimensionValue }}
+<p>Dimension: {{ .DimensionValue }}</p>
+{{- end }}
+{{ .Chart }}
+<table>
+<tr><th>Date</th><th>Diff</th><th>Value</th><th>Commit</th><th>Comments</th></tr>
+{{- range .Events }}
+<tr><td>{{ .Date }}</td><td>{{ .Diff }}</td><td>{{ .Value }}</td><td class="text">{{ if .CommitUrl }}<a href="{{ .CommitUrl }}">commit</a>{{ end }}</td><td class="text">{{ .Comments }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+{{- range .Cohorts }}
+<h2>Cohorts {{ .TimeGrain }}</h2>
+{{ .Chart }}
+<div class="cohorts">
+<table>
+<tr><th>Date</th>{{ range .CohortDates }}<th>{{ . }}</th>{{ end }}</tr>
+{{- range .Rows }}
+<tr><td>{{ .Date }}</td>{{ range .Values }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+</div>
+{{- end }}
+</body>
+</html>
+{{ end -}}
+`))
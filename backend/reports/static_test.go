@@ -0,0 +1,232 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/data-drift/data-drift/reducers"
+	"github.com/google/go-github/v56/github"
+	"github.com/shopspring/decimal"
+)
+
+func newStaticTestData() (common.MetricConfig, []common.KPIReport, common.Metrics) {
+	metric := common.MetricConfig{MetricName: "revenue", TimeGrains: []common.TimeGrain{common.Month}}
+	june := func(day int) int64 { return time.Date(2023, 6, day, 10, 0, 0, 0, time.UTC).Unix() }
+	report := common.KPIReport{
+		KPIName:           "revenue 2023-05",
+		PeriodId:          "2023-05",
+		DimensionValue:    common.NoDimensionValue,
+		WaterfallChartUrl: "https://app.data-drift.io/report/acme/data/metrics/revenue/report/2023-05",
+		InitialValue:      decimal.NewFromInt(1000),
+		LatestValue:       decimal.NewFromInt(950),
+		Events: []common.EventObject{
+			{CommitTimestamp: june(1), Current: decimal.NewFromInt(1000), EventType: common.EventTypeCreate, CommitUrl: "https://github.com/acme/data/commit/a"},
+			{CommitTimestamp: june(5), Diff: -50, Current: decimal.NewFromInt(950), EventType: common.EventTypeUpdate, CommitUrl: "https://github.com/acme/data/commit/b",
+				CommitComments: []common.CommitComments{{CommentAuthor: "jane", CommentBody: "Late refunds | duplicated"}}},
+		},
+	}
+	metrics := common.Metrics{
+		"2023-05": {TimeGrain: common.Month, Period: "2023-05", Dimension: common.NoDimension, DimensionValue: common.NoDimensionValue, History: common.MetricHistory{
+			"a": {KPI: decimal.NewFromInt(1000), CommitTimestamp: june(1)},
+			"b": {KPI: decimal.NewFromInt(950), CommitTimestamp: june(5)},
+		}},
+	}
+	return metric, []common.KPIReport{report}, metrics
+}
+
+func TestRenderStaticMetricPage(t *testing.T) {
+	metric, kpiReports, metrics := newStaticTestData()
+	cohorts := []reducers.CohortsData{reducers.GetReportData(metrics, common.Month, common.DimensionFilter{})}
+
+	htmlFiles, err := RenderStaticMetricPage(common.StaticReportHTML, metric, kpiReports, cohorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(htmlFiles) != 1 {
+		t.Fatalf("Expected a single self-contained page, got %d files", len(htmlFiles))
+	}
+	page := string(htmlFiles["revenue.html"])
+	for _, expected := range []string{
+		"<h1>revenue</h1>",
+		"<td>1,000</td><td>950</td><td>-50</td>",
+		"<svg",
+		"Late refunds | duplicated",
+		`<a href="https://github.com/acme/data/commit/b">commit</a>`,
+		`<a href="#2023-05-No-dimension-97c5f29f">revenue 2023-05</a>`,
+		`<h3 id="2023-05-No-dimension-97c5f29f">revenue 2023-05</h3>`,
+		"<h2>Cohorts month</h2>",
+		"<th>2023-05</th>",
+		"<td>2023-06-05 10:00</td><td>-5.00%</td>",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("Expected the html page to contain %s, got %s", expected, page)
+		}
+	}
+
+	markdownFiles, err := RenderStaticMetricPage(common.StaticReportMarkdown, metric, kpiReports, cohorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"revenue.md", "revenue/2023-05-No-dimension-97c5f29f.svg", "revenue/cohorts-month-3d2c592d.svg"} {
+		if _, ok := markdownFiles[file]; !ok {
+			t.Errorf("Expected the file %s, got %d files", file, len(markdownFiles))
+		}
+	}
+	markdown := string(markdownFiles["revenue.md"])
+	if strings.Contains(page, "app.data-drift.io") || strings.Contains(markdown, "app.data-drift.io") {
+		t.Errorf("Expected the pages to only link within the site")
+	}
+	for _, expected := range []string{
+		"| [revenue 2023-05](revenue/2023-05-No-dimension-97c5f29f.svg) | 1,000 | 950 | -50 |",
+		"![Waterfall of revenue 2023-05](revenue/2023-05-No-dimension-97c5f29f.svg)",
+		"| 2023-06-05 10:00 | -50 | 950 | [commit](https://github.com/acme/data/commit/b) | jane: Late refunds \\| duplicated |",
+		"| Date | 2023-05 |",
+		"| 2023-06-05 10:00 | -5.00% |",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected the markdown page to contain %s, got %s", expected, markdown)
+		}
+	}
+}
+
+func TestRenderStaticMetricPageDistinctCharts(t *testing.T) {
+	metric, kpiReports, _ := newStaticTestData()
+	slashReport := kpiReports[0]
+	slashReport.KPIName, slashReport.DimensionValue = "revenue 2023-05 FR/x", "FR/x"
+	dashReport := kpiReports[0]
+	dashReport.KPIName, dashReport.DimensionValue = "revenue 2023-05 FR-x", "FR-x"
+
+	files, err := RenderStaticMetricPage(common.StaticReportMarkdown, metric, []common.KPIReport{slashReport, dashReport}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected the page and a chart per KPI, got %d files", len(files))
+	}
+}
+
+func TestStaticReporterWritesDirectory(t *testing.T) {
+	metric, kpiReports, metrics := newStaticTestData()
+	store, err := common.NewFileMetricStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.WriteMetricKPI("acme", "data", metric.MetricName, metrics); err != nil {
+		t.Fatal(err)
+	}
+	siteDir := t.TempDir()
+	reporter := newStaticReporter(common.StaticReportConfig{Format: common.StaticReportMarkdown}, []string{"revenue"}, store, "acme", "data", directoryWriter{directory: siteDir})
+
+	if err := reporter.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range append(kpiReports, common.KPIReport{}) {
+		if err := reporter.UpsertKPIReport(metric, report, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reporter.UpsertSummaryReport(metric, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	index, _ := os.ReadFile(filepath.Join(siteDir, "index.md"))
+	if !strings.Contains(string(index), "- [revenue](revenue.md)") {
+		t.Errorf("Unexpected index %s", index)
+	}
+	page, _ := os.ReadFile(filepath.Join(siteDir, "revenue.md"))
+	if !strings.Contains(string(page), "### revenue 2023-05") || !strings.Contains(string(page), "## Cohorts month") {
+		t.Errorf("Unexpected page %s", page)
+	}
+	if _, err := os.Stat(filepath.Join(siteDir, "revenue", "cohorts-month-3d2c592d.svg")); err != nil {
+		t.Errorf("Expected the cohorts chart to be written: %v", err)
+	}
+}
+
+// fakeGitServer stores the branch of a single repository, a tree sha being derived from its content.
+type fakeGitServer struct {
+	headCommit string
+	headTree   string
+	commits    int
+}
+
+func (server *fakeGitServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/data/git/ref/heads/gh-pages":
+		if server.headCommit == "" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"ref": "refs/heads/gh-pages", "object": {"sha": %q}}`, server.headCommit)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/data/git/commits/"):
+		fmt.Fprintf(w, `{"sha": %q, "tree": {"sha": %q}}`, server.headCommit, server.headTree)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/data/git/trees":
+		var body struct {
+			Tree []github.TreeEntry `json:"tree"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var paths []string
+		for _, entry := range body.Tree {
+			paths = append(paths, entry.GetPath()+"="+entry.GetContent())
+		}
+		fmt.Fprintf(w, `{"sha": %q}`, fmt.Sprintf("tree-%x", strings.Join(paths, ",")))
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/data/git/commits":
+		var commit struct {
+			Tree string `json:"tree"`
+		}
+		json.NewDecoder(r.Body).Decode(&commit)
+		server.commits++
+		server.headTree = commit.Tree
+		fmt.Fprintf(w, `{"sha": "commit-%d", "tree": {"sha": %q}}`, server.commits, server.headTree)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/data/git/refs",
+		r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/data/git/refs/heads/gh-pages":
+		server.headCommit = fmt.Sprintf("commit-%d", server.commits)
+		fmt.Fprintf(w, `{"ref": "refs/heads/gh-pages", "object": {"sha": %q}}`, server.headCommit)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGitBranchWriter(t *testing.T) {
+	gitServer := &fakeGitServer{}
+	server := httptest.NewServer(gitServer)
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	writer, err := newStaticSiteWriter(common.StaticReportConfig{Branch: "gh-pages", Directory: "docs"}, client, "acme", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{"index.html": []byte("<h1>acme/data</h1>")}
+	if err := writer.WriteFiles(files, "Update the index of the drift reports"); err != nil {
+		t.Fatal(err)
+	}
+	if gitServer.commits != 1 || gitServer.headCommit != "commit-1" {
+		t.Fatalf("Expected the branch to be created with a commit, got %d commits and head %s", gitServer.commits, gitServer.headCommit)
+	}
+
+	if err := writer.WriteFiles(files, "Update the index of the drift reports"); err != nil {
+		t.Fatal(err)
+	}
+	if gitServer.commits != 1 {
+		t.Errorf("Expected no commit when the files are unchanged, got %d commits", gitServer.commits)
+	}
+
+	files["index.html"] = []byte("<h1>acme/data reports</h1>")
+	if err := writer.WriteFiles(files, "Update the index of the drift reports"); err != nil {
+		t.Fatal(err)
+	}
+	if gitServer.commits != 2 || gitServer.headCommit != "commit-2" {
+		t.Errorf("Expected the branch to move to a second commit, got %d commits and head %s", gitServer.commits, gitServer.headCommit)
+	}
+}
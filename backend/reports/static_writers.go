@@ -0,0 +1,127 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/data-drift/data-drift/common"
+	"github.com/google/go-github/v56/github"
+)
+
+// staticSiteWriter writes the files of a static site, by path relative to its root.
+type staticSiteWriter interface {
+	WriteFiles(files map[string][]byte, message string) error
+}
+
+func newStaticSiteWriter(config common.StaticReportConfig, client *github.Client, ownerName string, repoName string) (staticSiteWriter, error) {
+	if config.Directory != "" && !filepath.IsLocal(config.Directory) {
+		return nil, fmt.Errorf("the directory of the static report has to be relative, got %s", config.Directory)
+	}
+	if config.Branch != "" {
+		if client == nil {
+			return nil, fmt.Errorf("a github client is needed to commit the static report to %s", config.Branch)
+		}
+		return gitBranchWriter{client: client, ownerName: ownerName, repoName: repoName, branch: config.Branch, directory: filepath.ToSlash(config.Directory)}, nil
+	}
+	rootDir := os.Getenv("STATIC_REPORTS_DIR")
+	if rootDir == "" {
+		rootDir = filepath.Join("dist", "static-reports")
+	}
+	return directoryWriter{directory: filepath.Join(rootDir, ownerName, repoName, config.Directory)}, nil
+}
+
+// directoryWriter writes the site in a directory of the server.
+type directoryWriter struct {
+	directory string
+}
+
+func (writer directoryWriter) WriteFiles(files map[string][]byte, message string) error {
+	for filePath, content := range files {
+		if !filepath.IsLocal(filePath) {
+			return fmt.Errorf("invalid static report file %s", filePath)
+		}
+		fullPath := filepath.Join(writer.directory, filepath.FromSlash(filePath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitBranchWriter commits the site to a branch of the repository, the branch being created when missing.
+// No commit is made when the files are unchanged, so that syncing twice does not push twice.
+type gitBranchWriter struct {
+	client    *github.Client
+	ownerName string
+	repoName  string
+	branch    string
+	directory string
+}
+
+func (writer gitBranchWriter) WriteFiles(files map[string][]byte, message string) error {
+	ctx := context.Background()
+	refName := "refs/heads/" + writer.branch
+
+	var parents []*github.Commit
+	var baseTree string
+	ref, resp, err := writer.client.Git.GetRef(ctx, writer.ownerName, writer.repoName, refName)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to get branch %s: %v", writer.branch, err.Error())
+	}
+	if err == nil {
+		parent, _, err := writer.client.Git.GetCommit(ctx, writer.ownerName, writer.repoName, ref.GetObject().GetSHA())
+		if err != nil {
+			return fmt.Errorf("failed to get head of %s: %v", writer.branch, err.Error())
+		}
+		parents = []*github.Commit{{SHA: parent.SHA}}
+		baseTree = parent.GetTree().GetSHA()
+	}
+
+	filePaths := make([]string, 0, len(files))
+	for filePath := range files {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	var entries []*github.TreeEntry
+	for _, filePath := range filePaths {
+		if !filepath.IsLocal(filePath) {
+			return fmt.Errorf("invalid static report file %s", filePath)
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(path.Join(writer.directory, filePath)),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(string(files[filePath])),
+		})
+	}
+	tree, _, err := writer.client.Git.CreateTree(ctx, writer.ownerName, writer.repoName, baseTree, entries)
+	if err != nil {
+		return fmt.Errorf("failed to create tree: %v", err.Error())
+	}
+	if tree.GetSHA() == baseTree {
+		return nil
+	}
+
+	commit, _, err := writer.client.Git.CreateCommit(ctx, writer.ownerName, writer.repoName, &github.Commit{Message: github.String(message), Tree: tree, Parents: parents}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create commit: %v", err.Error())
+	}
+	newRef := &github.Reference{Ref: github.String(refName), Object: &github.GitObject{SHA: commit.SHA}}
+	if len(parents) == 0 {
+		_, _, err = writer.client.Git.CreateRef(ctx, writer.ownerName, writer.repoName, newRef)
+	} else {
+		_, _, err = writer.client.Git.UpdateRef(ctx, writer.ownerName, writer.repoName, newRef, false)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update branch %s: %v", writer.branch, err.Error())
+	}
+	return nil
+}
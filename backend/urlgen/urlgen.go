@@ -3,6 +3,8 @@ package urlgen
 import (
 	"fmt"
 	"net/url"
+	"os"
+	"strings"
 
 	"github.com/data-drift/data-drift/common"
 )
@@ -11,14 +13,44 @@ func MetricCohortUrl(owner string, repo string, metricName string, timegrain com
 	return fmt.Sprintf("https://app.data-drift.io/report/%s/%s/metrics/%s/cohorts/%s", owner, repo, metricName, timegrain)
 }
 
-func MetricReportUrl(owner string, repo string, metricName string, period common.PeriodKey, dimensionValue string) string {
+func MetricReportUrl(owner string, repo string, metricName string, period common.PeriodKey, dimensionQuery url.Values) string {
 	url := fmt.Sprintf("https://app.data-drift.io/report/%s/%s/metrics/%s/report/%s", owner, repo, metricName, string(period))
-	if dimensionValue != "" {
-		url += fmt.Sprintf("?dimensionValue=%s", dimensionValue)
+	if len(dimensionQuery) > 0 {
+		url += "?" + dimensionQuery.Encode()
 	}
 	return url
 }
 
+// ApiUrl is the public base URL of the server of the API, read from DATADRIFT_API_URL, empty when it is not set.
+func ApiUrl() string {
+	return strings.TrimSuffix(os.Getenv("DATADRIFT_API_URL"), "/")
+}
+
+// ValidateApiUrl checks that DATADRIFT_API_URL is an absolute http or https URL when it is set.
+func ValidateApiUrl() error {
+	apiUrl := ApiUrl()
+	if apiUrl == "" {
+		return nil
+	}
+	parsedUrl, err := url.Parse(apiUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return fmt.Errorf("DATADRIFT_API_URL %s is not an absolute http or https URL", apiUrl)
+	}
+	return nil
+}
+
+// MetricCohortChartUrl is the chart of the cohorts of a time grain rendered by the API, format being svg or png.
+// It is empty when DATADRIFT_API_URL is not set, the reports having no public URL to embed the chart from.
+// The URL is not signed: like the other gh/ routes it requires the credentials of a connection with AuthRequired,
+// so the chart only loads in Notion, Slack or any other embed when the repository is public on the server.
+func MetricCohortChartUrl(owner string, repo string, metricName string, timegrain common.TimeGrain, format string) string {
+	apiUrl := ApiUrl()
+	if apiUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/gh/%s/%s/metrics/%s/cohorts/%s/chart.%s", apiUrl, owner, repo, metricName, timegrain, format)
+}
+
 func BuildReportDiffBaseUrl(repoOwner, repoName string) string {
 	reportBaseUrl := fmt.Sprintf("https://app.data-drift.io/report/%s/%s/commit", repoOwner, repoName)
 	return reportBaseUrl
@@ -0,0 +1,39 @@
+package urlgen
+
+import (
+	"testing"
+
+	"github.com/data-drift/data-drift/common"
+)
+
+func TestMetricCohortChartUrl(t *testing.T) {
+	t.Setenv("DATADRIFT_API_URL", "")
+	if url := MetricCohortChartUrl("acme", "data", "revenue", common.Month, "png"); url != "" {
+		t.Errorf("Expected no chart URL without DATADRIFT_API_URL, got %s", url)
+	}
+
+	t.Setenv("DATADRIFT_API_URL", "https://drift.acme.internal/")
+	if url := MetricCohortChartUrl("acme", "data", "revenue", common.Month, "svg"); url != "https://drift.acme.internal/gh/acme/data/metrics/revenue/cohorts/month/chart.svg" {
+		t.Errorf("Expected the URL of DATADRIFT_API_URL, got %s", url)
+	}
+}
+
+func TestValidateApiUrl(t *testing.T) {
+	testCases := []struct {
+		apiUrl  string
+		wantErr bool
+	}{
+		{apiUrl: "", wantErr: false},
+		{apiUrl: "https://drift.acme.com", wantErr: false},
+		{apiUrl: "http://localhost:8080/", wantErr: false},
+		{apiUrl: "drift.acme.com", wantErr: true},
+		{apiUrl: "ftp://drift.acme.com", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Setenv("DATADRIFT_API_URL", tc.apiUrl)
+		if err := ValidateApiUrl(); (err != nil) != tc.wantErr {
+			t.Errorf("ValidateApiUrl(%q): expected an error to be %v, got %v", tc.apiUrl, tc.wantErr, err)
+		}
+	}
+}
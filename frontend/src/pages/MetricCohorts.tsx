@@ -8,13 +8,27 @@ import {
 import { mapCohortsMetricsMetadataToStepChartProps } from "../services/data-drift.mappers";
 import styled from "@emotion/styled";
 
+const COHORTS_PAGE_SIZE = 100;
+
+// Shows the latest cohorts: the cohorts are sorted by period, so the last page is loaded when there are more than a page.
 const getMetricCohortsData = async ({
   params,
 }: {
   params: Params<string>;
 }): Promise<StepChartProps> => {
   const typedParams = assertParamsHasNeededProperties(params);
-  const result = await getMetricCohorts(typedParams);
+  let result = await getMetricCohorts({
+    ...typedParams,
+    limit: COHORTS_PAGE_SIZE,
+  });
+  const page = result.data.page;
+  if (page && page.nextOffset !== null) {
+    result = await getMetricCohorts({
+      ...typedParams,
+      limit: COHORTS_PAGE_SIZE,
+      offset: page.total - COHORTS_PAGE_SIZE,
+    });
+  }
   const { metricNames, data } = mapCohortsMetricsMetadataToStepChartProps(
     result.data.cohortsMetricsMetadata
   );
@@ -7,7 +7,6 @@ import { theme } from "../theme";
 import {
   PeriodReport,
   Timegrain,
-  TimegrainAndDimensionString,
   TimegrainString,
   assertStringIsTimgrainString,
   getMetricReport,
@@ -22,14 +21,24 @@ const getMetricCohortsData = async ({
   params: Params<string>;
 }): Promise<WaterfallChartProps> => {
   const typedParams = assertParamsHasNeededProperties(params);
-  const result = await getMetricReport(typedParams);
-
   const searchParams = new URLSearchParams(location.search);
-  const dimensionValue = searchParams.get("dimensionValue");
-  const resultKey = dimensionValue
-    ? (`${typedParams.timegrainValue} ${dimensionValue}` as TimegrainAndDimensionString)
-    : typedParams.timegrainValue;
-  const metricMetadata = result.data[resultKey];
+  const dimensionFilter = new URLSearchParams();
+  searchParams.getAll("dimension").forEach((dimension) => {
+    dimensionFilter.append("dimension", dimension);
+  });
+  searchParams.getAll("dimensionValue").forEach((dimensionValue) => {
+    dimensionFilter.append("dimensionValue", dimensionValue);
+  });
+  const result = await getMetricReport({ ...typedParams, dimensionFilter });
+
+  // The report of a dimension only holds the periods of the requested combination of values,
+  // legacy links giving only the dimensionValue being resolved by the server.
+  const metricMetadata =
+    dimensionFilter.has("dimension") || dimensionFilter.has("dimensionValue")
+      ? Object.values(result.data).find(
+          (periodReport) => periodReport?.Period === typedParams.timegrainValue
+        )
+      : result.data[typedParams.timegrainValue];
   if (!metricMetadata)
     throw new Error(
       `Could not find metric metadata for ${typedParams.metricName} ${typedParams.timegrainValue}`
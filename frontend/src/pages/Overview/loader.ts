@@ -133,11 +133,14 @@ const fetchCommits = async (
       return mappedCommits;
     }
     case "github": {
-      const result = await getCommitList(
-        strategy.params,
-        currentDate.toISOString().substring(0, 10)
-      );
-      return result.data;
+      const date = currentDate.toISOString().substring(0, 10);
+      let result = await getCommitList(strategy.params, date);
+      const commits = [...result.data];
+      while (result.nextPage) {
+        result = await getCommitList(strategy.params, date, result.nextPage);
+        commits.push(...result.data);
+      }
+      return commits;
     }
     default:
       throw new Error("Strategy not supported");
@@ -46,6 +46,8 @@ export const getPatchAndHeader = async (params: CommitParam) => {
     date: string;
     filename: string;
     patchToLarge: boolean;
+    duplicateKeyCount: number;
+    dataQualityError: string;
   }>(
     `${DATA_DRIFT_API_URL}/gh/${params.owner}/${params.repo}/commit/${params.commitSHA}`
   );
@@ -56,6 +58,8 @@ export const getPatchAndHeader = async (params: CommitParam) => {
     date: new Date(result.data.date),
     filename: result.data.filename,
     patchToLarge: result.data.patchToLarge,
+    duplicateKeyCount: result.data.duplicateKeyCount,
+    dataQualityError: result.data.dataQualityError,
   };
 };
 
@@ -89,22 +93,28 @@ export const getMetricCohorts = async ({
   repo,
   metricName,
   timegrain,
+  limit,
+  offset,
 }: {
   installationId?: string;
   owner?: string;
   repo?: string;
   metricName: string;
   timegrain: Timegrain;
+  limit?: number;
+  offset?: number;
 }) => {
+  const params = { limit, offset };
   if (owner && repo) {
     const result = await axios.get<MetricCohortsResults>(
-      `${DATA_DRIFT_API_URL}/gh/${owner}/${repo}/metrics/${metricName}/cohorts/${timegrain}`
+      `${DATA_DRIFT_API_URL}/gh/${owner}/${repo}/metrics/${metricName}/cohorts/${timegrain}`,
+      { params }
     );
     return result;
   }
   const result = await axios.get<MetricCohortsResults>(
     `${DATA_DRIFT_API_URL}/metrics/${metricName}/cohorts/${timegrain}`,
-    { headers: { "Installation-Id": installationId } }
+    { headers: { "Installation-Id": installationId }, params }
   );
   return result;
 };
@@ -114,15 +124,17 @@ export const getCommitList = async (
     owner: string;
     repo: string;
   },
-  date?: string
+  date?: string,
+  page?: number
 ) => {
   const result = await axios.get<
     Endpoints["GET /repos/{owner}/{repo}/commits"]["response"]["data"]
   >(`${DATA_DRIFT_API_URL}/gh/${params.owner}/${params.repo}/commits`, {
-    params: { date },
+    params: { date, page },
   });
+  const nextPage = result.headers["x-next-page"] as string | undefined;
 
-  return result;
+  return { ...result, nextPage: nextPage ? Number(nextPage) : undefined };
 };
 
 export const getCommitListLocalStrategy = async (
@@ -145,7 +157,7 @@ export const getCommitListLocalStrategy = async (
 };
 
 // Define the custom type
-export type Timegrain = "year" | "quarter" | "month" | "week" | "day";
+export type Timegrain = "year" | "quarter" | "month" | "week" | "day" | "hour";
 
 // The assertion function
 export function assertTimegrain(value: string): asserts value is Timegrain {
@@ -154,7 +166,8 @@ export function assertTimegrain(value: string): asserts value is Timegrain {
     value !== "quarter" &&
     value !== "month" &&
     value !== "week" &&
-    value !== "day"
+    value !== "day" &&
+    value !== "hour"
   ) {
     throw new Error("Value is not a valid time unit!");
   }
@@ -165,6 +178,9 @@ type YearMonthString = `${number}-${string & { length: 2 }}`;
 type YearMonthDayString = `${number}-${string & { length: 2 }}-${string & {
   length: 2;
 }}`;
+type YearMonthDayHourString = `${YearMonthDayString}T${string & {
+  length: 2;
+}}`;
 type YearWeekString = `${number}-W${
   | (number & { length: 1 })
   | (string & { length: 2 })}`;
@@ -173,6 +189,7 @@ export type TimegrainString =
   | YearString
   | YearMonthString
   | YearMonthDayString
+  | YearMonthDayHourString
   | YearWeekString
   | YearQuarterString;
 
@@ -183,6 +200,7 @@ export function assertStringIsTimgrainString(
     str.match(/^\d{4}$/) !== null ||
     str.match(/^\d{4}-\d{2}$/) !== null ||
     str.match(/^\d{4}-\d{2}-\d{2}$/) !== null ||
+    str.match(/^\d{4}-\d{2}-\d{2}T\d{2}$/) !== null ||
     str.match(/^\d{4}-W\d{1,2}$/) !== null ||
     str.match(/^\d{4}-Q[1-4]$/) !== null
   ) {
@@ -199,6 +217,8 @@ export function getTimegrainFromString(str: TimegrainString): Timegrain {
     return "month";
   } else if (str.match(/^\d{4}-\d{2}-\d{2}$/) !== null) {
     return "day";
+  } else if (str.match(/^\d{4}-\d{2}-\d{2}T\d{2}$/) !== null) {
+    return "hour";
   } else if (str.match(/^\d{4}-W\d{1,2}$/) !== null) {
     return "week";
   } else if (str.match(/^\d{4}-Q[1-4]$/) !== null) {
@@ -213,27 +233,33 @@ export const getMetricReport = async ({
   metricName,
   owner,
   repo,
+  dimensionFilter,
 }: {
   installationId?: string;
   owner?: string;
   repo?: string;
   metricName: string;
   timegrain: Timegrain;
+  dimensionFilter?: URLSearchParams;
 }) => {
   if (owner && repo) {
     const result = await axios.get<MetricReport>(
-      `${DATA_DRIFT_API_URL}/gh/${owner}/${repo}/metrics/${metricName}/reports`
+      `${DATA_DRIFT_API_URL}/gh/${owner}/${repo}/metrics/${metricName}/reports`,
+      { params: dimensionFilter }
     );
     return result;
   }
   const result = await axios.get<MetricReport>(
     `${DATA_DRIFT_API_URL}/metrics/${metricName}/reports`,
-    { headers: { "Installation-Id": installationId } }
+    {
+      headers: { "Installation-Id": installationId },
+      params: dimensionFilter,
+    }
   );
   return result;
 };
 
-export type TimegrainAndDimensionString = `${TimegrainString}-${string}`;
+export type TimegrainAndDimensionString = `${TimegrainString}?${string}`;
 
 export type MetricReport = Record<
   TimegrainString | TimegrainAndDimensionString,
@@ -246,6 +272,7 @@ export interface PeriodReport {
   Period: TimegrainString;
   Dimension: string;
   DimensionValue: string;
+  DimensionValues?: Record<string, string>;
   History: { [key: CommitSha]: History };
 }
 
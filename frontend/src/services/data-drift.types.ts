@@ -7,6 +7,14 @@ export interface MetricCohortsResults {
   cohortsMetricsMetadata: CohortsMetricsMetadata;
   dataIndexedByTimestamp: { [key: TimestampString]: DataIndexedByTimestamp };
   timegrain: string;
+  page?: CohortsPage;
+}
+
+export interface CohortsPage {
+  offset: number;
+  limit: number;
+  total: number;
+  nextOffset: number | null;
 }
 
 export type CohortsMetricsMetadata = Record<CohortDate, CohortMetric>;